@@ -0,0 +1,123 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package check
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/stack"
+)
+
+// ErrAssertion indicates that an assertion of the project failed.
+const ErrAssertion errors.Kind = "assertion failed"
+
+// Result is a failed assertion of a stack.
+type Result struct {
+	// Stack is the stack where the assertion failed.
+	Stack project.Path `json:"stack"`
+
+	// Message is the evaluated message of the assertion.
+	Message string `json:"message"`
+
+	// Warning tells if the assertion is a warning instead of an error.
+	Warning bool `json:"warning"`
+
+	// Range is the range of the assertion expression.
+	Range info.Range `json:"range"`
+}
+
+// Results is a list of failed assertions.
+type Results []Result
+
+// String returns the result in the format "stack: severity: range: message".
+func (r Result) String() string {
+	severity := "error"
+	if r.Warning {
+		severity = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", r.Stack, severity, r.Range, r.Message)
+}
+
+// Errors returns the failed assertions which are not warnings.
+func (rs Results) Errors() Results {
+	var errs Results
+	for _, r := range rs {
+		if !r.Warning {
+			errs = append(errs, r)
+		}
+	}
+	return errs
+}
+
+// Stacks evaluates the assertions of the given stacks. The assertions of a
+// stack are the assert blocks defined in the stack directory and in all its
+// parent directories, evaluated in the stack context. Assertions spanning
+// multiple stacks can use the tm_stacks and tm_stack_globals functions.
+//
+// It returns the failed assertions sorted by stack and range. An error is
+// returned only if the globals or the assertions can't be evaluated.
+func Stacks(root *config.Root, stacks []*config.Stack) (Results, error) {
+	logger := log.With().
+		Str("action", "check.Stacks()").
+		Logger()
+
+	asserts := map[project.Path][]config.Assert{}
+	errs := errors.L()
+	for _, st := range stacks {
+		logger.Trace().Stringer("stack", st.Dir).Msg("checking assertions")
+
+		report := globals.ForStack(root, st)
+		if err := report.AsError(); err != nil {
+			errs.Append(errors.E(err, "loading globals of stack %s", st.Dir))
+			continue
+		}
+
+		stackAsserts, err := stack.LoadAsserts(root, st, report.Globals)
+		if err != nil {
+			errs.Append(err)
+			continue
+		}
+		asserts[st.Dir] = stackAsserts
+	}
+
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return FromAsserts(root, stacks, asserts), nil
+}
+
+// FromAsserts returns the failed assertions of the given stacks from their
+// already evaluated assertions, keyed by the stack directory. The results are
+// sorted by stack and range.
+func FromAsserts(root *config.Root, stacks []*config.Stack, asserts map[project.Path][]config.Assert) Results {
+	var results Results
+	for _, st := range stacks {
+		for _, assert := range asserts[st.Dir] {
+			if assert.Assertion {
+				continue
+			}
+			results = append(results, Result{
+				Stack:   st.Dir,
+				Message: assert.Message,
+				Warning: assert.Warning,
+				Range:   info.NewRange(root.HostDir(), assert.Range),
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Stack != results[j].Stack {
+			return results[i].Stack.String() < results[j].Stack.String()
+		}
+		return results[i].Range.String() < results[j].Range.String()
+	})
+	return results
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package check_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/check"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	errtest "github.com/terramate-io/terramate/test/errors"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+type (
+	result struct {
+		Stack   string
+		Message string
+		Warning bool
+	}
+
+	testcase struct {
+		name    string
+		layout  []string
+		want    []result
+		wantErr error
+	}
+)
+
+func TestCheckStacks(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []testcase{
+		{
+			name: "no asserts",
+			layout: []string{
+				"s:stack",
+			},
+		},
+		{
+			name: "successful assertions are not reported",
+			layout: []string{
+				"s:stack",
+				`f:assert.tm:assert {
+					assertion = terramate.stack.name != ""
+					message   = "stack has no name"
+				}`,
+			},
+		},
+		{
+			name: "failed assertions are reported per stack",
+			layout: []string{
+				`s:stacks/a:tags=["prod"]`,
+				`s:stacks/b:tags=["prod"]`,
+				`s:stacks/c:tags=["dev"]`,
+				`f:stacks/a/globals.tm:globals {
+					backend = "s3"
+				}`,
+				`f:assert.tm:assert {
+					assertion = !tm_contains(terramate.stack.tags, "prod") || tm_try(global.backend, null) != null
+					message   = "prod stack ${terramate.stack.path.absolute} has no backend"
+				}`,
+			},
+			want: []result{
+				{
+					Stack:   "/stacks/b",
+					Message: "prod stack /stacks/b has no backend",
+				},
+			},
+		},
+		{
+			name: "warnings are reported",
+			layout: []string{
+				"s:stack",
+				`f:stack/assert.tm:assert {
+					assertion = false
+					message   = "just a warning"
+					warning   = true
+				}`,
+			},
+			want: []result{
+				{
+					Stack:   "/stack",
+					Message: "just a warning",
+					Warning: true,
+				},
+			},
+		},
+		{
+			name: "assertions spanning multiple stacks",
+			layout: []string{
+				"s:stacks/a",
+				"s:stacks/b",
+				"s:stacks/c",
+				`f:stacks/a/globals.tm:globals {
+					state_key = "shared"
+				}`,
+				`f:stacks/b/globals.tm:globals {
+					state_key = "shared"
+				}`,
+				`f:stacks/c/globals.tm:globals {
+					state_key = "c"
+				}`,
+				`f:assert.tm:assert {
+					assertion = tm_length([
+						for s in tm_stacks("") : s.path
+						if tm_stack_globals(s.path, "state_key") == global.state_key
+					]) == 1
+					message = "state_key ${global.state_key} is shared with other stacks"
+				}`,
+			},
+			want: []result{
+				{
+					Stack:   "/stacks/a",
+					Message: "state_key shared is shared with other stacks",
+				},
+				{
+					Stack:   "/stacks/b",
+					Message: "state_key shared is shared with other stacks",
+				},
+			},
+		},
+		{
+			name: "results are sorted by stack and range",
+			layout: []string{
+				"s:z",
+				"s:a",
+				"s:a/child",
+				`f:assert.tm:assert {
+					assertion = false
+					message   = "root"
+				}`,
+				`f:a/assert.tm:assert {
+					assertion = false
+					message   = "a"
+					warning   = true
+				}`,
+			},
+			want: []result{
+				{Stack: "/a", Message: "a", Warning: true},
+				{Stack: "/a", Message: "root"},
+				{Stack: "/a/child", Message: "a", Warning: true},
+				{Stack: "/a/child", Message: "root"},
+				{Stack: "/z", Message: "root"},
+			},
+		},
+		{
+			name: "invalid assertion fails",
+			layout: []string{
+				"s:stack",
+				`f:assert.tm:assert {
+					assertion = "not a bool"
+					message   = "invalid"
+				}`,
+			},
+			wantErr: errors.E(config.ErrSchema),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := sandbox.NoGit(t, true)
+			s.BuildTree(tc.layout)
+
+			root, err := config.LoadRoot(s.RootDir())
+			assert.NoError(t, err)
+
+			all, err := config.LoadAllStacks(root.Tree())
+			assert.NoError(t, err)
+
+			var stacks []*config.Stack
+			for _, st := range all {
+				stacks = append(stacks, st.Stack)
+			}
+
+			results, err := check.Stacks(root, stacks)
+			errtest.Assert(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+
+			var got []result
+			for _, res := range results {
+				got = append(got, result{
+					Stack:   res.Stack.String(),
+					Message: res.Message,
+					Warning: res.Warning,
+				})
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("-(want) +(got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckResultsErrors(t *testing.T) {
+	t.Parallel()
+
+	results := check.Results{
+		{Message: "error 1"},
+		{Message: "warning", Warning: true},
+		{Message: "error 2"},
+	}
+
+	errs := results.Errors()
+	assert.EqualInts(t, 2, len(errs))
+	assert.EqualStrings(t, "error 1", errs[0].Message)
+	assert.EqualStrings(t, "error 2", errs[1].Message)
+}
@@ -0,0 +1,6 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+// Package check evaluates the assert blocks of the project for each stack,
+// independently of the code generation.
+package check
@@ -0,0 +1,100 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	stdjson "encoding/json"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/terramate-io/terramate/check"
+	cloudstack "github.com/terramate-io/terramate/cloud/stack"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+)
+
+func (c *cli) checkProject() {
+	report, err := c.listStacks(c.parsedArgs.Changed, cloudstack.NoFilter)
+	if err != nil {
+		fatal("listing stacks", err)
+	}
+
+	var stacks []*config.Stack
+	for _, entry := range c.filterStacks(report.Stacks) {
+		stacks = append(stacks, entry.Stack)
+	}
+
+	results, err := check.Stacks(c.cfg(), stacks)
+	if err != nil {
+		fatal("evaluating assertions", err)
+	}
+
+	switch c.parsedArgs.Experimental.Check.Format {
+	case "json":
+		if results == nil {
+			results = check.Results{}
+		}
+		data, err := stdjson.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fatal("encoding assertion results", err)
+		}
+		c.output.MsgStdOut("%s", data)
+	default:
+		for _, res := range results {
+			c.output.MsgStdOut("%s", res)
+		}
+	}
+
+	if len(results.Errors()) > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkStacksAssertions is the assertions safeguard, which fails if any
+// assertion of the given stacks fails. The assertions evaluated by the
+// outdated code check are reused, if it ran.
+func (c *cli) checkStacksAssertions(stacks config.List[*config.SortableStack]) {
+	logger := log.With().
+		Str("action", "checkStacksAssertions()").
+		Logger()
+
+	if !c.checkAssertions() {
+		return
+	}
+
+	var selected []*config.Stack
+	for _, st := range stacks {
+		selected = append(selected, st.Stack)
+	}
+
+	var results check.Results
+	if c.stackAsserts != nil {
+		results = check.FromAsserts(c.cfg(), selected, c.stackAsserts)
+	} else {
+		var err error
+		results, err = check.Stacks(c.cfg(), selected)
+		if err != nil {
+			fatal("evaluating assertions", err)
+		}
+	}
+
+	for _, res := range results {
+		if res.Warning {
+			logger.Warn().
+				Stringer("stack", res.Stack).
+				Stringer("origin", res.Range).
+				Msg(res.Message)
+		} else {
+			logger.Error().
+				Stringer("stack", res.Stack).
+				Stringer("origin", res.Range).
+				Msg(res.Message)
+		}
+	}
+
+	if len(results.Errors()) > 0 {
+		fatal(errors.E(check.ErrAssertion).Error(),
+			errors.E("please fix the assertions or disable this safeguard with: --disable-safeguards=assertions"))
+	}
+}
@@ -84,8 +84,6 @@ const (
 	defaultLogDest  = "stderr"
 )
 
-const defaultVendorDir = "/modules"
-
 const terramateUserConfigDir = ".terramate.d"
 
 const (
@@ -111,6 +109,8 @@ type cliSpec struct {
 	LogDestination string   `optional:"true" default:"stderr" enum:"stderr,stdout" help:"Destination of log messages"`
 	Quiet          bool     `optional:"false" help:"Disable output"`
 	Verbose        int      `short:"v" optional:"true" default:"0" type:"counter" help:"Increase verboseness of output"`
+	Strict         bool     `optional:"true" help:"Enable strict parsing of the configuration"`
+	Profile        string   `optional:"true" env:"TM_PROFILE" help:"Activate the given configuration profile"`
 
 	deprecatedGlobalSafeguardsCliSpec
 
@@ -133,9 +133,11 @@ type cliSpec struct {
 	} `cmd:"" help:"Creates a stack on the project"`
 
 	Fmt struct {
-		Files            []string `arg:"" optional:"true" predictor:"file" help:"files to be formatted"`
-		Check            bool     `hidden:"" help:"Lists unformatted files, exit with 0 if all is formatted, 1 otherwise"`
-		DetailedExitCode bool     `help:"Return an appropriate exit code (0 = ok, 1 = error, 2 = no error but changes were made)"`
+		Files               []string `arg:"" optional:"true" predictor:"file" help:"files to be formatted"`
+		Check               bool     `hidden:"" help:"Lists unformatted files, exit with 0 if all is formatted, 1 otherwise"`
+		DetailedExitCode    bool     `help:"Return an appropriate exit code (0 = ok, 1 = error, 2 = no error but changes were made)"`
+		Diff                bool     `help:"Print the formatting changes as unified diffs instead of the file names, without changing the files"`
+		SortStackAttributes bool     `help:"Sort the attributes of the stack blocks in the canonical order (name, description, id, tags, after, ...)"`
 	} `cmd:"" help:"Format all files inside dir recursively"`
 
 	List struct {
@@ -164,8 +166,15 @@ type cliSpec struct {
 
 	Generate struct {
 		DetailedExitCode bool `default:"false" help:"Return detailed exit code (0 = ok, 1 = errors, 2 = no errors but changes were made"`
+		Force            bool `default:"false" help:"Overwrite generated files even if they were manually edited"`
 	} `cmd:"" help:"Generate terraform code for stacks"`
 
+	Lint struct {
+		Rule        []string `help:"Only check the given rules"`
+		DisableRule []string `help:"Do not check the given rules"`
+		Format      string   `default:"human" enum:"human,json,sarif" help:"Output format: 'human', 'json' or 'sarif'"`
+	} `cmd:"" help:"Check the Terramate configuration for issues"`
+
 	Script struct {
 		List struct{} `cmd:"" help:"Show a list of all scripts in the current directory"`
 		Tree struct{} `cmd:"" help:"Show a tree of all scripts in the current directory"`
@@ -182,13 +191,26 @@ type cliSpec struct {
 		} `cmd:"" help:"Run script in stacks"`
 	} `cmd:"" help:"Terramate Script commands"`
 
+	Stack struct {
+		SetTags struct {
+			Tags []string `arg:"" optional:"true" name:"tag" help:"Tags of the stack, all tags are removed if none is given"`
+		} `cmd:"" help:"Sets the tags of the stack in the working directory"`
+		AddAfter struct {
+			Stacks []string `arg:"" name:"stack" predictor:"file" help:"Stacks which must run before the stack"`
+		} `cmd:"" help:"Adds stacks to the after list of the stack in the working directory"`
+	} `cmd:"" help:"Edits the stack in the working directory, preserving comments"`
+
 	InstallCompletions kongplete.InstallCompletions `cmd:"" help:"Install shell completions"`
 
 	Debug struct {
 		Show struct {
-			Metadata        struct{} `cmd:"" help:"Shows metadata available on the project"`
-			Globals         struct{} `cmd:"" help:"List globals for all stacks"`
+			Metadata struct{} `cmd:"" help:"Shows metadata available on the project"`
+			Globals  struct {
+				Origin bool `help:"Shows where each global is defined and the definitions it overrides"`
+				JSON   bool `help:"Outputs the globals and their origins as JSON"`
+			} `cmd:"" help:"List globals for all stacks"`
 			GenerateOrigins struct {
+				JSON bool `help:"Outputs the origins of the generated files, blocks and attributes as JSON"`
 			} `cmd:"" help:"Show generate debug information"`
 			RuntimeEnv struct{} `cmd:"" help:"List run environment variables for all stacks"`
 		} `cmd:"" help:"Show information available in the project"`
@@ -234,10 +256,23 @@ type cliSpec struct {
 			} `cmd:"" help:"Downloads a Terraform module and stores it on the project vendor dir"`
 		} `cmd:"" help:"Manages vendored Terraform modules"`
 
+		Schema struct {
+			Format string `default:"json-schema" enum:"json-schema,markdown" help:"Output format (json-schema, markdown)"`
+		} `cmd:"" help:"Outputs the schema of the Terramate configuration"`
+
+		Migrate struct {
+			DryRun bool `default:"false" help:"Show the changes as a diff instead of rewriting the files"`
+		} `cmd:"" help:"Migrates the configuration from deprecated syntax to the current syntax"`
+
+		Check struct {
+			Format string `default:"human" enum:"human,json" help:"Output format (human, json)"`
+		} `cmd:"" help:"Evaluates the assertions of the stacks"`
+
 		Eval struct {
-			Global map[string]string `short:"g" help:"set/override globals. eg.: --global name=<expr>"`
-			AsJSON bool              `help:"Outputs the result as a JSON value"`
-			Exprs  []string          `arg:"" help:"expressions to be evaluated" name:"expr" passthrough:""`
+			Global  map[string]string `short:"g" help:"set/override globals. eg.: --global name=<expr>"`
+			AsJSON  bool              `help:"Outputs the result as a JSON value"`
+			Explain bool              `help:"Outputs the evaluation tree of the expression"`
+			Exprs   []string          `arg:"" help:"expressions to be evaluated" name:"expr" passthrough:""`
 		} `cmd:"" help:"Eval expression"`
 
 		PartialEval struct {
@@ -246,9 +281,10 @@ type cliSpec struct {
 		} `cmd:"" help:"Partial evaluate the expressions"`
 
 		GetConfigValue struct {
-			Global map[string]string `short:"g" help:"set/override globals. eg.: --global name=<expr>"`
-			AsJSON bool              `help:"Outputs the result as a JSON value"`
-			Vars   []string          `arg:"" help:"variable to be retrieved" name:"var" passthrough:""`
+			Global  map[string]string `short:"g" help:"set/override globals. eg.: --global name=<expr>"`
+			AsJSON  bool              `help:"Outputs the result as a JSON value"`
+			Explain bool              `help:"Outputs the evaluation tree of the variable"`
+			Vars    []string          `arg:"" help:"variable to be retrieved" name:"var" passthrough:""`
 		} `cmd:"" help:"Get configuration value"`
 
 		Cloud struct {
@@ -265,7 +301,7 @@ type cliSpec struct {
 type runSafeguardsCliSpec struct {
 	// Note: The `name` and `short` are being used to define the -X flag without longer version.
 	DisableSafeguardsAll bool               `default:"false" name:"disable-safeguards=all" short:"X" help:"Disable all safeguards"`
-	DisableSafeguards    safeguard.Keywords `env:"TM_DISABLE_SAFEGUARDS" enum:"git,all,none,git-untracked,git-uncommitted,outdated-code,git-out-of-sync" help:"Disable safeguards: {all,none,git,git-untracked,git-uncommitted,outdated-code,git-out-of-sync}"`
+	DisableSafeguards    safeguard.Keywords `env:"TM_DISABLE_SAFEGUARDS" enum:"git,all,none,git-untracked,git-uncommitted,outdated-code,git-out-of-sync,assertions" help:"Disable safeguards: {all,none,git,git-untracked,git-uncommitted,outdated-code,git-out-of-sync,assertions}"`
 
 	DeprecatedDisableCheckGenCode   bool `hidden:"" default:"false" name:"disable-check-gen-code" env:"TM_DISABLE_CHECK_GEN_CODE" help:"Disable outdated generated code check"`
 	DeprecatedDisableCheckGitRemote bool `hidden:"" default:"false" name:"disable-check-git-remote" env:"TM_DISABLE_CHECK_GIT_REMOTE" help:"Disable checking if local default branch is updated with remote"`
@@ -281,6 +317,7 @@ type safeguards struct {
 	DisableCheckGitUncommitted        bool
 	DisableCheckGitRemote             bool
 	DisableCheckGenerateOutdatedCheck bool
+	DisableCheckAssertions            bool
 
 	reEnabled bool
 }
@@ -326,6 +363,10 @@ type cli struct {
 	uimode         UIMode
 	affectedStacks []stack.Entry
 
+	// stackAsserts are the assertions of the stacks evaluated by the outdated
+	// code check, reused by the assertions safeguard.
+	stackAsserts map[prj.Path][]config.Assert
+
 	safeguards safeguards
 
 	checkpointResults chan *checkpoint.CheckResponse
@@ -474,6 +515,9 @@ func newCLI(version string, args []string, stdin io.Reader, stdout, stderr io.Wr
 			}
 		}
 
+		return &cli{exit: true}
+	case "experimental schema":
+		printSchema(output, parsedArgs.Experimental.Schema.Format)
 		return &cli{exit: true}
 	case "install-completions":
 		logger.Debug().Msg("Handle `install-completions` command.")
@@ -523,7 +567,7 @@ func newCLI(version string, args []string, stdin io.Reader, stdout, stderr io.Wr
 		fatal(sprintf("evaluating symlinks on working dir: %s", wd), err)
 	}
 
-	prj, foundRoot, err := lookupProject(wd)
+	prj, foundRoot, err := lookupProject(wd, parsedArgs.Strict, parsedArgs.Profile)
 	if err != nil {
 		fatal("unable to parse configuration", err)
 	}
@@ -617,6 +661,8 @@ func (c *cli) run() {
 		c.runOnStacks()
 	case "generate":
 		c.generate()
+	case "lint":
+		c.lint()
 	case "experimental clone <srcdir> <destdir>":
 		c.cloneStack()
 	case "experimental trigger":
@@ -625,6 +671,11 @@ func (c *cli) run() {
 		c.triggerStack(c.parsedArgs.Experimental.Trigger.Stack)
 	case "experimental vendor download <source> <ref>":
 		c.vendorDownload()
+	case "experimental migrate":
+		c.migrate()
+	case "experimental check":
+		c.setupGit()
+		c.checkProject()
 	case "debug show globals":
 		c.setupGit()
 		c.printStacksGlobals()
@@ -683,6 +734,12 @@ func (c *cli) run() {
 		c.setupGit()
 		c.setupSafeguards(c.parsedArgs.Script.Run.runSafeguardsCliSpec)
 		c.runScript()
+	case "stack set-tags":
+		c.setStackTags()
+	case "stack set-tags <tag>":
+		c.setStackTags()
+	case "stack add-after <stack>":
+		c.addStackAfter()
 	default:
 		fatal("unexpected command sequence", nil)
 	}
@@ -719,6 +776,7 @@ func (c *cli) setupSafeguards(run runSafeguardsCliSpec) {
 	c.safeguards.DisableCheckGitUntracked = run.DisableSafeguards.Has(safeguard.GitUntracked, safeguard.All, safeguard.Git)
 	c.safeguards.DisableCheckGitRemote = run.DisableSafeguards.Has(safeguard.GitOutOfSync, safeguard.All, safeguard.Git)
 	c.safeguards.DisableCheckGenerateOutdatedCheck = run.DisableSafeguards.Has(safeguard.Outdated, safeguard.All)
+	c.safeguards.DisableCheckAssertions = run.DisableSafeguards.Has(safeguard.Assertions, safeguard.All)
 	if run.DisableSafeguards.Has("none") {
 		c.safeguards = safeguards{}
 		c.safeguards.reEnabled = true
@@ -762,6 +820,9 @@ func (c *cli) vendorDownload() {
 		Logger()
 
 	parsedSource, err := tf.ParseSource(source)
+	if err != nil && tf.IsRegistrySource(source) {
+		parsedSource, err = tf.ParseRegistrySource(source)
+	}
 	if err != nil {
 		fatal(sprintf("parsing module source %s: %s", source, err), nil)
 	}
@@ -825,40 +886,23 @@ func (c *cli) vendorDir() prj.Path {
 		return prj.NewPath(dir)
 	}
 
-	checkVendorDir := func(dir string) prj.Path {
-		if !path.IsAbs(dir) {
-			fatal(sprintf("vendorDir %s defined is not an absolute path", dir), nil)
-		}
-		return prj.NewPath(dir)
-	}
-
+	cfgs := []hcl.Config{}
 	dotTerramate := filepath.Join(c.rootdir(), ".terramate")
 	dotTerramateInfo, err := os.Stat(dotTerramate)
-
 	if err == nil && dotTerramateInfo.IsDir() {
-
-		cfg, err := hcl.ParseDir(c.rootdir(), filepath.Join(c.rootdir(), ".terramate"))
+		cfg, err := hcl.ParseDir(c.rootdir(), dotTerramate)
 		if err != nil {
 			fatal("parsing vendor dir configuration on .terramate", err)
 		}
-
-		if hasVendorDirConfig(cfg) {
-
-			return checkVendorDir(cfg.Vendor.Dir)
-		}
+		cfgs = append(cfgs, cfg)
 	}
+	cfgs = append(cfgs, c.rootNode())
 
-	hclcfg := c.rootNode()
-	if hasVendorDirConfig(hclcfg) {
-
-		return checkVendorDir(hclcfg.Vendor.Dir)
+	dir, err := hcl.ConfiguredVendorDir(cfgs...)
+	if err != nil {
+		fatal("loading the vendor dir configuration", err)
 	}
-
-	return prj.NewPath(defaultVendorDir)
-}
-
-func hasVendorDirConfig(cfg hcl.Config) bool {
-	return cfg.Vendor != nil && cfg.Vendor.Dir != ""
+	return dir
 }
 
 func (c *cli) triggerStackByFilter() {
@@ -987,7 +1031,9 @@ func (c *cli) gencodeWithVendor() (generate.Report, download.Report) {
 
 	log.Debug().Msg("generating code")
 
-	report := generate.Do(c.cfg(), c.vendorDir(), vendorRequestEvents)
+	report := generate.DoWithOptions(c.cfg(), c.vendorDir(), vendorRequestEvents, generate.Options{
+		Force: c.parsedArgs.Generate.Force,
+	})
 
 	log.Debug().Msg("code generation finished, waiting for vendor requests to be handled")
 
@@ -1222,7 +1268,7 @@ func (c *cli) initTerraform() {
 		return
 	}
 
-	root, err := config.LoadRoot(c.rootdir())
+	root, err := loadRoot(c.rootdir(), c.parsedArgs.Strict, c.parsedArgs.Profile)
 	if err != nil {
 		fatal("reloading the configuration", err)
 	}
@@ -1416,11 +1462,15 @@ func (c *cli) format() {
 		fatal("Invalid args", errors.E("--check conflicts with --detailed-exit-code"))
 	}
 
+	opts := fmt.Options{
+		SortStackAttributes: c.parsedArgs.Fmt.SortStackAttributes,
+	}
+
 	var results []fmt.FormatResult
 	switch len(c.parsedArgs.Fmt.Files) {
 	case 0:
 		var err error
-		results, err = fmt.FormatTree(c.wd())
+		results, err = fmt.FormatTreeWithOptions(c.wd(), opts)
 		if err != nil {
 			fatal(sprintf("formatting directory %s", c.wd()), err)
 		}
@@ -1431,11 +1481,15 @@ func (c *cli) format() {
 				fatal("reading stdin", err)
 			}
 			original := string(content)
-			formatted, err := fmt.Format(original, "<stdin>")
+			formatted, err := fmt.FormatWithOptions(original, "<stdin>", opts)
 			if err != nil {
 				fatal("formatting stdin", err)
 			}
 
+			if c.parsedArgs.Fmt.Diff && formatted != original {
+				c.output.MsgStdOut("%s", strings.TrimSuffix(fmt.Diff("stdin", original, formatted), "\n"))
+			}
+
 			if c.parsedArgs.Fmt.Check {
 				var status int
 				if formatted != original {
@@ -1444,14 +1498,16 @@ func (c *cli) format() {
 				os.Exit(status)
 			}
 
-			stdfmt.Print(formatted)
+			if !c.parsedArgs.Fmt.Diff {
+				stdfmt.Print(formatted)
+			}
 			return
 		}
 
 		fallthrough
 	default:
 		var err error
-		results, err = fmt.FormatFiles(c.wd(), c.parsedArgs.Fmt.Files)
+		results, err = fmt.FormatFilesWithOptions(c.wd(), c.parsedArgs.Fmt.Files, opts)
 		if err != nil {
 			fatal("formatting files", err)
 		}
@@ -1459,6 +1515,10 @@ func (c *cli) format() {
 
 	for _, res := range results {
 		path := strings.TrimPrefix(res.Path(), c.wd()+string(filepath.Separator))
+		if c.parsedArgs.Fmt.Diff {
+			c.output.MsgStdOut("%s", strings.TrimSuffix(res.Diff(filepath.ToSlash(path)), "\n"))
+			continue
+		}
 		c.output.MsgStdOut(path)
 	}
 
@@ -1472,6 +1532,11 @@ func (c *cli) format() {
 		}
 	}
 
+	// with --diff the changes are only shown, like for stdin.
+	if c.parsedArgs.Fmt.Diff {
+		return
+	}
+
 	errs := errors.L()
 	for _, res := range results {
 		errs.Append(res.Save())
@@ -1563,7 +1628,7 @@ func (c *cli) printRuntimeEnv() {
 	}
 
 	for _, stackEntry := range c.filterStacks(report.Stacks) {
-		envVars, err := run.LoadEnv(c.cfg(), stackEntry.Stack)
+		envVars, err := run.LoadRedactedEnv(c.cfg(), stackEntry.Stack)
 		if err != nil {
 			fatal("loading stack run environment", err)
 		}
@@ -1757,6 +1822,11 @@ func (c *cli) generateDebug() {
 		selectedStacks[stack.Dir()] = struct{}{}
 	}
 
+	if c.parsedArgs.Debug.Show.GenerateOrigins.JSON {
+		c.generateDebugJSON(selectedStacks)
+		return
+	}
+
 	results, err := generate.Load(c.cfg(), c.vendorDir())
 	if err != nil {
 		fatal("generate debug: loading generated code", err)
@@ -1788,12 +1858,49 @@ func (c *cli) generateDebug() {
 	}
 }
 
+func (c *cli) generateDebugJSON(selectedStacks map[prj.Path]struct{}) {
+	results, err := generate.LoadProvenance(c.cfg(), c.vendorDir())
+	if err != nil {
+		fatal("generate debug: loading generated code", err)
+	}
+
+	files := []generate.FileProvenance{}
+	for _, res := range results {
+		if _, ok := selectedStacks[res.Dir]; !ok {
+			log.Debug().Msgf("discarding dir %s since it is not a selected stack", res.Dir)
+			continue
+		}
+		if res.Err != nil {
+			errmsg := stdfmt.Sprintf("generate debug error on dir %s: %v", res.Dir, res.Err)
+			log.Error().Msg(errmsg)
+			c.output.MsgStdErr(errmsg)
+			continue
+		}
+		files = append(files, res.Files...)
+	}
+
+	data, err := stdjson.MarshalIndent(files, "", "  ")
+	if err != nil {
+		fatal("generate debug: encoding JSON", err)
+	}
+	c.output.MsgStdOut(string(data))
+}
+
 func (c *cli) printStacksGlobals() {
 	report, err := c.listStacks(c.parsedArgs.Changed, cloudstack.NoFilter)
 	if err != nil {
 		fatal("listing stacks globals: listing stacks", err)
 	}
 
+	type stackGlobals struct {
+		Stack   prj.Path        `json:"stack"`
+		Globals []globalsOrigin `json:"globals"`
+	}
+
+	showOrigin := c.parsedArgs.Debug.Show.Globals.Origin
+	asJSON := c.parsedArgs.Debug.Show.Globals.JSON
+	jsonRes := []stackGlobals{}
+
 	for _, stackEntry := range c.filterStacks(report.Stacks) {
 		stack := stackEntry.Stack
 		report := globals.ForStack(c.cfg(), stack)
@@ -1801,6 +1908,44 @@ func (c *cli) printStacksGlobals() {
 			fatal(sprintf("listing stacks globals: loading stack at %s", stack.Dir), err)
 		}
 
+		if asJSON {
+			origins := []globalsOrigin{}
+			for _, origin := range report.Origins() {
+				origin.Value = eval.Redact(origin.Value)
+				data, err := json.Marshal(origin.Value, origin.Value.Type())
+				if err != nil {
+					fatal(sprintf("listing stacks globals: encoding %s", origin.Path), err)
+				}
+				origins = append(origins, globalsOrigin{
+					Origin: origin,
+					Value:  data,
+				})
+			}
+			jsonRes = append(jsonRes, stackGlobals{
+				Stack:   stack.Dir,
+				Globals: origins,
+			})
+			continue
+		}
+
+		if showOrigin {
+			origins := report.Origins()
+			if len(origins) == 0 {
+				continue
+			}
+
+			c.output.MsgStdOut("\nstack %q:", stack.Dir)
+			for _, origin := range origins {
+				c.output.MsgStdOut("\t%s = %s", origin.Path,
+					strings.TrimSpace(string(hclwrite.TokensForValue(eval.Redact(origin.Value)).Bytes())))
+				c.output.MsgStdOut("\t\tdefined at %s (dir %s)", origin.Range.String(), origin.Dir)
+				for _, def := range origin.Overridden {
+					c.output.MsgStdOut("\t\toverrides %s (dir %s)", def.Range.String(), def.Dir)
+				}
+			}
+			continue
+		}
+
 		globalsStrRepr := report.Globals.String()
 		if globalsStrRepr == "" {
 			continue
@@ -1811,6 +1956,20 @@ func (c *cli) printStacksGlobals() {
 			c.output.MsgStdOut("\t%s", line)
 		}
 	}
+
+	if asJSON {
+		data, err := stdjson.MarshalIndent(jsonRes, "", "  ")
+		if err != nil {
+			fatal("listing stacks globals: encoding JSON", err)
+		}
+		c.output.MsgStdOut(string(data))
+	}
+}
+
+// globalsOrigin is the JSON representation of a global and its origin.
+type globalsOrigin struct {
+	globals.Origin
+	Value stdjson.RawMessage `json:"value"`
 }
 
 func (c *cli) printMetadata() {
@@ -1876,6 +2035,22 @@ func (c *cli) checkGenCode() bool {
 
 }
 
+func (c *cli) checkAssertions() bool {
+	if c.safeguards.DisableCheckAssertions {
+		return false
+	}
+
+	if c.safeguards.reEnabled {
+		return !c.safeguards.DisableCheckAssertions
+	}
+
+	cfg := c.rootNode()
+	if cfg.Terramate == nil || cfg.Terramate.Config == nil {
+		return true
+	}
+	return !cfg.Terramate.Config.HasSafeguardDisabled(safeguard.Assertions)
+}
+
 func (c *cli) ensureStackID() {
 	report, err := c.listStacks(false, cloudstack.NoFilter)
 	if err != nil {
@@ -1897,12 +2072,22 @@ func (c *cli) ensureStackID() {
 }
 
 func (c *cli) eval() {
-	ctx := c.detectEvalContext(c.parsedArgs.Experimental.Eval.Global)
+	if c.parsedArgs.Experimental.Eval.Explain && c.parsedArgs.Experimental.Eval.AsJSON {
+		fatal("Invalid args", errors.E("the --explain flag can't be used together with --as-json"))
+	}
+	ctx, report := c.detectEvalContext(c.parsedArgs.Experimental.Eval.Global)
 	for _, exprStr := range c.parsedArgs.Experimental.Eval.Exprs {
 		expr, err := ast.ParseExpression(exprStr, "<cmdline>")
 		if err != nil {
 			fatal("unable to parse expression", err)
 		}
+		if c.parsedArgs.Experimental.Eval.Explain {
+			trace := c.explainExpr(ctx, report, expr)
+			if trace.Err != nil {
+				fatal(sprintf("eval %q", exprStr), trace.Err)
+			}
+			continue
+		}
 		val, err := ctx.Eval(expr)
 		if err != nil {
 			fatal(sprintf("eval %q", exprStr), err)
@@ -1912,7 +2097,7 @@ func (c *cli) eval() {
 }
 
 func (c *cli) partialEval() {
-	ctx := c.detectEvalContext(c.parsedArgs.Experimental.PartialEval.Global)
+	ctx, _ := c.detectEvalContext(c.parsedArgs.Experimental.PartialEval.Global)
 	for _, exprStr := range c.parsedArgs.Experimental.PartialEval.Exprs {
 		expr, err := ast.ParseExpression(exprStr, "<cmdline>")
 		if err != nil {
@@ -1922,12 +2107,13 @@ func (c *cli) partialEval() {
 		if err != nil {
 			fatal(sprintf("partial eval %q", exprStr), err)
 		}
+		eval.RedactExpr(newexpr)
 		c.output.MsgStdOut("%s", string(hclwrite.Format(ast.TokensForExpression(newexpr).Bytes())))
 	}
 }
 
 func (c *cli) evalRunArgs(st *config.Stack, cmd []string) ([]string, error) {
-	ctx := c.setupEvalContext(st, map[string]string{})
+	ctx, _ := c.setupEvalContext(st, map[string]string{})
 	var newargs []string
 	for _, arg := range cmd {
 		exprStr := `"` + arg + `"`
@@ -1943,13 +2129,17 @@ func (c *cli) evalRunArgs(st *config.Stack, cmd []string) ([]string, error) {
 			return nil, errors.E("cmd line evaluates to type %s but only string is permitted", val.Type().FriendlyName())
 		}
 
+		val, _ = val.Unmark()
 		newargs = append(newargs, val.AsString())
 	}
 	return newargs, nil
 }
 
 func (c *cli) getConfigValue() {
-	ctx := c.detectEvalContext(c.parsedArgs.Experimental.GetConfigValue.Global)
+	if c.parsedArgs.Experimental.GetConfigValue.Explain && c.parsedArgs.Experimental.GetConfigValue.AsJSON {
+		fatal("Invalid args", errors.E("the --explain flag can't be used together with --as-json"))
+	}
+	ctx, report := c.detectEvalContext(c.parsedArgs.Experimental.GetConfigValue.Global)
 	for _, exprStr := range c.parsedArgs.Experimental.GetConfigValue.Vars {
 		expr, err := ast.ParseExpression(exprStr, "<cmdline>")
 		if err != nil {
@@ -1966,6 +2156,14 @@ func (c *cli) getConfigValue() {
 			fatal("only terramate and global variables are supported", nil)
 		}
 
+		if c.parsedArgs.Experimental.GetConfigValue.Explain {
+			trace := c.explainExpr(ctx, report, expr)
+			if trace.Err != nil {
+				fatal(sprintf("evaluating expression: %s", exprStr), trace.Err)
+			}
+			continue
+		}
+
 		val, err := ctx.Eval(expr)
 		if err != nil {
 			fatal(sprintf("evaluating expression: %s", exprStr), err)
@@ -1976,6 +2174,7 @@ func (c *cli) getConfigValue() {
 }
 
 func (c *cli) outputEvalResult(val cty.Value, asJSON bool) {
+	val = eval.Redact(val)
 	var data []byte
 	if asJSON {
 		var err error
@@ -1995,7 +2194,7 @@ func (c *cli) outputEvalResult(val cty.Value, asJSON bool) {
 	c.output.MsgStdOut("%s", string(data))
 }
 
-func (c *cli) detectEvalContext(overrideGlobals map[string]string) *eval.Context {
+func (c *cli) detectEvalContext(overrideGlobals map[string]string) (*eval.Context, globals.EvalReport) {
 	var st *config.Stack
 	if config.IsStack(c.cfg(), c.wd()) {
 		var err error
@@ -2007,7 +2206,7 @@ func (c *cli) detectEvalContext(overrideGlobals map[string]string) *eval.Context
 	return c.setupEvalContext(st, overrideGlobals)
 }
 
-func (c *cli) setupEvalContext(st *config.Stack, overrideGlobals map[string]string) *eval.Context {
+func (c *cli) setupEvalContext(st *config.Stack, overrideGlobals map[string]string) (*eval.Context, globals.EvalReport) {
 	runtime := c.cfg().Runtime()
 
 	var tdir string
@@ -2019,6 +2218,7 @@ func (c *cli) setupEvalContext(st *config.Stack, overrideGlobals map[string]stri
 	}
 
 	ctx := eval.NewContext(stdlib.NoFS(tdir))
+	globals.SetFunctions(c.cfg(), ctx)
 	ctx.SetNamespace("terramate", runtime)
 
 	wdPath := prj.PrjAbsPath(c.rootdir(), tdir)
@@ -2050,8 +2250,8 @@ func (c *cli) setupEvalContext(st *config.Stack, overrideGlobals map[string]stri
 			}),
 		)
 	}
-	_ = exprs.Eval(ctx)
-	return ctx
+	report := exprs.Eval(ctx)
+	return ctx, report
 }
 
 func envVarIsSet(val string) bool {
@@ -2067,10 +2267,11 @@ func (c *cli) checkOutdatedGeneratedCode() {
 		return
 	}
 
-	outdatedFiles, err := generate.DetectOutdated(c.cfg(), c.vendorDir())
+	outdatedFiles, asserts, err := generate.DetectOutdatedWithAsserts(c.cfg(), c.vendorDir())
 	if err != nil {
 		fatal("failed to check outdated code on project", err)
 	}
+	c.stackAsserts = asserts
 
 	for _, outdated := range outdatedFiles {
 		logger.Error().
@@ -2078,6 +2279,22 @@ func (c *cli) checkOutdatedGeneratedCode() {
 			Msg("outdated code found")
 	}
 
+	editedFiles, err := generate.DetectManualEdits(c.cfg(), outdatedFiles)
+	if err != nil {
+		fatal("failed to check manually edited code on project", err)
+	}
+
+	for _, edited := range editedFiles {
+		logger.Error().
+			Str("filename", edited).
+			Msg("manually edited generated code found")
+	}
+
+	if len(editedFiles) > 0 {
+		fatal(errors.E(ErrOutdatedGenCodeDetected).Error(),
+			errors.E("please revert the manual changes or run: 'terramate generate --force' to overwrite them"))
+	}
+
 	if len(outdatedFiles) > 0 {
 		fatal(errors.E(ErrOutdatedGenCodeDetected).Error(),
 			errors.E("please run: 'terramate generate' to update generated code"))
@@ -2304,7 +2521,7 @@ func newGit(basedir string) (*git.Git, error) {
 	return g, nil
 }
 
-func lookupProject(wd string) (prj project, found bool, err error) {
+func lookupProject(wd string, strict bool, profile string) (prj project, found bool, err error) {
 	prj = project{
 		wd: wd,
 	}
@@ -2336,7 +2553,7 @@ func lookupProject(wd string) (prj project, found bool, err error) {
 					Msg("ignoring root config")
 			}
 
-			cfg, err := config.LoadRoot(rootdir)
+			cfg, err := loadRoot(rootdir, strict, profile)
 			if err != nil {
 				return project{}, false, err
 			}
@@ -2359,12 +2576,40 @@ func lookupProject(wd string) (prj project, found bool, err error) {
 		return project{}, false, nil
 	}
 
+	if strict && !rootcfg.Tree().Node.Strict() {
+		rootcfg, err = config.LoadRootStrict(rootCfgPath)
+		if err != nil {
+			return project{}, false, err
+		}
+	}
+
+	err = rootcfg.SetProfile(profile)
+	if err != nil {
+		return project{}, false, err
+	}
+
 	prj.rootdir = rootCfgPath
 	prj.root = *rootcfg
 	prj.stackManager = stack.NewManager(&prj.root)
 	return prj, true, nil
 }
 
+func loadRoot(rootdir string, strict bool, profile string) (*config.Root, error) {
+	load := config.LoadRoot
+	if strict {
+		load = config.LoadRootStrict
+	}
+	root, err := load(rootdir)
+	if err != nil {
+		return nil, err
+	}
+	err = root.SetProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
 func configureLogging(logLevel, logFmt, logdest string, stdout, stderr io.Writer) {
 	var output io.Writer
 
@@ -0,0 +1,134 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// explainExpr evaluates the expression and prints its evaluation tree.
+// The globals report is used to tell the origin of the referenced globals.
+func (c *cli) explainExpr(ctx *eval.Context, report globals.EvalReport, expr hhcl.Expression) *eval.Trace {
+	trace := ctx.Explain(expr)
+	var sb strings.Builder
+	printTrace(&sb, "", "", trace, report, report.Origins())
+	c.output.MsgStdOut("%s", strings.TrimSuffix(sb.String(), "\n"))
+	return trace
+}
+
+func printTrace(
+	w io.Writer,
+	prefix, childPrefix string,
+	trace *eval.Trace,
+	report globals.EvalReport,
+	origins []globals.Origin,
+) {
+	detailPrefix := childPrefix
+	if len(trace.Children) > 0 {
+		detailPrefix += "│   "
+	} else {
+		detailPrefix += "    "
+	}
+
+	// continuation lines of multiline expressions and values are indented
+	// at the level of the trace details.
+	text := indentLines(exprString(trace.Expr), detailPrefix)
+	if trace.Kind == eval.TraceCall {
+		text = "call " + text
+	}
+
+	switch {
+	case trace.Err != nil:
+		fmt.Fprintf(w, "%s%s: error: %s\n", prefix, text,
+			indentLines(trace.Err.Error(), detailPrefix))
+	case trace.IsUnknown():
+		fmt.Fprintf(w, "%s%s = (unknown)\n", prefix, text)
+	default:
+		fmt.Fprintf(w, "%s%s = %s\n", prefix, text,
+			indentLines(valueString(trace.Value), detailPrefix))
+	}
+
+	if trace.Kind == eval.TraceVariable {
+		printGlobalOrigins(w, detailPrefix, trace.Expr, report, origins)
+	}
+
+	for i, child := range trace.Children {
+		if i == len(trace.Children)-1 {
+			printTrace(w, childPrefix+"└── ", childPrefix+"    ", child, report, origins)
+		} else {
+			printTrace(w, childPrefix+"├── ", childPrefix+"│   ", child, report, origins)
+		}
+	}
+}
+
+// printGlobalOrigins prints where the globals referenced by the expression
+// are defined, given the origins of all globals of the report, and the errors
+// which made them undefined, if any.
+func printGlobalOrigins(
+	w io.Writer,
+	prefix string,
+	expr hhcl.Expression,
+	report globals.EvalReport,
+	origins []globals.Origin,
+) {
+	traversal, diags := hhcl.AbsTraversalForExpr(expr)
+	if diags.HasErrors() || traversal.RootName() != "global" {
+		return
+	}
+
+	name := "global"
+	for _, step := range traversal[1:] {
+		switch s := step.(type) {
+		case hhcl.TraverseAttr:
+			name += "." + s.Name
+		case hhcl.TraverseIndex:
+			if s.Key.Type() == cty.String {
+				name += "." + s.Key.AsString()
+			}
+		}
+	}
+
+	for _, origin := range origins {
+		if origin.Path == name || strings.HasPrefix(origin.Path, name+".") {
+			fmt.Fprintf(w, "%s%s defined at %s (dir %s)\n", prefix, origin.Path,
+				origin.Range.String(), origin.Dir)
+		}
+	}
+
+	var failures []string
+	for key, evalErr := range report.Errors {
+		errname := "global." + strings.Join(key.Path(), ".")
+		if errname == name || strings.HasPrefix(errname, name+".") ||
+			strings.HasPrefix(name, errname+".") {
+			failures = append(failures, fmt.Sprintf("%s%s failed at %s: %s\n", prefix, errname,
+				evalErr.Expr.Origin.String(), indentLines(evalErr.Err.Error(), prefix)))
+		}
+	}
+	sort.Strings(failures)
+	for _, failure := range failures {
+		fmt.Fprint(w, failure)
+	}
+}
+
+func exprString(expr hhcl.Expression) string {
+	return strings.TrimSpace(string(hclwrite.Format(ast.TokensForExpression(expr).Bytes())))
+}
+
+func valueString(val cty.Value) string {
+	return strings.TrimSpace(string(hclwrite.TokensForValue(eval.Redact(val)).Bytes()))
+}
+
+func indentLines(s, prefix string) string {
+	return strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
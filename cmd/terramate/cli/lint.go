@@ -0,0 +1,48 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	stdjson "encoding/json"
+	"os"
+
+	"github.com/terramate-io/terramate/lint"
+	prj "github.com/terramate-io/terramate/project"
+)
+
+func (c *cli) lint() {
+	issues, err := lint.Lint(c.cfg(), prj.PrjAbsPath(c.rootdir(), c.wd()), lint.Options{
+		Rules:         c.parsedArgs.Lint.Rule,
+		DisabledRules: c.parsedArgs.Lint.DisableRule,
+	})
+	if err != nil {
+		fatal("linting project", err)
+	}
+
+	switch c.parsedArgs.Lint.Format {
+	case "json":
+		if issues == nil {
+			issues = lint.Issues{}
+		}
+		data, err := stdjson.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			fatal("lint: encoding JSON", err)
+		}
+		c.output.MsgStdOut("%s", data)
+	case "sarif":
+		data, err := issues.SARIF(c.version)
+		if err != nil {
+			fatal("lint: encoding SARIF", err)
+		}
+		c.output.MsgStdOut("%s", data)
+	default:
+		for _, issue := range issues {
+			c.output.MsgStdOut("%s", issue)
+		}
+	}
+
+	if issues.HasErrors() {
+		os.Exit(1)
+	}
+}
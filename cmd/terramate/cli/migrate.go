@@ -0,0 +1,32 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/terramate-io/terramate/migrate"
+	prj "github.com/terramate-io/terramate/project"
+)
+
+func (c *cli) migrate() {
+	files, err := migrate.Dir(c.cfg(), prj.PrjAbsPath(c.rootdir(), c.wd()))
+	if err != nil {
+		fatal("migrating configuration", err)
+	}
+
+	for _, f := range files {
+		if c.parsedArgs.Experimental.Migrate.DryRun {
+			c.output.MsgStdOut("%s", strings.TrimSuffix(f.Diff(), "\n"))
+			continue
+		}
+		if err := f.Write(); err != nil {
+			fatal("migrating configuration", err)
+		}
+		c.output.MsgStdOut("%s", f.Path)
+		for _, change := range f.Changes {
+			c.output.MsgStdOut("\t%s", change)
+		}
+	}
+}
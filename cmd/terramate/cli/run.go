@@ -91,6 +91,8 @@ func (c *cli) runOnStacks() {
 		}
 	}
 
+	c.checkStacksAssertions(stacks)
+
 	reason, err := runutil.Sort(c.cfg(), stacks,
 		func(s *config.SortableStack) *config.Stack { return s.Stack })
 	if err != nil {
@@ -0,0 +1,23 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"github.com/terramate-io/terramate/cmd/terramate/cli/out"
+	"github.com/terramate-io/terramate/hcl"
+)
+
+func printSchema(output out.O, format string) {
+	schema := hcl.Schema()
+	switch format {
+	case "markdown":
+		output.MsgStdOut("%s", schema.Markdown())
+	default:
+		data, err := schema.JSONSchema()
+		if err != nil {
+			fatal("schema: encoding JSON Schema", err)
+		}
+		output.MsgStdOut("%s", data)
+	}
+}
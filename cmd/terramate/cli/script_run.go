@@ -46,6 +46,8 @@ func (c *cli) runScript() {
 		}
 	}
 
+	c.checkStacksAssertions(stacks)
+
 	// search for the script and prepare a list of script/stack entries
 	m := newScriptsMatcher(c.parsedArgs.Script.Run.Cmds)
 	m.Search(c.cfg(), stacks)
@@ -192,6 +194,7 @@ func scriptEvalContext(root *config.Root, st *config.Stack) (*eval.Context, erro
 	}
 
 	evalctx := eval.NewContext(stdlib.Functions(st.HostDir(root)))
+	globals.SetFunctions(root, evalctx)
 	runtime := root.Runtime()
 	runtime.Merge(st.RuntimeValues(root))
 	evalctx.SetNamespace("terramate", runtime)
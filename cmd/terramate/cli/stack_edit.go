@@ -0,0 +1,81 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"path"
+	"strings"
+
+	"github.com/terramate-io/terramate/config/tag"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/edit"
+	prj "github.com/terramate-io/terramate/project"
+)
+
+func (c *cli) setStackTags() {
+	tags := c.parsedArgs.Stack.SetTags.Tags
+	errs := errors.L()
+	for _, t := range tags {
+		errs.Append(tag.Validate(t))
+	}
+	if err := errs.AsError(); err != nil {
+		fatal("invalid tags", err)
+	}
+	c.editStack(func(dir *edit.Dir) error {
+		return dir.SetStackList("tags", tags)
+	})
+}
+
+func (c *cli) addStackAfter() {
+	stacks := c.parsedArgs.Stack.AddAfter.Stacks
+	stackdir := prj.PrjAbsPath(c.rootdir(), c.wd())
+	errs := errors.L()
+	for i, pathstr := range stacks {
+		if strings.HasPrefix(pathstr, "tag:") {
+			continue
+		}
+		var target prj.Path
+		if path.IsAbs(pathstr) {
+			target = prj.NewPath(path.Clean(pathstr))
+			stacks[i] = target.String()
+		} else {
+			target = stackdir.Join(pathstr)
+			stacks[i] = path.Clean(pathstr)
+		}
+		if target == stackdir {
+			errs.Append(errors.E("stack %s cannot run after itself", stackdir))
+			continue
+		}
+		node, ok := c.cfg().Lookup(target)
+		if !ok || len(node.Stacks()) == 0 {
+			errs.Append(errors.E("path %s does not contain any stack", target))
+		}
+	}
+	if err := errs.AsError(); err != nil {
+		fatal("invalid stacks", err)
+	}
+	c.editStack(func(dir *edit.Dir) error {
+		return dir.AddStackListValues("after", stacks...)
+	})
+}
+
+// editStack applies the edit to the stack of the working directory and writes
+// the changed files.
+func (c *cli) editStack(apply func(dir *edit.Dir) error) {
+	stackdir := prj.PrjAbsPath(c.rootdir(), c.wd())
+	dir, err := edit.LoadDir(c.rootdir(), c.wd())
+	if err != nil {
+		fatal(sprintf("loading stack %s", stackdir), err)
+	}
+	if err := apply(dir); err != nil {
+		fatal(sprintf("editing stack %s", stackdir), err)
+	}
+	files := dir.Files()
+	if err := dir.Save(); err != nil {
+		fatal(sprintf("editing stack %s", stackdir), err)
+	}
+	for _, f := range files {
+		c.output.MsgStdOut("Updated %s", f.Path)
+	}
+}
@@ -215,3 +215,91 @@ func TestGenerateDebugWithChanged(t *testing.T) {
 	ts = NewCLI(t, filepath.Join(s.RootDir(), "no-stack"))
 	AssertRunResult(t, ts.Run("debug", "show", "generate-origins", "--changed"), RunExpected{})
 }
+
+func TestGenerateDebugJSON(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		"s:other",
+	})
+	root := s.RootEntry()
+	root.CreateFile("globals.tm", Globals(
+		Str("bucket", "state"),
+	).String())
+	root.CreateFile("stack/config.tm", GenerateHCL(
+		Labels("backend.tf"),
+		Content(
+			Block("terraform",
+				Expr("bucket", "global.bucket"),
+			),
+		),
+	).String())
+
+	ts := NewCLI(t, filepath.Join(s.RootDir(), "stack"))
+	AssertRunResult(t, ts.Run("debug", "show", "generate-origins", "--json"), RunExpected{
+		Stdout: `[
+  {
+    "file": "/stack/backend.tf",
+    "origin": {
+      "path": "/stack/config.tm",
+      "start": {
+        "line": 1,
+        "column": 1,
+        "byte": 0
+      },
+      "end": {
+        "line": 7,
+        "column": 2,
+        "byte": 96
+      }
+    },
+    "entries": [
+      {
+        "kind": "block",
+        "name": "terraform",
+        "range": {
+          "path": "/stack/config.tm",
+          "start": {
+            "line": 3,
+            "column": 5,
+            "byte": 44
+          },
+          "end": {
+            "line": 5,
+            "column": 6,
+            "byte": 90
+          }
+        },
+        "globals": [
+          {
+            "path": "global.bucket",
+            "dir": "/",
+            "range": {
+              "path": "/globals.tm",
+              "start": {
+                "line": 2,
+                "column": 3,
+                "byte": 12
+              },
+              "end": {
+                "line": 2,
+                "column": 19,
+                "byte": 28
+              }
+            }
+          }
+        ]
+      }
+    ]
+  }
+]
+`,
+	})
+
+	ts = NewCLI(t, filepath.Join(s.RootDir(), "other"))
+	AssertRunResult(t, ts.Run("debug", "show", "generate-origins", "--json"), RunExpected{
+		Stdout: "[]\n",
+	})
+}
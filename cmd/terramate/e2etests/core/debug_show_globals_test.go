@@ -258,3 +258,96 @@ stack "/stacks/stack-name":
 		})
 	}
 }
+
+func TestStacksGlobalsOrigin(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stacks/stack",
+	})
+	root := s.RootEntry()
+	root.CreateFile("globals.tm", Globals(
+		Str("env", "dev"),
+		Str("team", "platform"),
+	).String())
+	root.CreateFile("stacks/stack/globals.tm", Globals(
+		Str("env", "prd"),
+	).String())
+
+	ts := NewCLI(t, s.RootDir())
+	AssertRunResult(t, ts.Run("debug", "show", "globals", "--origin"), RunExpected{
+		Stdout: `
+stack "/stacks/stack":
+	global.env = "prd"
+		defined at /stacks/stack/globals.tm:2,3-14 (dir /stacks/stack)
+		overrides /globals.tm:2,3-15 (dir /)
+	global.team = "platform"
+		defined at /globals.tm:3,3-20 (dir /)
+`,
+	})
+}
+
+func TestStacksGlobalsJSON(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+	})
+	root := s.RootEntry()
+	root.CreateFile("globals.tm", Globals(
+		Number("replicas", 1),
+	).String())
+	root.CreateFile("stack/globals.tm", Globals(
+		Number("replicas", 3),
+	).String())
+
+	ts := NewCLI(t, s.RootDir())
+	AssertRunResult(t, ts.Run("debug", "show", "globals", "--json"), RunExpected{
+		Stdout: `[
+  {
+    "stack": "/stack",
+    "globals": [
+      {
+        "path": "global.replicas",
+        "dir": "/stack",
+        "range": {
+          "path": "/stack/globals.tm",
+          "start": {
+            "line": 2,
+            "column": 3,
+            "byte": 12
+          },
+          "end": {
+            "line": 2,
+            "column": 15,
+            "byte": 24
+          }
+        },
+        "overridden": [
+          {
+            "dir": "/",
+            "range": {
+              "path": "/globals.tm",
+              "start": {
+                "line": 2,
+                "column": 3,
+                "byte": 12
+              },
+              "end": {
+                "line": 2,
+                "column": 15,
+                "byte": 24
+              }
+            }
+          }
+        ],
+        "value": 3
+      }
+    ]
+  }
+]
+`,
+	})
+}
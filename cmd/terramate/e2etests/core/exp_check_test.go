@@ -0,0 +1,95 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+const backendAssertion = `assert {
+  assertion = !tm_contains(terramate.stack.tags, "prod") || tm_try(global.backend, null) != null
+  message   = "prod stacks must define a backend"
+}
+
+assert {
+  assertion = false
+  message   = "this is a warning"
+  warning   = true
+}
+`
+
+func TestExpCheck(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		`s:stacks/a:tags=["prod"]`,
+		`s:stacks/b:tags=["prod"]`,
+		`f:stacks/a/globals.tm:globals {
+			backend = "s3"
+		}`,
+		"f:assert.tm:" + backendAssertion,
+	})
+
+	tmcli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, tmcli.Run("experimental", "check"), RunExpected{
+		Status: 1,
+		Stdout: "/stacks/a: warning: /assert.tm:7,15-20: this is a warning\n" +
+			"/stacks/b: error: /assert.tm:2,15-97: prod stacks must define a backend\n" +
+			"/stacks/b: warning: /assert.tm:7,15-20: this is a warning\n",
+	})
+
+	AssertRunResult(t, tmcli.Run("experimental", "check", "--format", "json"), RunExpected{
+		Status:      1,
+		StdoutRegex: `"message": "prod stacks must define a backend",\s+"warning": false`,
+	})
+
+	tmcli = NewCLI(t, s.DirEntry("stacks/a").Path())
+	AssertRunResult(t, tmcli.Run("experimental", "check"), RunExpected{
+		Stdout: "/stacks/a: warning: /assert.tm:7,15-20: this is a warning\n",
+	})
+}
+
+func TestRunAssertionsSafeguard(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.New(t)
+	s.BuildTree([]string{
+		`s:stack:tags=["prod"]`,
+		"f:assert.tm:" + backendAssertion,
+	})
+	s.Git().CommitAll("all")
+
+	tmcli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, tmcli.Run("run", "--quiet", HelperPath, "echo", "ok"), RunExpected{
+		Status:      1,
+		StderrRegex: "--disable-safeguards=assertions",
+	})
+	AssertRunResult(t, tmcli.Run("run", "--quiet", "--disable-safeguards=assertions", HelperPath, "echo", "ok"),
+		RunExpected{Stdout: "ok\n"})
+	AssertRunResult(t, tmcli.Run("run", "--quiet", "--disable-safeguards=git,assertions", HelperPath, "echo", "ok"),
+		RunExpected{Stdout: "ok\n"})
+	AssertRunResult(t, tmcli.Run("run", "--quiet", "--disable-safeguards=assertions,outdated-code", HelperPath, "echo", "ok"),
+		RunExpected{Stdout: "ok\n"})
+	AssertRunResult(t, tmcli.Run("run", "--quiet", "-X", HelperPath, "echo", "ok"),
+		RunExpected{Stdout: "ok\n"})
+
+	s.RootEntry().CreateFile("safeguards.tm", `terramate {
+  config {
+    disable_safeguards = ["assertions"]
+  }
+}
+`)
+	s.Git().CommitAll("disable assertions")
+	AssertRunResult(t, tmcli.Run("run", "--quiet", HelperPath, "echo", "ok"),
+		RunExpected{Stdout: "ok\n"})
+	AssertRunResult(t, tmcli.Run("run", "--quiet", "--disable-safeguards=none", HelperPath, "echo", "ok"),
+		RunExpected{
+			Status:      1,
+			StderrRegex: "assertion failed",
+		})
+}
@@ -453,3 +453,65 @@ func TestGetConfigValue(t *testing.T) {
 }
 
 func addnl(s string) string { return s + "\n" }
+
+func TestExpEvalExplain(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		`f:globals.tm:globals {
+  name = "terramate"
+  bad  = global.undefined
+  obj  = { a = 1 }
+}`,
+	})
+
+	ts := NewCLI(t, s.RootDir())
+	AssertRunResult(t, ts.Run("experimental", "eval", "--explain",
+		`tm_try(global.missing, tm_upper(global.name))`),
+		RunExpected{
+			Stdout: `call tm_try(global.missing, tm_upper(global.name)) = "TERRAMATE"
+├── global.missing: error: <cmdline>:1,14-22: eval expression: This object does not have an attribute named "missing".
+└── call tm_upper(global.name) = "TERRAMATE"
+    └── global.name = "terramate"
+            global.name defined at /globals.tm:2,3-21 (dir /)
+`,
+		})
+
+	AssertRunResult(t, ts.Run("experimental", "eval", "--explain",
+		"tm_merge(global.obj, {\n  b = global.name\n})"),
+		RunExpected{
+			Stdout: `call tm_merge(global.obj, {
+│     b = global.name
+│   }) = {
+│     a = 1
+│     b = "terramate"
+│   }
+├── global.obj = {
+│         a = 1
+│       }
+│       global.obj.a defined at /globals.tm:4,3-19 (dir /)
+└── {
+    │     b = global.name
+    │   } = {
+    │     b = "terramate"
+    │   }
+    └── global.name = "terramate"
+            global.name defined at /globals.tm:2,3-21 (dir /)
+`,
+		})
+
+	AssertRunResult(t, ts.Run("experimental", "get-config-value", "--explain", "global.bad"),
+		RunExpected{
+			Status:      1,
+			StdoutRegex: `global.bad failed at /globals.tm:3,3-26`,
+			StderrRegex: `This object does not have an attribute named "bad"`,
+		})
+
+	AssertRunResult(t, ts.Run("experimental", "eval", "--explain", "--as-json", "global.name"),
+		RunExpected{
+			Status:      1,
+			StderrRegex: "--explain flag can't be used together with --as-json",
+		})
+}
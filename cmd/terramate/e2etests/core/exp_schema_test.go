@@ -0,0 +1,34 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/test"
+)
+
+func TestExpSchema(t *testing.T) {
+	t.Parallel()
+
+	jsonSchema, err := hcl.Schema().JSONSchema()
+	assert.NoError(t, err)
+
+	// the schema doesn't depend on a project.
+	tmcli := NewCLI(t, test.TempDir(t))
+
+	AssertRunResult(t, tmcli.Run("experimental", "schema"), RunExpected{
+		Stdout: string(jsonSchema) + "\n",
+	})
+	AssertRunResult(t, tmcli.Run("experimental", "schema", "--format", "markdown"), RunExpected{
+		Stdout: hcl.Schema().Markdown() + "\n",
+	})
+	AssertRunResult(t, tmcli.Run("experimental", "schema", "--format", "yaml"), RunExpected{
+		Status:      1,
+		StderrRegex: "must be one of",
+	})
+}
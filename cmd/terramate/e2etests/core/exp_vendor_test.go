@@ -110,6 +110,23 @@ func TestVendorModule(t *testing.T) {
 		})
 	})
 
+	t.Run("imported root configuration", func(t *testing.T) {
+		t.Parallel()
+		const importedcfg = "/from/imported/cfg"
+
+		s := sandbox.NoGit(t, true)
+		s.RootEntry().CreateFile("shared/vendor.tm.hcl", vendorHCLConfig(importedcfg))
+		s.RootEntry().CreateFile("import.tm", `
+			import {
+			  source = "/shared/vendor.tm.hcl"
+			}
+		`)
+
+		tmcli := NewCLI(t, s.RootDir())
+		res := tmcli.Run("experimental", "vendor", "download", gitSource, "main")
+		checkVendoredFiles(t, s.RootDir(), res, project.NewPath(importedcfg))
+	})
+
 	t.Run(".terramate configuration", func(t *testing.T) {
 		t.Parallel()
 		const dotTerramateCfg = "/from/dottm/cfg"
@@ -205,6 +205,7 @@ func TestFmtFiles(t *testing.T) {
 		layout   []string
 		files    []string
 		check    bool
+		flags    []string
 		stdin    string
 		absPaths bool
 		want     want
@@ -378,6 +379,102 @@ name="name"
 				},
 			},
 		},
+		{
+			name: "single file with --diff",
+			layout: []string{
+				`f:example.tm:terramate {
+    config{}
+}`,
+			},
+			files: []string{"example.tm"},
+			flags: []string{"--diff"},
+			want: want{
+				res: RunExpected{
+					Stdout: `--- a/example.tm
++++ b/example.tm
+@@ -1,3 +1,3 @@
+ terramate {
+-    config{}
++  config {}
+ }
+\ No newline at end of file
+`,
+				},
+				layout: []string{
+					`f:example.tm:terramate {
+    config{}
+}`,
+				},
+			},
+		},
+		{
+			name:  "format stdin with --diff",
+			files: []string{"-"},
+			flags: []string{"--diff"},
+			stdin: `stack {
+name="name"
+}
+`,
+			want: want{
+				res: RunExpected{
+					Stdout: `--- a/stdin
++++ b/stdin
+@@ -1,3 +1,3 @@
+ stack {
+-name="name"
++  name = "name"
+ }
+`,
+				},
+			},
+		},
+		{
+			name:  "format stdin with --sort-stack-attributes",
+			files: []string{"-"},
+			flags: []string{"--sort-stack-attributes"},
+			stdin: `stack {
+  # ordering
+  after = ["/other"]
+  tags  = ["a"]
+  id    = "id"
+  name  = "name"
+}
+`,
+			want: want{
+				res: RunExpected{
+					Stdout: `stack {
+  name = "name"
+  id   = "id"
+  tags = ["a"]
+  # ordering
+  after = ["/other"]
+}
+`,
+				},
+			},
+		},
+		{
+			name: "single file with --sort-stack-attributes",
+			layout: []string{
+				`f:stack.tm:stack {
+  description = "desc"
+  name        = "name"
+}`,
+			},
+			files: []string{"stack.tm"},
+			flags: []string{"--sort-stack-attributes"},
+			want: want{
+				res: RunExpected{
+					Stdout: nljoin("stack.tm"),
+				},
+				layout: []string{
+					`f:stack.tm:stack {
+  name        = "name"
+  description = "desc"
+}`,
+				},
+			},
+		},
 	} {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
@@ -395,6 +492,7 @@ name="name"
 			if tc.check {
 				args = append(args, "--check")
 			}
+			args = append(args, tc.flags...)
 			args = append(args, files...)
 			var result RunResult
 			if len(files) == 1 && files[0] == "-" {
@@ -0,0 +1,193 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestUserDefinedFunctions(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		`f:functions.tm:function "tm_name" {
+  params = ["env", "service"]
+  result = tm_lower("${env}-${service}")
+}
+
+function "tm_tags" {
+  params = ["team"]
+  result = { team = team, name = tm_name("PRD", team) }
+}`,
+		`f:terramate.tm:terramate {
+  config {
+    run {
+      env {
+        NAME = tm_name("DEV", terramate.stack.name)
+      }
+    }
+  }
+}`,
+		`f:stack/globals.tm:globals {
+  name = tm_name("STG", "api")
+}`,
+		`f:stack/generate.tm:generate_hcl "tags.tf" {
+  content {
+    locals {
+      tags = tm_tags("core")
+      name = global.name
+    }
+  }
+}`,
+	})
+
+	cli := NewCLI(t, filepath.Join(s.RootDir(), "stack"))
+	AssertRunResult(t, cli.Run("experimental", "eval", `tm_tags("api").name`), RunExpected{
+		Stdout: "prd-api\n",
+	})
+	AssertRunResult(t, cli.Run("experimental", "get-config-value", "global.name"), RunExpected{
+		Stdout: "stg-api\n",
+	})
+	AssertRunResult(t, cli.Run("debug", "show", "runtime-env"), RunExpected{
+		Stdout: "\nstack \"/stack\":\n\tNAME=dev-stack\n",
+	})
+	AssertRunResult(t, cli.Run("generate"), RunExpected{
+		IgnoreStdout: true,
+	})
+
+	got := string(test.ReadFile(t, filepath.Join(s.RootDir(), "stack"), "tags.tf"))
+	for _, want := range []string{
+		`name = "stg-api"`,
+		`name = "prd-core"`,
+		`team = "core"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("generated code doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestProjectQueryFunctions(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		`s:network/vpc:tags=["network"]`,
+		`s:network/dns:tags=["network"]`,
+		`s:app:tags=["app"]`,
+		`f:network/globals.tm:globals {
+  owner = "@org/network"
+}`,
+		`f:app/globals.tm:globals {
+  owner = "@org/app"
+}`,
+		`f:codeowners.tm:generate_file "/CODEOWNERS" {
+  context = root
+  content = tm_join("\n", [
+    for s in tm_stacks("") : "${s.path} ${tm_stack_globals(s.path, "owner")}"
+  ])
+}`,
+		`f:app/remote_state.tm:generate_hcl "remote_state.tf" {
+  content {
+    tm_dynamic "data" {
+      for_each = tm_stacks("network")
+      iterator = s
+      labels   = ["terraform_remote_state", s.value.name]
+      content {
+        backend = "local"
+        config = {
+          path = "${s.value.path}/terraform.tfstate"
+        }
+      }
+    }
+  }
+}`,
+	})
+
+	cli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, cli.Run("generate"), RunExpected{
+		IgnoreStdout: true,
+	})
+
+	codeowners := string(test.ReadFile(t, s.RootDir(), "CODEOWNERS"))
+	assert.EqualStrings(t, "/app @org/app\n/network/dns @org/network\n/network/vpc @org/network",
+		codeowners)
+
+	got := string(test.ReadFile(t, filepath.Join(s.RootDir(), "app"), "remote_state.tf"))
+	for _, want := range []string{
+		`data "terraform_remote_state" "dns" {`,
+		`data "terraform_remote_state" "vpc" {`,
+		`path = "/network/vpc/terraform.tfstate"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("generated code doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSensitiveValues(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		`f:globals.tm:globals {
+  password = tm_sensitive("secret")
+  user     = "admin"
+}`,
+		`f:terramate.tm:terramate {
+  config {
+    run {
+      env {
+        PASSWORD = global.password
+      }
+    }
+  }
+}`,
+	})
+
+	cli := NewCLI(t, filepath.Join(s.RootDir(), "stack"))
+	AssertRunResult(t, cli.Run("debug", "show", "globals"), RunExpected{
+		Stdout: `
+stack "/stack":
+	password = "(sensitive value)"
+	user     = "admin"
+`,
+	})
+	AssertRunResult(t, cli.Run("experimental", "eval", `"${global.user}:${global.password}"`), RunExpected{
+		Stdout: "(sensitive value)\n",
+	})
+	AssertRunResult(t, cli.Run("debug", "show", "runtime-env"), RunExpected{
+		Stdout: `
+stack "/stack":
+	PASSWORD=(sensitive value)
+`,
+	})
+	AssertRunResult(t, cli.Run("run", "--quiet", HelperPath, "env"), RunExpected{
+		StdoutRegex: "PASSWORD=secret",
+	})
+
+	s.RootEntry().CreateFile("stack/generate.tm", `generate_hcl "db.tf" {
+  content {
+    password = global.password
+  }
+}`)
+	AssertRunResult(t, cli.Run("generate"), RunExpected{
+		Status:      1,
+		StdoutRegex: "sensitive value",
+	})
+	if _, err := os.Stat(filepath.Join(s.RootDir(), "stack", "db.tf")); err == nil {
+		t.Fatal("sensitive value was generated")
+	}
+}
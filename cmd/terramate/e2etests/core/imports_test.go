@@ -75,3 +75,116 @@ stack "/":
 		})
 	})
 }
+
+func TestImportsVendored(t *testing.T) {
+	t.Parallel()
+
+	const importCfg = `import {
+		source = "git::https://github.com/org/shared.git?ref=v1.2.0"
+	}`
+
+	t.Run("vendored source is imported", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree([]string{
+			`s:stack`,
+			`f:stack/imports.tm:` + importCfg,
+			`f:modules/github.com/org/shared/v1.2.0/globals.tm:globals {
+				team = "platform"
+			}`,
+		})
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t,
+			tmcli.Run("debug", "show", "globals"),
+			RunExpected{
+				Stdout: `
+stack "/stack":
+	team = "platform"
+`,
+			},
+		)
+	})
+
+	t.Run("missing vendored copy fails", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree([]string{
+			`s:stack`,
+			`f:stack/imports.tm:` + importCfg,
+		})
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t,
+			tmcli.Run("debug", "show", "globals"),
+			RunExpected{
+				Status:      1,
+				StderrRegex: `terramate experimental vendor download git::https://github.com/org/shared.git v1.2.0`,
+			},
+		)
+	})
+}
+
+func TestImportsWithConditionAndMetadata(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		`s:stacks/app:tags=["prod"]`,
+		`s:stacks/db:tags=["dev"]`,
+		`f:config/prod/globals.tm.hcl:globals {
+  env = "prod"
+}`,
+		`f:config/dev/globals.tm.hcl:globals {
+  env = "dev"
+}`,
+		`f:config/region/globals.tm.hcl:globals {
+  region = "eu-west-1"
+}`,
+		`f:stacks/app/imports.tm:import {
+  source = "/config/${terramate.stack.tags[0]}/globals.tm.hcl"
+}
+
+import {
+  condition = tm_try(env.TM_TEST_REGION, "") != ""
+  source    = "/config/region/globals.tm.hcl"
+}`,
+		`f:stacks/db/imports.tm:import {
+  source = "/config/${terramate.stack.tags[0]}/globals.tm.hcl"
+}`,
+	})
+
+	tmcli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, tmcli.Run("debug", "show", "globals"), RunExpected{
+		Stdout: `
+stack "/stacks/app":
+	env = "prod"
+
+stack "/stacks/db":
+	env = "dev"
+`,
+	})
+
+	tmcli.AppendEnv = append(tmcli.AppendEnv, "TM_TEST_REGION=eu")
+	AssertRunResult(t, tmcli.Run("debug", "show", "globals"), RunExpected{
+		Stdout: `
+stack "/stacks/app":
+	env    = "prod"
+	region = "eu-west-1"
+
+stack "/stacks/db":
+	env = "dev"
+`,
+	})
+
+	s.RootEntry().CreateFile("config/dev/globals.tm.hcl", `import {
+  source = "/shared/common.tm.hcl"
+}`)
+	s.RootEntry().CreateFile("shared/common.tm.hcl", `import {
+  source = "/config/dev/globals.tm.hcl"
+}`)
+	AssertRunResult(t, tmcli.Run("list"), RunExpected{
+		Status:      1,
+		StderrRegex: `import cycle: (/config/dev/globals.tm.hcl -> /shared/common.tm.hcl -> /config/dev/globals.tm.hcl|/shared/common.tm.hcl -> /config/dev/globals.tm.hcl -> /shared/common.tm.hcl)`,
+	})
+}
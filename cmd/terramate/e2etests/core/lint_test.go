@@ -0,0 +1,135 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	layout := []string{
+		`f:stack/stack.tm:stack {
+			id = "stack"
+		}
+		globals {
+			unused = 1
+		}`,
+		`f:other/stack.tm:stack {}`,
+	}
+
+	t.Run("human", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("lint"), RunExpected{
+			Stdout: `/other/stack.tm:1:1: warning: stack has no id (stack-missing-id)
+/stack/stack.tm:5:4: warning: global "unused" is never referenced (unused-global)
+`,
+		})
+	})
+
+	t.Run("only files inside the working dir", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, filepath.Join(s.RootDir(), "other"))
+		AssertRunResult(t, tmcli.Run("lint"), RunExpected{
+			Stdout: `/other/stack.tm:1:1: warning: stack has no id (stack-missing-id)
+`,
+		})
+	})
+
+	t.Run("no issues", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("lint",
+			"--disable-rule", "stack-missing-id",
+			"--disable-rule", "unused-global",
+			"--format", "json",
+		), RunExpected{
+			Stdout: "[]\n",
+		})
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("lint", "--rule", "stack-missing-id", "--format", "json"), RunExpected{
+			Stdout: `[
+  {
+    "rule": "stack-missing-id",
+    "severity": "warning",
+    "message": "stack has no id",
+    "range": {
+      "path": "/other/stack.tm",
+      "start": {
+        "line": 1,
+        "column": 1,
+        "byte": 0
+      },
+      "end": {
+        "line": 1,
+        "column": 6,
+        "byte": 5
+      }
+    }
+  }
+]
+`,
+		})
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("lint", "--format", "sarif"), RunExpected{
+			StdoutRegex: `"ruleId": "unused-global"`,
+		})
+	})
+
+	t.Run("errors fail", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(append(layout, `f:ordered/stack.tm:stack {
+			id    = "ordered"
+			after = ["/missing"]
+		}`))
+
+		tmcli := NewCLI(t, filepath.Join(s.RootDir(), "ordered"))
+		AssertRunResult(t, tmcli.Run("lint"), RunExpected{
+			Status:      1,
+			StdoutRegex: `error: .* \(stack-order-missing-path\)`,
+		})
+	})
+
+	t.Run("unknown rule", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("lint", "--rule", "unknown"), RunExpected{
+			Status:      1,
+			StderrRegex: `unknown lint rule`,
+		})
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestListChangedGlobalsDataFile(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.New(t)
+	s.BuildTree([]string{
+		"s:stacks/a/stack-1",
+		"s:stacks/b/stack-2",
+		"f:data/accounts.yaml:account: \"111\"",
+	})
+	s.RootEntry().CreateFile("stacks/a/globals.tm", Globals(
+		Block("data_file",
+			Str("path", "/data/accounts.yaml"),
+		),
+	).String())
+
+	cli := NewCLI(t, s.RootDir())
+	git := s.Git()
+	git.CommitAll("all")
+	git.Push("main")
+	git.CheckoutNew("change-the-data-file")
+
+	s.RootEntry().CreateFile("data/accounts.yaml", "account: \"222\"")
+	git.CommitAll("data file changed")
+
+	AssertRunResult(t, cli.ListChangedStacks(), RunExpected{
+		Stdout: "stacks/a/stack-1\n",
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestListChangedTemplateFile(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.New(t)
+	s.BuildTree([]string{
+		"s:stacks/a/stack-1",
+		"s:stacks/b/stack-2",
+		"f:templates/file.tpl:${terramate.stack.name}",
+	})
+	s.RootEntry().CreateFile("stacks/a/generate.tm", GenerateFile(
+		Labels("file.txt"),
+		Str("template_file", "/templates/file.tpl"),
+	).String())
+
+	cli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, cli.Run("generate"), RunExpected{IgnoreStdout: true})
+
+	git := s.Git()
+	git.CommitAll("all")
+	git.Push("main")
+	git.CheckoutNew("change-the-template")
+
+	s.RootEntry().CreateFile("templates/file.tpl", "changed ${terramate.stack.name}")
+	git.CommitAll("template changed")
+
+	AssertRunResult(t, cli.ListChangedStacks(), RunExpected{
+		Stdout: "stacks/a/stack-1\n",
+	})
+}
+
+func TestListChangedTemplateFileIgnoresFilteredStacks(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.New(t)
+	s.BuildTree([]string{
+		"s:stacks/a/stack-1",
+		"s:stacks/b/stack-2",
+		"s:stacks/c/stack-3",
+		"f:templates/file.tpl:${terramate.stack.name}",
+	})
+	s.RootEntry().CreateFile("stacks/generate.tm", Doc(
+		GenerateFile(
+			Labels("file.txt"),
+			Str("template_file", "/templates/file.tpl"),
+			StackFilter(
+				ProjectPaths("/stacks/a/**"),
+			),
+		),
+	).String())
+	s.RootEntry().CreateFile("stacks/c/generate.tm", GenerateFile(
+		Labels("disabled.txt"),
+		Str("template_file", "/templates/file.tpl"),
+		Bool("condition", false),
+	).String())
+
+	cli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, cli.Run("generate"), RunExpected{IgnoreStdout: true})
+
+	git := s.Git()
+	git.CommitAll("all")
+	git.Push("main")
+	git.CheckoutNew("change-the-template")
+
+	s.RootEntry().CreateFile("templates/file.tpl", "changed ${terramate.stack.name}")
+	git.CommitAll("template changed")
+
+	AssertRunResult(t, cli.ListChangedStacks(), RunExpected{
+		Stdout: "stacks/a/stack-1\n",
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		stackConfig = `stack {}
+
+globals {
+  # deprecated metadata
+  name = terramate.name
+}
+`
+		migratedStackConfig = `stack {}
+
+globals {
+  # deprecated metadata
+  name = terramate.stack.name
+}
+`
+	)
+
+	layout := []string{
+		"f:stack/stack.tm:" + stackConfig,
+		"f:terramate.tm:" + `terramate {
+  config {
+    run {
+      check_gen_code = false
+    }
+  }
+}
+`,
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("experimental", "migrate", "--dry-run"), RunExpected{
+			Stdout: `--- a/terramate.tm
++++ b/terramate.tm
+@@ -1,7 +1,5 @@
+ terramate {
+   config {
+-    run {
+-      check_gen_code = false
+-    }
++    disable_safeguards = ["outdated-code"]
+   }
+ }
+--- a/stack/stack.tm
++++ b/stack/stack.tm
+@@ -2,5 +2,5 @@
+` + " \n" + ` globals {
+   # deprecated metadata
+-  name = terramate.name
++  name = terramate.stack.name
+ }
+`,
+		})
+
+		assert.EqualStrings(t, stackConfig,
+			string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+	})
+
+	t.Run("rewrites files", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("experimental", "migrate"), RunExpected{
+			Stdout: `/terramate.tm
+	replaced terramate.config.run.check_gen_code with terramate.config.disable_safeguards
+	set terramate.config.disable_safeguards
+/stack/stack.tm
+	replaced terramate.name with terramate.stack.name
+`,
+		})
+
+		assert.EqualStrings(t, migratedStackConfig,
+			string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+
+		AssertRun(t, tmcli.Run("experimental", "migrate", "--dry-run"))
+	})
+
+	t.Run("only files inside the working dir", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("experimental", "migrate"), RunExpected{
+			Stdout: `/stack/stack.tm
+	replaced terramate.name with terramate.stack.name
+`,
+		})
+	})
+}
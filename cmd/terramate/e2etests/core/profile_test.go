@@ -0,0 +1,79 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		`f:globals.tm:globals {
+  env      = "dev"
+  replicas = 1
+}`,
+		`f:profiles.tm:profile "prod" {
+  globals {
+    env = "prod"
+  }
+}`,
+		`f:run.tm:terramate {
+  config {
+    run {
+      env {
+        ENVIRONMENT = global.env
+        PROFILE     = terramate.profile
+      }
+    }
+  }
+}`,
+		`f:stack/generate.tm:generate_file "env.txt" {
+  content = "${global.env}:${global.replicas}"
+}`,
+	})
+
+	tmcli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, tmcli.Run("debug", "show", "globals"), RunExpected{
+		Stdout: `
+stack "/stack":
+	env      = "dev"
+	replicas = 1
+`,
+	})
+	AssertRunResult(t, tmcli.Run("--profile", "prod", "debug", "show", "globals"), RunExpected{
+		Stdout: `
+stack "/stack":
+	env      = "prod"
+	replicas = 1
+`,
+	})
+
+	AssertRunResult(t, tmcli.Run("--profile", "prod", "generate"), RunExpected{
+		IgnoreStdout: true,
+	})
+	test.AssertFileContentEquals(t, filepath.Join(s.RootDir(), "stack", "env.txt"), "prod:1")
+
+	AssertRunResult(t, tmcli.Run("--profile", "prod", "list"), RunExpected{Stdout: "stack\n"})
+	AssertRunResult(t, tmcli.Run("--profile", "prod", "run", "--quiet", HelperPath, "env"), RunExpected{
+		StdoutRegex: "ENVIRONMENT=prod\n(.|\n)*PROFILE=prod|PROFILE=prod\n(.|\n)*ENVIRONMENT=prod",
+	})
+	AssertRunResult(t, tmcli.Run("run", "--quiet", HelperPath, "env"), RunExpected{
+		Status:      1,
+		StderrRegex: "outdated",
+	})
+
+	AssertRunResult(t, tmcli.Run("--profile", "prd", "list"), RunExpected{
+		Status:      1,
+		StderrRegex: `profile "prd" is not declared \(did you mean "prod"\?\)`,
+	})
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestStackEdit(t *testing.T) {
+	t.Parallel()
+
+	const stackConfig = `# the stack
+stack {
+  name  = "stack"
+  tags  = ["old"] # the tags
+  after = ["/other"]
+}
+`
+
+	layout := []string{
+		"s:other",
+		"f:stack/stack.tm:" + stackConfig,
+	}
+
+	t.Run("set tags", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "set-tags", "prod", "app"), RunExpected{
+			Stdout: "Updated /stack/stack.tm\n",
+		})
+		assert.EqualStrings(t, `# the stack
+stack {
+  name  = "stack"
+  tags  = ["prod", "app"] # the tags
+  after = ["/other"]
+}
+`, string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+
+		tmcli = NewCLI(t, s.RootDir())
+		AssertRunResult(t, tmcli.Run("list", "--tags", "prod"), RunExpected{
+			Stdout: "stack\n",
+		})
+	})
+
+	t.Run("set no tags removes the tags", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "set-tags"), RunExpected{
+			Stdout: "Updated /stack/stack.tm\n",
+		})
+		assert.EqualStrings(t, `# the stack
+stack {
+  name  = "stack"
+  after = ["/other"]
+}
+`, string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+	})
+
+	t.Run("set invalid tags fails", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(layout)
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "set-tags", "Invalid Tag"), RunExpected{
+			Status:      1,
+			StderrRegex: "invalid tags",
+		})
+		assert.EqualStrings(t, stackConfig,
+			string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+	})
+
+	t.Run("add after", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(append(layout, "s:another"))
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "/other", "/another"), RunExpected{
+			Stdout: "Updated /stack/stack.tm\n",
+		})
+		assert.EqualStrings(t, `# the stack
+stack {
+  name  = "stack"
+  tags  = ["old"] # the tags
+  after = ["/other", "/another"]
+}
+`, string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "/other"), RunExpected{})
+	})
+
+	t.Run("add after resolves relative paths", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(append(layout, "s:another"))
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "../other", "../another/", "/another"), RunExpected{
+			Stdout: "Updated /stack/stack.tm\n",
+		})
+		assert.EqualStrings(t, `# the stack
+stack {
+  name  = "stack"
+  tags  = ["old"] # the tags
+  after = ["/other", "../another"]
+}
+`, string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+	})
+
+	t.Run("add after keeps tab indentation", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree([]string{
+			"s:other",
+			"f:stack/stack.tm:stack {\n\tname = \"stack\"\n\ttags = [\"old\"]\n}\n",
+		})
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "../other"), RunExpected{
+			Stdout: "Updated /stack/stack.tm\n",
+		})
+		assert.EqualStrings(t, "stack {\n\tname = \"stack\"\n\ttags = [\"old\"]\n\tafter = [\"../other\"]\n}\n",
+			string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+	})
+
+	t.Run("add after not a stack fails", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(append(layout, "d:dir"))
+
+		tmcli := NewCLI(t, s.DirEntry("stack").Path())
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "/dir"), RunExpected{
+			Status:      1,
+			StderrRegex: "path /dir does not contain any stack",
+		})
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "."), RunExpected{
+			Status:      1,
+			StderrRegex: "cannot run after itself",
+		})
+		assert.EqualStrings(t, stackConfig,
+			string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+	})
+
+	t.Run("outside stack fails", func(t *testing.T) {
+		t.Parallel()
+		s := sandbox.NoGit(t, true)
+		s.BuildTree(append(layout, "d:dir"))
+
+		tmcli := NewCLI(t, s.DirEntry("dir").Path())
+		AssertRunResult(t, tmcli.Run("stack", "add-after", "/other"), RunExpected{
+			Status:      1,
+			StderrRegex: "directory /dir has no stack block",
+		})
+	})
+}
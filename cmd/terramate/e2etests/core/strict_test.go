@@ -0,0 +1,46 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package core_test
+
+import (
+	"testing"
+
+	. "github.com/terramate-io/terramate/cmd/terramate/e2etests/internal/runner"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestStrictParsing(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		`f:stack/globals.tm:globals {
+			map "m" {
+				for_each = []
+				key      = element.new
+				value    = element.new
+				vaule    = 1
+			}
+		}`,
+	})
+
+	tmcli := NewCLI(t, s.RootDir())
+	AssertRunResult(t, tmcli.Run("list"), RunExpected{Stdout: "stack\n"})
+	AssertRunResult(t, tmcli.Run("--strict", "list"), RunExpected{
+		Status:      1,
+		StderrRegex: `unrecognized attribute map.vaule \(did you mean "value"\?\)`,
+	})
+
+	s.RootEntry().CreateFile("strict.tm", `terramate {
+  config {
+    strict = true
+  }
+}
+`)
+	AssertRunResult(t, tmcli.Run("list"), RunExpected{
+		Status:      1,
+		StderrRegex: `unrecognized attribute map.vaule`,
+	})
+}
@@ -127,6 +127,14 @@ func TestAssertConfigEval(t *testing.T) {
 			},
 			wantErr: errors.E(eval.ErrEval),
 		},
+		{
+			name: "sensitive message fails",
+			assert: hcl.AssertConfig{
+				Assertion: expr(`true`),
+				Message:   expr(`"token ${tm_sensitive("secret")}"`),
+			},
+			wantErr: errors.E(eval.ErrSensitive),
+		},
 		{
 			name: "warning is not boolean fails",
 			assert: hcl.AssertConfig{
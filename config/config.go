@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/slices"
 
@@ -17,6 +18,8 @@ import (
 	"github.com/terramate-io/terramate/config/filter"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/eval"
 	"github.com/terramate-io/terramate/project"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -32,6 +35,10 @@ const (
 const (
 	// ErrSchema indicates that the configuration has an invalid schema.
 	ErrSchema errors.Kind = "config has an invalid schema"
+
+	// ErrProfileNotFound indicates that the selected profile is not declared
+	// by any profile block of the project.
+	ErrProfileNotFound errors.Kind = "profile not found"
 )
 
 // Root is the root configuration tree.
@@ -40,6 +47,7 @@ const (
 type Root struct {
 	tree Tree
 
+	profile string
 	runtime project.Runtime
 }
 
@@ -59,6 +67,8 @@ type Tree struct {
 	stack *Stack
 
 	dir string
+
+	memo *sync.Map
 }
 
 // DirElem represents a node which is represented by a directory.
@@ -74,7 +84,8 @@ type List[T DirElem] []T
 // TryLoadConfig try to load the Terramate configuration tree. It looks for the
 // the config in fromdir and all parent directories until / is reached.
 // If the configuration is found, it returns the whole configuration tree,
-// configpath != "" and found as true.
+// configpath != "" and found as true. The configuration is parsed in strict
+// mode if the root configuration sets terramate.config.strict.
 func TryLoadConfig(fromdir string) (tree *Root, configpath string, found bool, err error) {
 	for {
 		ok, err := hcl.IsRootConfig(fromdir)
@@ -89,7 +100,7 @@ func TryLoadConfig(fromdir string) (tree *Root, configpath string, found bool, e
 			}
 			rootTree := NewTree(fromdir)
 			rootTree.Node = cfg
-			_, err = loadTree(rootTree, fromdir, nil)
+			_, err = loadTree(rootTree, fromdir, nil, cfg.Strict())
 			if err != nil {
 				return nil, fromdir, true, err
 			}
@@ -114,7 +125,8 @@ func NewRoot(tree *Tree) *Root {
 	return r
 }
 
-// LoadRoot loads the root configuration tree.
+// LoadRoot loads the root configuration tree. The configuration is parsed in
+// strict mode if the root configuration sets terramate.config.strict.
 func LoadRoot(rootdir string) (*Root, error) {
 	cfgtree, err := LoadTree(rootdir, rootdir)
 	if err != nil {
@@ -123,6 +135,16 @@ func LoadRoot(rootdir string) (*Root, error) {
 	return NewRoot(cfgtree), nil
 }
 
+// LoadRootStrict is like LoadRoot but always parses the configuration in
+// strict mode.
+func LoadRootStrict(rootdir string) (*Root, error) {
+	cfgtree, err := loadRootTree(rootdir, rootdir, true)
+	if err != nil {
+		return nil, err
+	}
+	return NewRoot(cfgtree), nil
+}
+
 // Tree returns the root configuration tree.
 func (root *Root) Tree() *Tree { return &root.tree }
 
@@ -221,9 +243,16 @@ func (root *Root) LoadSubTree(cfgdir project.Path) error {
 		return errors.E(err, "failed to load config from %s", subtreeDir)
 	}
 
+	if root.profile != "" {
+		node.applyProfile(root.profile)
+	}
+
 	if node.HostDir() == rootdir {
 		// root configuration reloaded
+		profile := root.profile
 		*root = *NewRoot(node)
+		root.profile = profile
+		root.initRuntime()
 	} else {
 		node.Parent = parentNode
 		parentNode.Children[nextComponent] = node
@@ -231,6 +260,45 @@ func (root *Root) LoadSubTree(cfgdir project.Path) error {
 	return nil
 }
 
+// Profile returns the name of the active profile or an empty string if no
+// profile is active.
+func (root *Root) Profile() string { return root.profile }
+
+// SetProfile activates the given profile. The globals of the profile blocks
+// of each directory overlay the globals of the same directory and the profile
+// name is exposed as terramate.profile. It must be called before any
+// evaluation of the configuration and the profile can't be changed once set.
+// An empty name is a no-op.
+func (root *Root) SetProfile(name string) error {
+	if name == "" || name == root.profile {
+		return nil
+	}
+	if root.profile != "" {
+		return errors.E("profile %q is already active", root.profile)
+	}
+
+	declared := map[string]struct{}{}
+	for _, node := range root.tree.AsList() {
+		for _, profile := range node.Node.Profiles.Names() {
+			declared[profile] = struct{}{}
+		}
+	}
+	if _, ok := declared[name]; !ok {
+		names := make([]string, 0, len(declared))
+		for profile := range declared {
+			names = append(names, profile)
+		}
+		sort.Strings(names)
+		return errors.E(ErrProfileNotFound, "profile %q is not declared%s",
+			name, ast.DidYouMean(name, names))
+	}
+
+	root.tree.applyProfile(name)
+	root.profile = name
+	root.initRuntime()
+	return nil
+}
+
 // Stacks return the stacks paths.
 func (root *Root) Stacks() project.Paths {
 	return root.tree.Stacks().Paths()
@@ -246,6 +314,15 @@ func (root *Root) Runtime() project.Runtime {
 	return runtime
 }
 
+// SetFunctions sets the user defined functions of the project and the
+// tm_stacks function on the evaluation context.
+func (root *Root) SetFunctions(ctx *eval.Context) {
+	ctx.SetFunction(StacksFuncName, root.StacksFunc())
+	for _, fn := range root.tree.Node.Functions {
+		ctx.SetUserFunction(fn.Name, fn.Params, fn.Result)
+	}
+}
+
 func (root *Root) initRuntime() {
 	rootfs := cty.ObjectVal(map[string]cty.Value{
 		"absolute": cty.StringVal(root.HostDir()),
@@ -264,19 +341,24 @@ func (root *Root) initRuntime() {
 		"root":    rootNS,
 		"stacks":  stacksNs,
 		"version": cty.StringVal(terramate.Version()),
+		"profile": cty.StringVal(root.profile),
 	}
 }
 
 // LoadTree loads the whole hierarchical configuration from cfgdir downwards
 // using rootdir as project root.
 func LoadTree(rootdir string, cfgdir string) (*Tree, error) {
-	cfg, err := hcl.ParseDir(rootdir, rootdir)
+	return loadRootTree(rootdir, cfgdir, false)
+}
+
+func loadRootTree(rootdir string, cfgdir string, strict bool) (*Tree, error) {
+	cfg, err := parseDir(rootdir, rootdir, strict)
 	if err != nil {
 		return nil, err
 	}
 	root := NewTree(rootdir)
 	root.Node = cfg
-	return loadTree(root, cfgdir, nil)
+	return loadTree(root, cfgdir, nil, strict || cfg.Strict())
 }
 
 // HostDir is the node absolute directory in the host.
@@ -305,6 +387,13 @@ func (tree *Tree) Root() *Root {
 	return NewRoot(tree)
 }
 
+// Memo returns the memoization table of the node, used to cache values
+// computed from the configuration of the directory. The values live as long
+// as the configuration tree, so they must not depend on anything else.
+func (tree *Tree) Memo() *sync.Map {
+	return tree.memo
+}
+
 // IsStack tells if the node is a stack.
 func (tree *Tree) IsStack() bool {
 	return tree.Node.Stack != nil
@@ -365,6 +454,12 @@ func (tree *Tree) lookup(abspath project.Path) (*Tree, bool) {
 	return cfg, true
 }
 
+func (tree *Tree) applyProfile(name string) {
+	for _, node := range tree.AsList() {
+		node.Node.ApplyProfile(name)
+	}
+}
+
 // AsList returns a list with this node and all its children.
 func (tree *Tree) AsList() List[*Tree] {
 	result := List[*Tree]{
@@ -381,7 +476,7 @@ func (l List[T]) Len() int           { return len(l) }
 func (l List[T]) Less(i, j int) bool { return l[i].Dir().String() < l[j].Dir().String() }
 func (l List[T]) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
 
-func loadTree(parentTree *Tree, cfgdir string, rootcfg *hcl.Config) (_ *Tree, err error) {
+func loadTree(parentTree *Tree, cfgdir string, rootcfg *hcl.Config, strict bool) (_ *Tree, err error) {
 	logger := log.With().
 		Str("action", "config.loadTree()").
 		Str("dir", cfgdir).
@@ -416,7 +511,7 @@ func loadTree(parentTree *Tree, cfgdir string, rootcfg *hcl.Config) (_ *Tree, er
 	if cfgdir != parentTree.RootDir() {
 		tree := NewTree(cfgdir)
 
-		cfg, err := hcl.ParseDir(parentTree.RootDir(), cfgdir, rootcfg.Experiments()...)
+		cfg, err := parseDir(parentTree.RootDir(), cfgdir, strict, rootcfg.Experiments()...)
 		if err != nil {
 			return nil, err
 		}
@@ -433,7 +528,7 @@ func loadTree(parentTree *Tree, cfgdir string, rootcfg *hcl.Config) (_ *Tree, er
 		}
 
 		dir := filepath.Join(cfgdir, fname)
-		node, err := loadTree(parentTree, dir, rootcfg)
+		node, err := loadTree(parentTree, dir, rootcfg, strict)
 		if err != nil {
 			return nil, errors.E(err, "loading from %s", dir)
 		}
@@ -464,11 +559,19 @@ func IsStack(root *Root, dir string) bool {
 	return ok && node.IsStack()
 }
 
+func parseDir(rootdir string, dir string, strict bool, experiments ...string) (hcl.Config, error) {
+	if strict {
+		return hcl.ParseDirStrict(rootdir, dir, experiments...)
+	}
+	return hcl.ParseDir(rootdir, dir, experiments...)
+}
+
 // NewTree creates a new tree node.
 func NewTree(cfgdir string) *Tree {
 	return &Tree{
 		dir:      cfgdir,
 		Children: make(map[string]*Tree),
+		memo:     &sync.Map{},
 	}
 }
 
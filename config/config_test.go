@@ -6,13 +6,16 @@ package config_test
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/madlambda/spells/assert"
 	"github.com/rs/zerolog"
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
 	"github.com/terramate-io/terramate/project"
+	errtest "github.com/terramate-io/terramate/test/errors"
 	"github.com/terramate-io/terramate/test/sandbox"
 )
 
@@ -330,6 +333,75 @@ func TestConfigSkipdir(t *testing.T) {
 	assert.IsTrue(t, !found)
 }
 
+func TestConfigStrict(t *testing.T) {
+	t.Parallel()
+
+	layout := []string{
+		"s:/stack",
+		`f:/stack/globals.tm:globals {
+			map "m" {
+				for_each = []
+				key      = element.new
+				value    = element.new
+				values   = 1
+			}
+		}`,
+	}
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree(layout)
+
+	_, err := config.LoadRoot(s.RootDir())
+	assert.NoError(t, err)
+
+	_, err = config.LoadRootStrict(s.RootDir())
+	errtest.Assert(t, err, errors.E(hcl.ErrTerramateSchema))
+
+	s = sandbox.NoGit(t, true)
+	s.BuildTree(append(layout, `f:/strict.tm:terramate {
+		config {
+			strict = true
+		}
+	}`))
+
+	_, err = config.LoadRoot(s.RootDir())
+	errtest.Assert(t, err, errors.E(hcl.ErrTerramateSchema))
+
+	_, _, _, err = config.TryLoadConfig(s.DirEntry("stack").Path())
+	errtest.Assert(t, err, errors.E(hcl.ErrTerramateSchema))
+}
+
+func TestConfigProfile(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:/stack",
+		`f:/profiles.tm:profile "prod" {}`,
+		`f:/stack/profiles.tm:profile "staging" {}`,
+	})
+
+	root, err := config.LoadRoot(s.RootDir())
+	assert.NoError(t, err)
+	assert.EqualStrings(t, "", root.Profile())
+	assert.EqualStrings(t, "", root.Runtime()["profile"].AsString())
+
+	err = root.SetProfile("prd")
+	errtest.Assert(t, err, errors.E(config.ErrProfileNotFound))
+	assert.IsTrue(t, strings.Contains(err.Error(), `(did you mean "prod"?)`), err.Error())
+
+	assert.NoError(t, root.SetProfile("staging"))
+	assert.EqualStrings(t, "staging", root.Profile())
+	assert.EqualStrings(t, "staging", root.Runtime()["profile"].AsString())
+
+	assert.NoError(t, root.SetProfile("staging"))
+	assert.Error(t, root.SetProfile("prod"))
+
+	assert.NoError(t, root.LoadSubTree(project.NewPath("/")))
+	assert.EqualStrings(t, "staging", root.Profile())
+	assert.EqualStrings(t, "staging", root.Runtime()["profile"].AsString())
+}
+
 func isStack(root *config.Root, dir string) bool {
 	return config.IsStack(root, filepath.Join(root.HostDir(), dir))
 }
@@ -21,6 +21,7 @@ func evalBool(evalctx *eval.Context, expr hhcl.Expression, name string) (bool, e
 	if val.Type() != cty.Bool {
 		return false, errors.E(ErrSchema, "%s must be boolean, got %v", name, val.Type().FriendlyName())
 	}
+	val, _ = val.Unmark()
 	return val.True(), nil
 }
 
@@ -35,5 +36,10 @@ func evalString(evalctx *eval.Context, expr hhcl.Expression, name string) (strin
 	if val.Type() != cty.String {
 		return "", errors.E(ErrSchema, "%s must be string, got %v", name, val.Type().FriendlyName())
 	}
+	if eval.IsSensitive(val) {
+		return "", errors.E(eval.ErrSensitive, expr.Range(),
+			"%s must not be a sensitive value", name)
+	}
+	val, _ = val.UnmarkDeep()
 	return val.AsString(), nil
 }
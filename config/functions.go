@@ -0,0 +1,79 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"github.com/terramate-io/terramate/config/filter"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// StacksFuncName is the name of the function which lists the stacks of the project.
+const StacksFuncName = "tm_stacks"
+
+// stackObjectType is the type of each stack returned by tm_stacks.
+var stackObjectType = cty.Object(map[string]cty.Type{
+	"path":        cty.String,
+	"name":        cty.String,
+	"id":          cty.String,
+	"description": cty.String,
+	"tags":        cty.List(cty.String),
+})
+
+// StacksFunc returns the tm_stacks function, which returns the path, name, id,
+// description and tags of the stacks of the project matching a tag filter.
+// The filter has the same syntax as the --tags flag and an empty filter
+// matches all stacks. The stacks are sorted by path and stacks without an
+// id have a null id.
+func (root *Root) StacksFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "filter",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.List(stackObjectType)),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			var (
+				clauses   filter.TagClause
+				hasFilter bool
+			)
+			if expr := args[0].AsString(); expr != "" {
+				var err error
+				clauses, hasFilter, err = filter.ParseTagClauses(expr)
+				if err != nil {
+					return cty.NilVal, function.NewArgError(0, err)
+				}
+			}
+
+			var stacks []cty.Value
+			for _, tree := range root.tree.Stacks() {
+				if hasFilter && !filter.MatchTags(clauses, tree.Node.Stack.Tags) {
+					continue
+				}
+				st, err := tree.Stack()
+				if err != nil {
+					return cty.NilVal, errors.E(err, "loading stack %s", tree.Dir())
+				}
+				id := cty.NullVal(cty.String)
+				if st.ID != "" {
+					id = cty.StringVal(st.ID)
+				}
+				stacks = append(stacks, cty.ObjectVal(map[string]cty.Value{
+					"path":        cty.StringVal(st.Dir.String()),
+					"name":        cty.StringVal(st.Name),
+					"id":          id,
+					"description": cty.StringVal(st.Description),
+					"tags":        toCtyStringList(st.Tags),
+				}))
+			}
+			if len(stacks) == 0 {
+				return cty.ListValEmpty(stackObjectType), nil
+			}
+			return cty.ListVal(stacks), nil
+		},
+	})
+}
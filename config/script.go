@@ -87,6 +87,8 @@ func EvalScript(evalctx *eval.Context, script hcl.Script) (Script, error) {
 				continue
 			}
 
+			// commands need the actual values of sensitive arguments.
+			v, _ = v.UnmarkDeep()
 			command, err := unmarshalScriptJobCommand(v, expr)
 			if err != nil {
 				errs.Append(err)
@@ -103,6 +105,7 @@ func EvalScript(evalctx *eval.Context, script hcl.Script) (Script, error) {
 				continue
 			}
 
+			v, _ = v.UnmarkDeep()
 			commands, err := unmarshalScriptJobCommands(v, expr)
 			if err != nil {
 				errs.Append(err)
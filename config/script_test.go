@@ -67,6 +67,15 @@ func TestScriptEval(t *testing.T) {
 			},
 			wantErr: errors.E(config.ErrScriptInvalidTypeDesc),
 		},
+		{
+			name: "description attribute with sensitive value",
+			script: hcl.Script{
+				Labels: labels,
+				Description: hcl.NewScriptDescription(
+					makeAttribute(t, "description", `tm_sensitive("secret")`)),
+			},
+			wantErr: errors.E(config.ErrScriptInvalidTypeDesc),
+		},
 		{
 			name: "description attribute with functions",
 			script: hcl.Script{
@@ -218,22 +218,12 @@ func (s *Stack) HostDir(root *Root) string {
 
 // RuntimeValues returns the runtime "terramate" namespace for the stack.
 func (s *Stack) RuntimeValues(root *Root) map[string]cty.Value {
-	stackpath := cty.ObjectVal(map[string]cty.Value{
-		"absolute": cty.StringVal(s.Dir.String()),
-		"relative": cty.StringVal(s.RelPath()),
-		"basename": cty.StringVal(s.PathBase()),
-		"to_root":  cty.StringVal(s.RelPathToRoot(root)),
+	stack := hcl.StackMetadata(root.HostDir(), s.Dir, &hcl.Stack{
+		ID:          s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		Tags:        s.Tags,
 	})
-	stackMapVals := map[string]cty.Value{
-		"name":        cty.StringVal(s.Name),
-		"description": cty.StringVal(s.Description),
-		"tags":        toCtyStringList(s.Tags),
-		"path":        stackpath,
-	}
-	if s.ID != "" {
-		stackMapVals["id"] = cty.StringVal(s.ID)
-	}
-	stack := cty.ObjectVal(stackMapVals)
 	return map[string]cty.Value{
 		"name":        cty.StringVal(s.Name),         // DEPRECATED
 		"path":        cty.StringVal(s.Dir.String()), // DEPRECATED
@@ -203,6 +203,10 @@ export default defineConfig({
                   text: 'Overview',
                   link: '/cli/code-generation/functions/',
                 },
+                {
+                  text: 'User Defined Functions',
+                  link: '/cli/code-generation/functions/user-defined',
+                },
                 {
                   text: 'Terramate',
                   collapsed: true,
@@ -219,6 +223,54 @@ export default defineConfig({
                       text: 'tm_version_match',
                       link: '/cli/code-generation/functions/tm_version_match',
                     },
+                    {
+                      text: 'tm_semver_compare',
+                      link: '/cli/code-generation/functions/tm_semver_compare',
+                    },
+                    {
+                      text: 'tm_hcl_decode',
+                      link: '/cli/code-generation/functions/tm_hcl_decode',
+                    },
+                    {
+                      text: 'tm_hcl_encode',
+                      link: '/cli/code-generation/functions/tm_hcl_encode',
+                    },
+                    {
+                      text: 'tm_cidrsubnets_nonoverlapping',
+                      link: '/cli/code-generation/functions/tm_cidrsubnets_nonoverlapping',
+                    },
+                    {
+                      text: 'tm_slugify',
+                      link: '/cli/code-generation/functions/tm_slugify',
+                    },
+                    {
+                      text: 'tm_deep_merge',
+                      link: '/cli/code-generation/functions/tm_deep_merge',
+                    },
+                    {
+                      text: 'tm_sensitive',
+                      link: '/cli/code-generation/functions/tm_sensitive',
+                    },
+                    {
+                      text: 'tm_nonsensitive',
+                      link: '/cli/code-generation/functions/tm_nonsensitive',
+                    },
+                    {
+                      text: 'tm_stacks',
+                      link: '/cli/code-generation/functions/tm_stacks',
+                    },
+                    {
+                      text: 'tm_stack_globals',
+                      link: '/cli/code-generation/functions/tm_stack_globals',
+                    },
+                    {
+                      text: 'tm_terraform_required_providers',
+                      link: '/cli/code-generation/functions/tm_terraform_required_providers',
+                    },
+                    {
+                      text: 'tm_terraform_lock_providers',
+                      link: '/cli/code-generation/functions/tm_terraform_lock_providers',
+                    },
                   ],
                 },
                 {
@@ -756,6 +808,8 @@ export default defineConfig({
               items: [
                 { text: 'create', link: '/cli/cmdline/create' },
                 { text: 'clone', link: '/cli/cmdline/clone' },
+                { text: 'stack set-tags', link: '/cli/cmdline/stack/set-tags' },
+                { text: 'stack add-after', link: '/cli/cmdline/stack/add-after' },
                 { text: 'list', link: '/cli/cmdline/list' },
                 { text: 'metadata', link: '/cli/cmdline/metadata' },
                 { text: 'get-config-value', link: '/cli/cmdline/get-config-value' },
@@ -786,6 +840,7 @@ export default defineConfig({
                 { text: 'fmt', link: '/cli/cmdline/fmt' },
                 { text: 'generate', link: '/cli/cmdline/generate' },
                 { text: 'globals', link: '/cli/cmdline/globals' },
+                { text: 'lint', link: '/cli/cmdline/lint' },
               ],
             },
             {
@@ -794,6 +849,9 @@ export default defineConfig({
                 { text: 'eval', link: '/cli/cmdline/eval' },
                 { text: 'partial-eval', link: '/cli/cmdline/partial-eval' },
                 { text: 'vendor download', link: '/cli/cmdline/vendor-download' },
+                { text: 'migrate', link: '/cli/cmdline/migrate' },
+                { text: 'schema', link: '/cli/cmdline/schema' },
+                { text: 'check', link: '/cli/cmdline/check' },
                 { text: 'install-completions', link: '/cli/cmdline/install-completions' },
                 { text: 'version', link: '/cli/cmdline/version' },
               ],
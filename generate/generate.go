@@ -36,6 +36,10 @@ const (
 	// was not previously generated by Terramate.
 	ErrManualCodeExists errors.Kind = "manually defined code found"
 
+	// ErrManualCodeEdited indicates code generation would replace generated
+	// code that was manually edited after being generated.
+	ErrManualCodeEdited errors.Kind = "manually edited generated code found"
+
 	// ErrConflictingConfig indicates that two code generation configurations
 	// are conflicting, like both generates a file with the same name
 	// and would overwrite each other.
@@ -48,6 +52,10 @@ const (
 	// ErrAssertion indicates that code generation configuration
 	// has a failed assertion.
 	ErrAssertion errors.Kind = "assertion failed"
+
+	// ErrInvalidSymlinkTarget indicates that a generate_symlink block
+	// has a target that is not a relative path inside the project.
+	ErrInvalidSymlinkTarget errors.Kind = "invalid generate_symlink target"
 )
 
 // GenFile represents a generated file loaded from a Terramate configuration.
@@ -56,6 +64,12 @@ type GenFile interface {
 	Header() string
 	// Body is the body of the generated file, if any.
 	Body() string
+	// Mode is the permission bits of the generated file. Zero means the
+	// file is generated with the default permissions.
+	Mode() fs.FileMode
+	// LinkTarget is the target of the generated symlink, or an empty string
+	// if the generated file is not a symlink.
+	LinkTarget() string
 	// Label is the label of the origin generate block that generated this file.
 	Label() string
 	// Context is the context of the generate block.
@@ -121,6 +135,7 @@ func Load(root *config.Root, vendorDir project.Path) ([]LoadResult, error) {
 		}
 		res := LoadResult{Dir: dircfg.Dir()}
 		evalctx := eval.NewContext(stdlib.Functions(dircfg.HostDir()))
+		globals.SetFunctions(root, evalctx)
 
 		var generated []GenFile
 		for _, block := range dircfg.Node.Generate.Files {
@@ -145,6 +160,12 @@ func Load(root *config.Root, vendorDir project.Path) ([]LoadResult, error) {
 	return results, nil
 }
 
+// Options are the options of the code generation.
+type Options struct {
+	// Force enables overwriting generated files that were manually edited.
+	Force bool
+}
+
 // Do will generate code for the entire configuration.
 //
 // There generation mechanism depend on the generate_* block context attribute:
@@ -177,9 +198,31 @@ func Do(
 	vendorDir project.Path,
 	vendorRequests chan<- event.VendorRequest,
 ) Report {
-	stackReport := forEachStack(root, vendorDir,
-		vendorRequests, doStackGeneration)
-	rootReport := doRootGeneration(root)
+	return DoWithOptions(root, vendorDir, vendorRequests, Options{})
+}
+
+// DoWithOptions works like [Do] but using the given options.
+//
+// Generated files that were manually edited after being generated are never
+// overwritten, unless [Options.Force] is set. Each of them is reported as a
+// failure with [ErrManualCodeEdited] naming the edited file.
+func DoWithOptions(
+	root *config.Root,
+	vendorDir project.Path,
+	vendorRequests chan<- event.VendorRequest,
+	opts Options,
+) Report {
+	stackReport := forEachStack(root, vendorDir, vendorRequests,
+		func(
+			root *config.Root,
+			stack *config.Stack,
+			globals *eval.Object,
+			vendorDir project.Path,
+			vendorRequests chan<- event.VendorRequest,
+		) dirReport {
+			return doStackGeneration(root, stack, globals, vendorDir, vendorRequests, opts)
+		})
+	rootReport := doRootGeneration(root, opts)
 	report := mergeReports(stackReport, rootReport)
 	return cleanupOrphaned(root, report)
 }
@@ -190,6 +233,7 @@ func doStackGeneration(
 	globals *eval.Object,
 	vendorDir project.Path,
 	vendorRequests chan<- event.VendorRequest,
+	opts Options,
 ) dirReport {
 	stackpath := stack.HostDir(root)
 	logger := log.With().
@@ -243,13 +287,12 @@ func doStackGeneration(
 			continue
 		}
 
-		body := file.Header() + file.Body()
-
 		// Change detection + remove entries that got re-generated
-		oldFileBody, oldExists := allFiles[filename]
+		oldFile, oldExists := allFiles[filename]
+		changed := oldExists && !oldFile.matches(file)
 
-		if !oldExists || oldFileBody != body {
-			err := writeGeneratedCode(root, path, file)
+		if !oldExists || changed {
+			err := writeGeneratedCode(root, path, file, opts.Force)
 			if err != nil {
 				report.err = errors.E(err, "saving file %q", filename)
 				return report
@@ -265,7 +308,7 @@ func doStackGeneration(
 			report.addCreatedFile(filename)
 		} else {
 			delete(allFiles, filename)
-			if body != oldFileBody {
+			if changed {
 				log.Info().
 					Stringer("stack", stack.Dir).
 					Str("file", filename).
@@ -298,13 +341,14 @@ func doStackGeneration(
 	return report
 }
 
-func doRootGeneration(root *config.Root) Report {
+func doRootGeneration(root *config.Root, opts Options) Report {
 	logger := log.With().
 		Str("action", "generate.doRootGeneration").
 		Logger()
 
 	report := Report{}
 	evalctx := eval.NewContext(stdlib.Functions(root.HostDir()))
+	globals.SetFunctions(root, evalctx)
 	evalctx.SetNamespace("terramate", root.Runtime())
 
 	var files []GenFile
@@ -372,7 +416,7 @@ func doRootGeneration(root *config.Root) Report {
 
 	logger.Debug().Msg("no conflicts found")
 
-	generateRootFiles(root, files, &report)
+	generateRootFiles(root, files, &report, opts)
 	return report
 }
 
@@ -488,30 +532,63 @@ processSubdirs:
 
 // DetectOutdated will verify if the given config has outdated code
 // and return a list of filenames that are outdated, ordered lexicographically.
+// It fails if any assertion of the stacks fails.
 func DetectOutdated(root *config.Root, vendorDir project.Path) ([]string, error) {
+	outdatedFiles, asserts, err := DetectOutdatedWithAsserts(root, vendorDir)
+	if err != nil {
+		return nil, err
+	}
+
+	stackdirs := make([]project.Path, 0, len(asserts))
+	for stackdir := range asserts {
+		stackdirs = append(stackdirs, stackdir)
+	}
+	sort.Slice(stackdirs, func(i, j int) bool {
+		return stackdirs[i].String() < stackdirs[j].String()
+	})
+
+	errs := errors.L()
+	for _, stackdir := range stackdirs {
+		errs.Append(handleAsserts(root.HostDir(),
+			project.AbsPath(root.HostDir(), stackdir.String()), asserts[stackdir]))
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return outdatedFiles, nil
+}
+
+// DetectOutdatedWithAsserts is like [DetectOutdated] but the failed assertions
+// of the assert blocks don't make it fail. Instead, the evaluated assertions
+// are returned keyed by the stack directory, so they can be checked without
+// evaluating the globals of the stacks again. The assertions of the generate
+// blocks are part of the code generation and still make it fail.
+func DetectOutdatedWithAsserts(root *config.Root, vendorDir project.Path) ([]string, map[project.Path][]config.Assert, error) {
 	logger := log.With().
-		Str("action", "generate.DetectOutdated()").
+		Str("action", "generate.DetectOutdatedWithAsserts()").
 		Logger()
 
 	stacks, err := config.LoadAllStacks(root.Tree())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	outdatedFiles := []string{}
+	asserts := map[project.Path][]config.Assert{}
 	errs := errors.L()
 
 	logger.Debug().Msg("checking outdated code inside stacks")
 
-	for _, stack := range stacks {
-		outdated, err := stackOutdated(root, stack.Stack, vendorDir)
+	for _, elem := range stacks {
+		outdated, stackAsserts, err := stackOutdated(root, elem.Stack, vendorDir)
 		if err != nil {
 			errs.Append(err)
 			continue
 		}
+		asserts[elem.Dir()] = stackAsserts
 
 		// We want results relative to root
-		stackRelPath := stack.Dir().String()[1:]
+		stackRelPath := elem.Dir().String()[1:]
 		for _, file := range outdated {
 			outdatedFiles = append(outdatedFiles,
 				path.Join(stackRelPath, file))
@@ -525,7 +602,7 @@ func DetectOutdated(root *config.Root, vendorDir project.Path) ([]string, error)
 		logger.Debug().Msg("project root is stack, no need to check for orphaned files")
 
 		sort.Strings(outdatedFiles)
-		return outdatedFiles, nil
+		return outdatedFiles, asserts, nil
 	}
 
 	logger.Debug().Msg("checking for orphaned files")
@@ -536,22 +613,23 @@ func DetectOutdated(root *config.Root, vendorDir project.Path) ([]string, error)
 	}
 
 	if err := errs.AsError(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	outdatedFiles = append(outdatedFiles, orphanedFiles...)
 	sort.Strings(outdatedFiles)
-	return outdatedFiles, nil
+	return outdatedFiles, asserts, nil
 }
 
 // stackOutdated will verify if a given stack has outdated code and return a list
-// of filenames that are outdated, ordered lexicographically.
+// of filenames that are outdated, ordered lexicographically, and the evaluated
+// assertions of the stack.
 // If the stack has an invalid configuration it will return an error.
 func stackOutdated(
 	root *config.Root,
 	st *config.Stack,
 	vendorDir project.Path,
-) ([]string, error) {
+) ([]string, []config.Assert, error) {
 	logger := log.With().
 		Str("action", "generate.stackOutdated").
 		Stringer("stack", st).
@@ -559,24 +637,38 @@ func stackOutdated(
 
 	report := globals.ForStack(root, st)
 	if err := report.AsError(); err != nil {
-		return nil, errors.E(err, "checking for outdated code")
+		return nil, nil, errors.E(err, "checking for outdated code")
 	}
 
 	globals := report.Globals
-	generated, err := loadStackCodeCfgs(root, st, globals, vendorDir, nil)
+	asserts, err := stack.LoadAsserts(root, st, globals)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	generated, err := loadStackGenFiles(root, st, globals, vendorDir, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	stackpath := st.HostDir(root)
+	var genAsserts []config.Assert
+	for _, gen := range generated {
+		genAsserts = append(genAsserts, gen.Asserts()...)
+	}
+	err = handleAsserts(root.HostDir(), stackpath, genAsserts)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	err = validateStackGeneratedFiles(root, stackpath, generated)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	genfilesOnFs, err := ListGenFiles(root, stackpath)
 	if err != nil {
-		return nil, errors.E(err, "checking for outdated code")
+		return nil, nil, errors.E(err, "checking for outdated code")
 	}
 
 	logger.Debug().Msgf("generated files detected on fs: %v", genfilesOnFs)
@@ -586,12 +678,12 @@ func stackOutdated(
 	outdatedFiles := newStringSet(genfilesOnFs...)
 	err = updateOutdatedFiles(stackpath, generated, outdatedFiles)
 	if err != nil {
-		return nil, errors.E(err, "checking for outdated files")
+		return nil, nil, errors.E(err, "checking for outdated files")
 	}
 
 	outdated := outdatedFiles.slice()
 	sort.Strings(outdated)
-	return outdated, nil
+	return outdated, asserts, nil
 }
 
 func updateOutdatedFiles(
@@ -615,7 +707,7 @@ func updateOutdatedFiles(
 		filename := genfile.Label()
 		targetpath := filepath.Join(stackpath, filename)
 
-		currentCode, codeFound, err := readFile(targetpath)
+		currentFile, codeFound, err := readFileState(targetpath)
 		if err != nil {
 			return err
 		}
@@ -651,8 +743,7 @@ func updateOutdatedFiles(
 			continue
 		}
 
-		generatedCode := genfile.Header() + genfile.Body()
-		if generatedCode != currentCode {
+		if !currentFile.matches(genfile) {
 			logger.Debug().Msg("outdated: code on fs differs from generated from config")
 
 			outdatedFiles.add(filename)
@@ -666,14 +757,14 @@ func updateOutdatedFiles(
 	return nil
 }
 
-func writeGeneratedCode(root *config.Root, target string, genfile GenFile) error {
+func writeGeneratedCode(root *config.Root, target string, genfile GenFile, force bool) error {
 	body := genfile.Header() + genfile.Body()
 
 	if genfile.Header() != "" {
 		// WHY: some file generation strategies don't provide
 		// headers, like generate_file, so we can't detect
 		// if we are overwriting a Terramate generated file.
-		if err := checkFileCanBeOverwritten(root, target); err != nil {
+		if err := checkFileCanBeOverwritten(root, target, force); err != nil {
 			return err
 		}
 	}
@@ -682,12 +773,68 @@ func writeGeneratedCode(root *config.Root, target string, genfile GenFile) error
 		return err
 	}
 
-	return os.WriteFile(target, []byte(body), 0666)
+	linkTarget := genfile.LinkTarget()
+
+	// WHY: symlinks can't be overwritten and writing a regular file over a
+	// symlink would change the symlink target instead.
+	if st, err := os.Lstat(target); err == nil && (linkTarget != "" || st.Mode()&fs.ModeSymlink != 0) {
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+
+	if linkTarget != "" {
+		return os.Symlink(filepath.FromSlash(linkTarget), target)
+	}
+
+	mode := genfile.Mode()
+	if mode == 0 {
+		return os.WriteFile(target, []byte(body), 0666)
+	}
+	if err := os.WriteFile(target, []byte(body), mode); err != nil {
+		return err
+	}
+	// WHY: the mode given to os.WriteFile is only used when creating the
+	// file and is subject to the umask.
+	return os.Chmod(target, mode)
+}
+
+func checkFileCanBeOverwritten(root *config.Root, path string, force bool) error {
+	data, found, err := readGeneratedFile(root, path)
+	if err != nil || !found || force {
+		return err
+	}
+	if isManuallyEdited(root, data) {
+		return errors.E(ErrManualCodeEdited,
+			"file %s was manually edited, use --force to overwrite it",
+			project.PrjAbsPath(root.HostDir(), path))
+	}
+	return nil
 }
 
-func checkFileCanBeOverwritten(root *config.Root, path string) error {
-	_, _, err := readGeneratedFile(root, path)
-	return err
+// isManuallyEdited tells if the given generated code was changed after being
+// generated, by comparing the checksum on its header with the actual checksum
+// of its body. Code generated without a checksum is never considered edited.
+func isManuallyEdited(root *config.Root, code string) bool {
+	checksum, body, ok := genhcl.ParseChecksum(genhcl.CommentStyleFromConfig(root.Tree()), code)
+	return ok && checksum != genhcl.Checksum(body)
+}
+
+// DetectManualEdits returns the generated files, from the given list of
+// files, that were manually edited after being generated. The files must be
+// relative to the project root, like the ones returned by [DetectOutdated].
+func DetectManualEdits(root *config.Root, files []string) ([]string, error) {
+	edited := []string{}
+	for _, file := range files {
+		code, found, err := readFile(filepath.Join(root.HostDir(), filepath.FromSlash(file)))
+		if err != nil {
+			return nil, errors.E(err, "checking if file %s was manually edited", file)
+		}
+		if found && isManuallyEdited(root, code) {
+			edited = append(edited, file)
+		}
+	}
+	return edited, nil
 }
 
 // readGeneratedFile will read the generated file at the given path.
@@ -737,6 +884,67 @@ func readFile(path string) (string, bool, error) {
 	return string(data), true, nil
 }
 
+// fileState is the state of a generated file on the file system.
+type fileState struct {
+	body       string
+	mode       fs.FileMode
+	linkTarget string
+}
+
+// readFileState reads the state of the file at the given path, without
+// following symlinks.
+//
+// The returned boolean indicates if the file exists.
+func readFileState(path string) (fileState, bool, error) {
+	st, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fileState{}, false, nil
+		}
+		return fileState{}, false, err
+	}
+
+	if st.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return fileState{}, false, err
+		}
+		return fileState{linkTarget: filepath.ToSlash(target)}, true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileState{}, false, err
+	}
+	return fileState{body: string(data), mode: st.Mode().Perm()}, true, nil
+}
+
+// matches tells if the file on the file system is equal to the given
+// generated file. The file mode is only compared if the generated file
+// defines one.
+func (s fileState) matches(genfile GenFile) bool {
+	if genfile.LinkTarget() != "" {
+		return s.linkTarget == genfile.LinkTarget()
+	}
+	if s.linkTarget != "" {
+		return false
+	}
+	if genfile.Mode() != 0 && s.mode != genfile.Mode() {
+		return false
+	}
+	if s.body == genfile.Header()+genfile.Body() {
+		return true
+	}
+	legacy, ok := genfile.(legacyHeaderFile)
+	return ok && s.body == legacy.LegacyHeader()+genfile.Body()
+}
+
+// legacyHeaderFile is a generated file whose header format changed. The files
+// generated with the legacy header and the same body are not outdated.
+type legacyHeaderFile interface {
+	LegacyHeader() string
+}
+
 type forEachStackFunc func(
 	*config.Root,
 	*config.Stack,
@@ -777,8 +985,8 @@ func allStackGeneratedFiles(
 	root *config.Root,
 	dir string,
 	genfiles []GenFile,
-) (map[string]string, error) {
-	allFiles := map[string]string{}
+) (map[string]fileState, error) {
+	allFiles := map[string]fileState{}
 	files, err := ListGenFiles(root, dir)
 	if err != nil {
 		return nil, err
@@ -789,34 +997,34 @@ func allStackGeneratedFiles(
 	// They may or not exist.
 	for _, genfile := range genfiles {
 		// Files that have header or that are inside the stack dir
-		// can be detected by ListGenFiles
-		if genfile.Header() == "" {
+		// can be detected by ListGenFiles, except dotfiles.
+		if genfile.Header() == "" || strings.HasPrefix(path.Base(genfile.Label()), ".") {
 			files = append(files, genfile.Label())
 		}
 	}
 
 	for _, filename := range files {
 		path := filepath.Join(dir, filename)
-		body, err := os.ReadFile(path)
+		file, found, err := readFileState(path)
 		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			}
 			return nil, errors.E(err, "reading generated file")
 		}
+		if !found {
+			continue
+		}
 
-		allFiles[filename] = string(body)
+		allFiles[filename] = file
 	}
 
 	return allFiles, nil
 }
 
-func generateRootFiles(root *config.Root, genfiles []GenFile, report *Report) {
+func generateRootFiles(root *config.Root, genfiles []GenFile, report *Report, opts Options) {
 	logger := log.With().
 		Str("action", "generate.generateRootFiles()").
 		Logger()
 
-	diskFiles := map[string]string{}         // files already on disk
+	diskFiles := map[string]fileState{}      // files already on disk
 	mustExistFiles := map[string]GenFile{}   // files that must be present on disk
 	mustDeleteFiles := map[string]struct{}{} // files to be deleted
 
@@ -850,22 +1058,22 @@ func generateRootFiles(root *config.Root, genfiles []GenFile, report *Report) {
 
 		abspath := filepath.Join(root.HostDir(), label)
 		dir := path.Dir(label)
-		body, err := os.ReadFile(abspath)
+		file, found, err := readFileState(abspath)
 		if err != nil {
-			if os.IsNotExist(err) {
-				logger.Debug().Msg("file do not exists")
-
-				continue
-			}
 			dirReport := dirReport{}
 			dirReport.err = errors.E(err, "reading generated file")
 			report.addDirReport(project.NewPath(dir), dirReport)
 			return
 		}
+		if !found {
+			logger.Debug().Msg("file do not exists")
+
+			continue
+		}
 
 		logger.Debug().Msg("file content read successfully")
 
-		diskFiles[label] = string(body)
+		diskFiles[label] = file
 	}
 
 	// this deletes the files that exist but have condition=false.
@@ -901,17 +1109,17 @@ func generateRootFiles(root *config.Root, genfiles []GenFile, report *Report) {
 		abspath := filepath.Join(root.HostDir(), label)
 		filename := path.Base(label)
 		dir := project.NewPath(path.Dir(label))
-		body := genfile.Header() + genfile.Body()
 
 		dirReport := dirReport{}
-		diskContent, existOnDisk := diskFiles[label]
-		if !existOnDisk || body != diskContent {
+		diskFile, existOnDisk := diskFiles[label]
+		changed := existOnDisk && !diskFile.matches(genfile)
+		if !existOnDisk || changed {
 			logger.Debug().
 				Bool("existOnDisk", existOnDisk).
-				Bool("fileChanged", body != diskContent).
+				Bool("fileChanged", changed).
 				Msg("writing file")
 
-			err := writeGeneratedCode(root, abspath, genfile)
+			err := writeGeneratedCode(root, abspath, genfile, opts.Force)
 			if err != nil {
 				dirReport.err = errors.E(err, "saving file %s", label)
 				report.addDirReport(dir, dirReport)
@@ -923,7 +1131,7 @@ func generateRootFiles(root *config.Root, genfiles []GenFile, report *Report) {
 
 		if !existOnDisk {
 			dirReport.addCreatedFile(filename)
-		} else if body != diskContent {
+		} else if changed {
 			dirReport.addChangedFile(label)
 		} else {
 			logger.Debug().Msg("nothing to do, file on disk is up to date.")
@@ -956,13 +1164,18 @@ func hasGenHCLHeader(commentStyle genhcl.CommentStyle, code string) bool {
 			return true
 		}
 	}
-	return false
+	_, _, ok := genhcl.ParseChecksum(commentStyle, code)
+	return ok
 }
 
 func validateStackGeneratedFiles(root *config.Root, stackpath string, generated []GenFile) error {
 	errs := errors.L()
 
 	for _, file := range generated {
+		if file.Condition() && file.LinkTarget() != "" {
+			errs.Append(validateSymlinkTarget(root, stackpath, file))
+		}
+
 		relpath := file.Label()
 		if !strings.Contains(relpath, "/") {
 			continue
@@ -1029,6 +1242,24 @@ func validateStackGeneratedFiles(root *config.Root, stackpath string, generated
 	return errs.AsError()
 }
 
+// validateSymlinkTarget checks that the target of the generated symlink is
+// a relative path that points to somewhere inside the project.
+func validateSymlinkTarget(root *config.Root, stackpath string, file GenFile) error {
+	target := file.LinkTarget()
+	if path.IsAbs(target) || filepath.IsAbs(target) {
+		return errors.E(ErrInvalidSymlinkTarget, file.Range(),
+			"%s: target %q must be a relative path", file.Label(), target)
+	}
+	linkdir := filepath.Dir(filepath.Join(stackpath, filepath.FromSlash(file.Label())))
+	abstarget := filepath.Join(linkdir, filepath.FromSlash(target))
+	relpath, err := filepath.Rel(root.HostDir(), abstarget)
+	if err != nil || relpath == ".." || strings.HasPrefix(relpath, ".."+string(filepath.Separator)) {
+		return errors.E(ErrInvalidSymlinkTarget, file.Range(),
+			"%s: target %q points outside the project", file.Label(), target)
+	}
+	return nil
+}
+
 func validateRootGenerateBlock(root *config.Root, block hcl.GenFileBlock) error {
 	target := block.Label
 	if !path.IsAbs(target) {
@@ -1138,61 +1369,46 @@ func checkFileConflict(generated []GenFile) map[string]error {
 	return errsmap
 }
 
-func loadAsserts(root *config.Root, st *config.Stack, globals *eval.Object) ([]config.Assert, error) {
-	logger := log.With().
-		Str("action", "generate.loadAsserts").
-		Str("rootdir", root.HostDir()).
-		Str("stack", st.Dir.String()).
-		Logger()
-
-	curdir := st.Dir
-	asserts := []config.Assert{}
-	errs := errors.L()
-
-	for {
-		logger = logger.With().
-			Stringer("curdir", curdir).
-			Logger()
+// loadStackCodeCfgs loads the generate blocks of the stack, failing if any
+// assertion of the stack or of its generate blocks fails.
+func loadStackCodeCfgs(
+	root *config.Root,
+	st *config.Stack,
+	globals *eval.Object,
+	vendorDir project.Path,
+	vendorRequests chan<- event.VendorRequest,
+) ([]GenFile, error) {
+	asserts, err := stack.LoadAsserts(root, st, globals)
+	if err != nil {
+		return nil, err
+	}
 
-		evalctx := stack.NewEvalCtx(root, st, globals)
-		cfg, ok := root.Lookup(curdir)
-		if ok {
-			for _, assertCfg := range cfg.Node.Asserts {
-				assert, err := config.EvalAssert(evalctx.Context, assertCfg)
-				if err != nil {
-					errs.Append(err)
-				} else {
-					asserts = append(asserts, assert)
-				}
-			}
-		}
+	genfilesConfigs, err := loadStackGenFiles(root, st, globals, vendorDir, vendorRequests)
+	if err != nil {
+		return nil, err
+	}
 
-		if p := curdir.Dir(); p != curdir {
-			curdir = p
-		} else {
-			break
-		}
+	for _, gen := range genfilesConfigs {
+		asserts = append(asserts, gen.Asserts()...)
 	}
 
-	if err := errs.AsError(); err != nil {
+	err = handleAsserts(root.HostDir(), st.HostDir(root), asserts)
+	if err != nil {
 		return nil, err
 	}
 
-	return asserts, nil
+	return genfilesConfigs, nil
 }
 
-func loadStackCodeCfgs(
+// loadStackGenFiles loads the generate blocks of the stack, sorted by label,
+// without checking their assertions.
+func loadStackGenFiles(
 	root *config.Root,
 	st *config.Stack,
 	globals *eval.Object,
 	vendorDir project.Path,
 	vendorRequests chan<- event.VendorRequest,
 ) ([]GenFile, error) {
-	asserts, err := loadAsserts(root, st, globals)
-	if err != nil {
-		return nil, err
-	}
-
 	var genfilesConfigs []GenFile
 
 	genfiles, err := genfile.Load(root, st, globals, vendorDir, vendorRequests)
@@ -1216,16 +1432,6 @@ func loadStackCodeCfgs(
 	sort.Slice(genfilesConfigs, func(i, j int) bool {
 		return genfilesConfigs[i].Label() < genfilesConfigs[j].Label()
 	})
-
-	for _, gen := range genfilesConfigs {
-		asserts = append(asserts, gen.Asserts()...)
-	}
-
-	err = handleAsserts(root.HostDir(), st.HostDir(root), asserts)
-	if err != nil {
-		return nil, err
-	}
-
 	return genfilesConfigs, nil
 }
 
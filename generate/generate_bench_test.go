@@ -122,3 +122,53 @@ func BenchmarkGenerateRegex(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkGenerateManyStacks(b *testing.B) {
+	// benchmarks the case of a project with 1000 stacks sharing the globals
+	// of parent directories, which don't depend on the stacks.
+
+	b.StopTimer()
+	s := sandbox.NoGit(b, true)
+
+	const (
+		numGroups         = 10
+		numStacksPerGroup = 100
+	)
+
+	layout := []string{}
+	for g := 0; g < numGroups; g++ {
+		for i := 0; i < numStacksPerGroup; i++ {
+			layout = append(layout, fmt.Sprintf("s:group-%d/stack-%d", g, i))
+		}
+	}
+	s.BuildTree(layout)
+
+	s.RootEntry().CreateFile("globals.tm", `
+	globals {
+		list    = tm_range(1000)
+		squares = [for i in global.list : i * i]
+		labels  = [for i in global.squares : "label-${i}"]
+	}
+
+	generate_hcl "locals.tf" {
+		content {
+			locals {
+				name  = terramate.stack.name
+				total = tm_length(global.labels)
+			}
+		}
+	}
+	`)
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		root, err := config.LoadRoot(s.RootDir())
+		assert.NoError(b, err)
+		b.StartTimer()
+
+		report := generate.Do(root, project.NewPath("/vendor"), nil)
+		if report.HasFailures() {
+			b.Fatal(report.Full())
+		}
+	}
+}
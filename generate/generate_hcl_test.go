@@ -1340,6 +1340,168 @@ func TestWontOverwriteManuallyDefinedTerraform(t *testing.T) {
 	assert.EqualStrings(t, manualTfCode, actualTfCode, "tf code altered by generate")
 }
 
+func TestWontOverwriteManuallyEditedGeneratedCode(t *testing.T) {
+	t.Parallel()
+
+	const genFilename = "test.tf"
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+	})
+	s.RootEntry().CreateConfig(
+		GenerateHCL(
+			Labels(genFilename),
+			Content(
+				Terraform(
+					Str("required_version", "1.11"),
+				),
+			),
+		).String(),
+	)
+
+	s.Generate()
+
+	stack := s.StackEntry("stack")
+	generatedCode := stack.ReadFile(genFilename)
+	editedCode := generatedCode + "\n# manual edit\n"
+	stack.CreateFile(genFilename, editedCode)
+
+	s.RootEntry().CreateConfig(
+		GenerateHCL(
+			Labels(genFilename),
+			Content(
+				Terraform(
+					Str("required_version", "1.12"),
+				),
+			),
+		).String(),
+	)
+	s.ReloadConfig()
+
+	outdated, err := generate.DetectOutdated(s.Config(), project.NewPath("/modules"))
+	assert.NoError(t, err)
+	edited, err := generate.DetectManualEdits(s.Config(), outdated)
+	assert.NoError(t, err)
+	assertEqualStringList(t, edited, []string{"stack/" + genFilename})
+
+	report := generate.Do(s.Config(), project.NewPath("/modules"), nil)
+	assert.EqualInts(t, 0, len(report.Successes), "want no success")
+	assert.EqualInts(t, 1, len(report.Failures), "want single failure")
+	assertReportHasError(t, report, errors.E(generate.ErrManualCodeEdited))
+	if !strings.Contains(report.Full(), "/stack/"+genFilename) {
+		t.Fatalf("report must name the edited file:\n%s", report.Full())
+	}
+	assert.EqualStrings(t, editedCode, stack.ReadFile(genFilename), "edited code altered by generate")
+
+	report = generate.DoWithOptions(s.Config(), project.NewPath("/modules"), nil, generate.Options{
+		Force: true,
+	})
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stack"),
+				Changed: []string{genFilename},
+			},
+		},
+	})
+
+	edited, err = generate.DetectManualEdits(s.Config(), []string{"stack/" + genFilename})
+	assert.NoError(t, err)
+	assertEqualStringList(t, edited, []string{})
+}
+
+func TestOverwriteGeneratedCodeWithoutChecksum(t *testing.T) {
+	t.Parallel()
+
+	const genFilename = "test.tf"
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		fmt.Sprintf("f:stack/%s:%s", genFilename, genhcl.DefaultHeader()+"manual edit"),
+	})
+	s.RootEntry().CreateConfig(
+		GenerateHCL(
+			Labels(genFilename),
+			Content(
+				Terraform(
+					Str("required_version", "1.11"),
+				),
+			),
+		).String(),
+	)
+
+	assertEqualReports(t, s.Generate(), generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stack"),
+				Changed: []string{genFilename},
+			},
+		},
+	})
+}
+
+func TestGeneratedCodeWithoutChecksumIsNotOutdated(t *testing.T) {
+	t.Parallel()
+
+	const genFilename = "test.tf"
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+	})
+	s.RootEntry().CreateConfig(
+		GenerateHCL(
+			Labels(genFilename),
+			Content(
+				Terraform(
+					Str("required_version", "1.11"),
+				),
+			),
+		).String(),
+	)
+
+	s.Generate()
+
+	// code generated by versions which didn't write the checksum.
+	stack := s.StackEntry("stack")
+	_, body, ok := genhcl.ParseChecksum(genhcl.DefaultComment, stack.ReadFile(genFilename))
+	assert.IsTrue(t, ok, "generated code must have a checksum")
+	legacyCode := genhcl.DefaultHeader() + body
+	stack.CreateFile(genFilename, legacyCode)
+
+	outdated, err := generate.DetectOutdated(s.Config(), project.NewPath("/modules"))
+	assert.NoError(t, err)
+	assertEqualStringList(t, outdated, []string{})
+
+	assertEqualReports(t, s.Generate(), generate.Report{})
+	assert.EqualStrings(t, legacyCode, stack.ReadFile(genFilename), "unchanged code rewritten by generate")
+
+	s.RootEntry().CreateConfig(
+		GenerateHCL(
+			Labels(genFilename),
+			Content(
+				Terraform(
+					Str("required_version", "1.12"),
+				),
+			),
+		).String(),
+	)
+	s.ReloadConfig()
+
+	assertEqualReports(t, s.Generate(), generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stack"),
+				Changed: []string{genFilename},
+			},
+		},
+	})
+	_, _, ok = genhcl.ParseChecksum(genhcl.DefaultComment, stack.ReadFile(genFilename))
+	assert.IsTrue(t, ok, "regenerated code must have a checksum")
+}
+
 func TestGenerateHCLStackFilters(t *testing.T) {
 	t.Parallel()
 
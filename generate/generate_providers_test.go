@@ -0,0 +1,309 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package generate_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/generate"
+	"github.com/terramate-io/terramate/generate/genhcl"
+	"github.com/terramate-io/terramate/project"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestGenerateProvidersFromCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog := Globals(
+		Expr("providers", `{
+			aws = {
+				source      = "hashicorp/aws"
+				version     = "5.31.0"
+				constraints = "~> 5.31"
+				hashes      = ["zh:bbb", "h1:aaa"]
+			}
+			google = {
+				source  = "hashicorp/google"
+				version = "5.10.0"
+			}
+			null = {
+				source  = "registry.terraform.io/hashicorp/null"
+				version = "3.2.2"
+			}
+		}`),
+	)
+
+	requiredProviders := GenerateHCL(
+		Labels("providers.tf"),
+		Content(
+			Block("terraform",
+				Block("tm_dynamic",
+					Labels("required_providers"),
+					Expr("attributes", `tm_terraform_required_providers(global.providers)`),
+				),
+			),
+		),
+	)
+
+	lockFile := GenerateHCL(
+		Labels(".terraform.lock.hcl"),
+		Content(
+			Block("tm_dynamic",
+				Labels("provider"),
+				Expr("for_each", `tm_terraform_lock_providers(global.providers)`),
+				Expr("labels", `[provider.key]`),
+				Expr("attributes", `provider.value`),
+			),
+		),
+	)
+
+	const outdated = "resource \"azurerm_resource_group\" \"a\" {}\n"
+
+	testCodeGeneration(t, []testcase{
+		{
+			name: "required_providers and lock file of referenced providers",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add:  Doc(catalog, requiredProviders, lockFile),
+				},
+				{
+					path:     "/stack",
+					filename: "main.tf",
+					add: Doc(
+						Block("resource", Labels("aws_instance", "a")),
+						Block("data", Labels("null_data_source", "a")),
+						Block("data", Labels("terraform_remote_state", "a")),
+					),
+				},
+			},
+			want: []generatedFile{
+				{
+					dir: "/stack",
+					files: map[string]fmt.Stringer{
+						"providers.tf": Terraform(
+							Block("required_providers",
+								Expr("aws", `{
+									source  = "hashicorp/aws"
+									version = "~> 5.31"
+								}`),
+								Expr("null", `{
+									source  = "registry.terraform.io/hashicorp/null"
+									version = "3.2.2"
+								}`),
+							),
+						),
+						".terraform.lock.hcl": Doc(
+							Block("provider",
+								Labels("registry.terraform.io/hashicorp/aws"),
+								Str("constraints", "~> 5.31"),
+								Expr("hashes", `["h1:aaa", "zh:bbb"]`),
+								Str("version", "5.31.0"),
+							),
+							Block("provider",
+								Labels("registry.terraform.io/hashicorp/null"),
+								Str("constraints", "3.2.2"),
+								Expr("hashes", `[]`),
+								Str("version", "3.2.2"),
+							),
+						),
+					},
+				},
+			},
+			wantReport: generate.Report{
+				Successes: []generate.Result{
+					{
+						Dir:     project.NewPath("/stack"),
+						Created: []string{".terraform.lock.hcl", "providers.tf"},
+					},
+				},
+			},
+		},
+		{
+			name: "provider referenced by provider block and meta-argument",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add:  Doc(catalog, requiredProviders),
+				},
+				{
+					path:     "/stack",
+					filename: "main.tf",
+					add: Doc(
+						Block("provider", Labels("google")),
+						Block("resource",
+							Labels("custom_thing", "a"),
+							Expr("provider", "aws.west"),
+						),
+					),
+				},
+			},
+			want: []generatedFile{
+				{
+					dir: "/stack",
+					files: map[string]fmt.Stringer{
+						"providers.tf": Terraform(
+							Block("required_providers",
+								Expr("aws", `{
+									source  = "hashicorp/aws"
+									version = "~> 5.31"
+								}`),
+								Expr("google", `{
+									source  = "hashicorp/google"
+									version = "5.10.0"
+								}`),
+							),
+						),
+					},
+				},
+			},
+			wantReport: generate.Report{
+				Successes: []generate.Result{
+					{
+						Dir:     project.NewPath("/stack"),
+						Created: []string{"providers.tf"},
+					},
+				},
+			},
+		},
+		{
+			name: "generated files not generated anymore are not considered",
+			layout: []string{
+				"s:stack",
+				"f:stack/old.tf:" + genhcl.HeaderWithChecksum(genhcl.SlashComment,
+					`resource "azurerm_resource_group" "a" {}`),
+				"f:stack/legacy.tf:" + genhcl.Header(genhcl.SlashComment) +
+					`resource "azurerm_resource_group" "b" {}`,
+				"f:stack/v0.tf:" + genhcl.HeaderV0 + "\n\n" +
+					`resource "azurerm_resource_group" "c" {}`,
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add:  Doc(catalog, requiredProviders),
+				},
+				{
+					path:     "/stack",
+					filename: "main.tf",
+					add:      Block("resource", Labels("aws_instance", "a")),
+				},
+			},
+			want: []generatedFile{
+				{
+					dir: "/stack",
+					files: map[string]fmt.Stringer{
+						"providers.tf": Terraform(
+							Block("required_providers",
+								Expr("aws", `{
+									source  = "hashicorp/aws"
+									version = "~> 5.31"
+								}`),
+							),
+						),
+					},
+				},
+			},
+			wantReport: generate.Report{
+				Successes: []generate.Result{
+					{
+						Dir:     project.NewPath("/stack"),
+						Created: []string{"providers.tf"},
+						Deleted: []string{"legacy.tf", "old.tf", "v0.tf"},
+					},
+				},
+			},
+		},
+		{
+			name: "generated files are considered with their up-to-date content",
+			layout: []string{
+				"s:stack",
+				"f:stack/google.tf:" + genhcl.HeaderWithChecksum(genhcl.SlashComment, outdated) + outdated,
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						catalog,
+						requiredProviders,
+						GenerateHCL(
+							Labels("google.tf"),
+							Content(
+								Block("provider", Labels("google")),
+							),
+						),
+					),
+				},
+				{
+					path:     "/stack",
+					filename: "main.tf",
+					add:      Block("resource", Labels("aws_instance", "a")),
+				},
+			},
+			want: []generatedFile{
+				{
+					dir: "/stack",
+					files: map[string]fmt.Stringer{
+						"google.tf": Block("provider", Labels("google")),
+						"providers.tf": Terraform(
+							Block("required_providers",
+								Expr("aws", `{
+									source  = "hashicorp/aws"
+									version = "~> 5.31"
+								}`),
+								Expr("google", `{
+									source  = "hashicorp/google"
+									version = "5.10.0"
+								}`),
+							),
+						),
+					},
+				},
+			},
+			wantReport: generate.Report{
+				Successes: []generate.Result{
+					{
+						Dir:     project.NewPath("/stack"),
+						Created: []string{"providers.tf"},
+						Changed: []string{"google.tf"},
+					},
+				},
+			},
+		},
+		{
+			name: "provider not in the catalog fails",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add:  Doc(catalog, requiredProviders),
+				},
+				{
+					path:     "/stack",
+					filename: "main.tf",
+					add:      Block("resource", Labels("azurerm_resource_group", "a")),
+				},
+			},
+			wantReport: generate.Report{
+				Failures: []generate.FailureResult{
+					{
+						Result: generate.Result{
+							Dir: project.NewPath("/stack"),
+						},
+						Error: errors.E(genhcl.ErrContentEval),
+					},
+				},
+			},
+		},
+	})
+}
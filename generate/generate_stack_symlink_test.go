@@ -0,0 +1,189 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package generate_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/generate"
+	"github.com/terramate-io/terramate/project"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestGenerateFileMode(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	stackEntry := s.CreateStack("stack")
+
+	assertMode := func(file string, want fs.FileMode) {
+		t.Helper()
+
+		st, err := os.Stat(filepath.Join(stackEntry.Path(), file))
+		assert.NoError(t, err)
+		if st.Mode().Perm() != want {
+			t.Fatalf("file %s has mode %s but want %s", file, st.Mode().Perm(), want)
+		}
+	}
+
+	createConfig := func(mode string) {
+		stackEntry.CreateConfig(
+			GenerateFile(
+				Labels("pre-plan.sh"),
+				Expr("content", `"#!/bin/sh\necho hello\n"`),
+				Str("file_mode", mode),
+			).String(),
+		)
+	}
+
+	createConfig("0777")
+	report := s.Generate()
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stack"),
+				Created: []string{"pre-plan.sh"},
+			},
+		},
+	})
+	assertMode("pre-plan.sh", 0777)
+
+	createConfig("0700")
+	report = s.Generate()
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stack"),
+				Changed: []string{"pre-plan.sh"},
+			},
+		},
+	})
+	assertMode("pre-plan.sh", 0700)
+
+	report = s.Generate()
+	assertEqualReports(t, report, generate.Report{})
+	assertMode("pre-plan.sh", 0700)
+}
+
+func TestGenerateFileBase64Content(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	stackEntry := s.CreateStack("stack")
+	stackEntry.CreateConfig(
+		GenerateFile(
+			Labels("data.bin"),
+			Str("content_base64", "AAEC/w=="),
+		).String(),
+	)
+
+	report := s.Generate()
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stack"),
+				Created: []string{"data.bin"},
+			},
+		},
+	})
+	assert.EqualStrings(t, "\x00\x01\x02\xff", stackEntry.ReadFile("data.bin"))
+}
+
+func TestGenerateSymlink(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"f:shared/pre-plan.sh:echo shared",
+		"f:shared/other.sh:echo other",
+	})
+	stackEntry := s.CreateStack("stacks/stack")
+
+	assertLink := func(file, want string) {
+		t.Helper()
+
+		got, err := os.Readlink(filepath.Join(stackEntry.Path(), file))
+		assert.NoError(t, err)
+		assert.EqualStrings(t, want, filepath.ToSlash(got))
+	}
+
+	createConfig := func(target string, condition bool) {
+		stackEntry.CreateConfig(
+			Block("generate_symlink",
+				Labels("pre-plan.sh"),
+				Bool("condition", condition),
+				Str("target", target),
+			).String(),
+		)
+	}
+
+	createConfig("../../shared/pre-plan.sh", true)
+	report := s.Generate()
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stacks/stack"),
+				Created: []string{"pre-plan.sh"},
+			},
+		},
+	})
+	assertLink("pre-plan.sh", "../../shared/pre-plan.sh")
+	assert.EqualStrings(t, "echo shared", stackEntry.ReadFile("pre-plan.sh"))
+
+	report = s.Generate()
+	assertEqualReports(t, report, generate.Report{})
+
+	createConfig("../../shared/other.sh", true)
+	report = s.Generate()
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stacks/stack"),
+				Changed: []string{"pre-plan.sh"},
+			},
+		},
+	})
+	assertLink("pre-plan.sh", "../../shared/other.sh")
+
+	createConfig("../../shared/other.sh", false)
+	report = s.Generate()
+	assertEqualReports(t, report, generate.Report{
+		Successes: []generate.Result{
+			{
+				Dir:     project.NewPath("/stacks/stack"),
+				Deleted: []string{"pre-plan.sh"},
+			},
+		},
+	})
+	_, err := os.Lstat(filepath.Join(stackEntry.Path(), "pre-plan.sh"))
+	assert.IsTrue(t, errors.Is(err, os.ErrNotExist), "symlink must be deleted")
+	assert.EqualStrings(t, "echo other", string(s.RootEntry().ReadFile("shared/other.sh")))
+}
+
+func TestGenerateSymlinkInvalidTarget(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{
+		"/shared/file.txt",
+		"../../outside.txt",
+	} {
+		s := sandbox.NoGit(t, true)
+		stackEntry := s.CreateStack("stack")
+		stackEntry.CreateConfig(
+			Block("generate_symlink",
+				Labels("file.txt"),
+				Str("target", target),
+			).String(),
+		)
+
+		report := generate.Do(s.Config(), project.NewPath("/modules"), nil)
+		assertReportHasError(t, report, errors.E(generate.ErrInvalidSymlinkTarget))
+	}
+}
@@ -5,11 +5,16 @@
 package genfile
 
 import (
+	"encoding/base64"
 	"fmt"
+	"io/fs"
+	"os"
 	"path"
 	"sort"
+	"strconv"
 
-	"github.com/gobwas/glob"
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/event"
@@ -43,6 +48,28 @@ const (
 	// ErrLabelConflict indicates the two generate_file blocks
 	// have the same label.
 	ErrLabelConflict errors.Kind = "label conflict detected"
+
+	// ErrTemplateEval indicates an error when rendering the template_file.
+	ErrTemplateEval errors.Kind = "evaluating template_file"
+
+	// ErrInvalidTemplateVarsType indicates the template_vars attribute
+	// has an invalid type.
+	ErrInvalidTemplateVarsType errors.Kind = "invalid template_vars type"
+
+	// ErrInvalidContentBase64 indicates the content_base64 attribute
+	// is not valid base64 encoded data.
+	ErrInvalidContentBase64 errors.Kind = "invalid content_base64"
+
+	// ErrInvalidFileMode indicates the file_mode attribute is invalid.
+	ErrInvalidFileMode errors.Kind = "invalid file_mode"
+
+	// ErrTargetEval indicates an error when evaluating the target attribute
+	// of a generate_symlink block.
+	ErrTargetEval errors.Kind = "evaluating target"
+
+	// ErrInvalidTargetType indicates the target attribute of a
+	// generate_symlink block has an invalid type.
+	ErrInvalidTargetType errors.Kind = "invalid target type"
 )
 
 const (
@@ -53,14 +80,17 @@ const (
 	RootContext = "root"
 )
 
-// File represents generated file from a single generate_file block.
+// File represents generated file from a single generate_file or
+// generate_symlink block.
 type File struct {
-	label     string
-	context   string
-	origin    info.Range
-	body      string
-	condition bool
-	asserts   []config.Assert
+	label      string
+	context    string
+	origin     info.Range
+	body       string
+	mode       fs.FileMode
+	linkTarget string
+	condition  bool
+	asserts    []config.Assert
 }
 
 // Label of the original generate_file block.
@@ -73,6 +103,18 @@ func (f File) Body() string {
 	return f.body
 }
 
+// Mode returns the permission bits of the file, as defined by the file_mode
+// attribute. It returns zero if no file_mode was defined.
+func (f File) Mode() fs.FileMode {
+	return f.mode
+}
+
+// LinkTarget returns the target of the symlink if the file was generated by
+// a generate_symlink block, or an empty string otherwise.
+func (f File) LinkTarget() string {
+	return f.linkTarget
+}
+
 // Range returns the range information of the generate_file block.
 func (f File) Range() info.Range {
 	return f.origin
@@ -103,20 +145,24 @@ func (f File) Header() string {
 }
 
 func (f File) String() string {
+	if f.linkTarget != "" {
+		return fmt.Sprintf("generate_symlink %q (condition %t) (target %q) (origin %q)",
+			f.Label(), f.Condition(), f.LinkTarget(), f.Range().Path())
+	}
 	return fmt.Sprintf("generate_file %q (condition %t) (body %q) (origin %q)",
 		f.Label(), f.Condition(), f.Body(), f.Range().Path())
 }
 
-// Load loads and parses from the file system all generate_file blocks for
-// a given stack. It will navigate the file system from the stack dir until
-// it reaches rootdir, loading generate_file blocks found on Terramate
-// configuration files.
+// Load loads and parses from the file system all generate_file and
+// generate_symlink blocks for a given stack. It will navigate the file system
+// from the stack dir until it reaches rootdir, loading the blocks found on
+// Terramate configuration files.
 //
-// All generate_file blocks must have unique labels, even ones at different
-// directories. Any conflicts will be reported as an error.
+// All generate_file and generate_symlink blocks must have unique labels, even
+// ones at different directories. Any conflicts will be reported as an error.
 //
 // Metadata and globals for the stack are used on the evaluation of the
-// generate_file blocks.
+// blocks.
 //
 // The rootdir MUST be an absolute path.
 func Load(
@@ -140,25 +186,8 @@ func Load(
 
 		name := genFileBlock.Label
 
-		matchedAnyStackFilter := len(genFileBlock.StackFilters) == 0
-		for _, cond := range genFileBlock.StackFilters {
-			matched := true
-
-			for n, globs := range map[string][]glob.Glob{
-				"project path":    cond.ProjectPaths,
-				"repository path": cond.RepositoryPaths,
-			} {
-				if globs != nil && !hcl.MatchAnyGlob(globs, st.Dir.String()) {
-					log.Logger.Trace().Msgf("Skipping %q, %s doesn't match any filter in %v", st.Dir, n, globs)
-					matched = false
-					break
-				}
-			}
-
-			matchedAnyStackFilter = matchedAnyStackFilter || matched
-		}
-
-		if !matchedAnyStackFilter {
+		if !hcl.MatchStackFilters(genFileBlock.StackFilters, st.Dir.String()) {
+			log.Logger.Trace().Msgf("Skipping %q, it doesn't match any stack_filter", st.Dir)
 			files = append(files, File{
 				label:     name,
 				origin:    genFileBlock.Range,
@@ -182,6 +211,15 @@ func Load(
 		files = append(files, file)
 	}
 
+	for _, symlinkBlock := range loadGenSymlinkBlocks(root, st.Dir) {
+		evalctx := stack.NewEvalCtx(root, st, globals)
+		file, err := EvalSymlink(symlinkBlock, evalctx.Context)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
 	sort.Slice(files, func(i, j int) bool {
 		return files[i].String() < files[j].String()
 	})
@@ -210,6 +248,7 @@ func Eval(block hcl.GenFileBlock, evalctx *eval.Context) (File, error) {
 				value.Type().FriendlyName(),
 			)
 		}
+		value, _ = value.Unmark()
 		condition = value.True()
 	}
 
@@ -252,29 +291,214 @@ func Eval(block hcl.GenFileBlock, evalctx *eval.Context) (File, error) {
 		}, nil
 	}
 
-	value, err := evalctx.Eval(block.Content.Expr)
-	if err != nil {
-		return File{}, errors.E(ErrContentEval, err)
+	var value cty.Value
+	contentAttr := "content"
+	switch {
+	case block.TemplateFile != nil:
+		value, err = evalTemplateFile(block.TemplateFile, evalctx)
+		if err != nil {
+			return File{}, err
+		}
+	case block.ContentBase64 != nil:
+		contentAttr = "content_base64"
+		value, err = evalctx.Eval(block.ContentBase64.Expr)
+		if err != nil {
+			return File{}, errors.E(ErrContentEval, err)
+		}
+	default:
+		value, err = evalctx.Eval(block.Content.Expr)
+		if err != nil {
+			return File{}, errors.E(ErrContentEval, err)
+		}
 	}
 
 	if value.Type() != cty.String {
 		return File{}, errors.E(
 			ErrInvalidContentType,
-			"content has type %s but must be string",
+			"%s has type %s but must be string",
+			contentAttr,
 			value.Type().FriendlyName(),
 		)
 	}
 
+	if value.IsMarked() {
+		if !block.AllowSensitive && eval.IsSensitive(value) {
+			return File{}, errors.E(eval.ErrSensitive, block.Range,
+				"%s is sensitive and sensitive values are not allowed in generated "+
+					"files unless allow_sensitive = true", contentAttr)
+		}
+		value, _ = value.Unmark()
+	}
+
+	body := value.AsString()
+	if block.ContentBase64 != nil {
+		data, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return File{}, errors.E(ErrInvalidContentBase64,
+				block.ContentBase64.Expr.Range(), err)
+		}
+		body = string(data)
+	}
+
+	var mode fs.FileMode
+	if block.FileMode != nil {
+		mode, err = evalFileMode(block.FileMode, evalctx)
+		if err != nil {
+			return File{}, err
+		}
+	}
+
 	return File{
 		label:     name,
 		origin:    block.Range,
-		body:      value.AsString(),
+		body:      body,
+		mode:      mode,
 		condition: condition,
 		context:   block.Context,
 		asserts:   asserts,
 	}, nil
 }
 
+// EvalSymlink evaluates the generate_symlink block.
+func EvalSymlink(block hcl.GenSymlinkBlock, evalctx *eval.Context) (File, error) {
+	err := lets.Load(block.Lets, evalctx)
+	if err != nil {
+		return File{}, err
+	}
+
+	file := File{
+		label:     block.Label,
+		origin:    block.Range,
+		condition: true,
+		context:   StackContext,
+	}
+
+	if block.Condition != nil {
+		value, err := evalctx.Eval(block.Condition.Expr)
+		if err != nil {
+			return File{}, errors.E(ErrConditionEval, err)
+		}
+		if value.Type() != cty.Bool {
+			return File{}, errors.E(
+				ErrInvalidConditionType,
+				"condition has type %s but must be boolean",
+				value.Type().FriendlyName(),
+			)
+		}
+		value, _ = value.Unmark()
+		file.condition = value.True()
+	}
+
+	if !file.condition {
+		return file, nil
+	}
+
+	value, err := evalctx.Eval(block.Target.Expr)
+	if err != nil {
+		return File{}, errors.E(ErrTargetEval, err)
+	}
+	value, _ = value.Unmark()
+	if value.Type() != cty.String {
+		return File{}, errors.E(
+			ErrInvalidTargetType,
+			block.Target.Expr.Range(),
+			"target has type %s but must be string",
+			value.Type().FriendlyName(),
+		)
+	}
+	if value.AsString() == "" {
+		return File{}, errors.E(
+			ErrInvalidTargetType,
+			block.Target.Expr.Range(),
+			"target can't be empty",
+		)
+	}
+	file.linkTarget = value.AsString()
+	return file, nil
+}
+
+// evalFileMode evaluates the file_mode attribute, which must be a string
+// with the octal representation of the permission bits, like "0755".
+func evalFileMode(attr *hclsyntax.Attribute, evalctx *eval.Context) (fs.FileMode, error) {
+	value, err := evalctx.Eval(attr.Expr)
+	if err != nil {
+		return 0, errors.E(ErrInvalidFileMode, err)
+	}
+	value, _ = value.Unmark()
+	if value.Type() != cty.String {
+		return 0, errors.E(
+			ErrInvalidFileMode,
+			attr.Expr.Range(),
+			"file_mode has type %s but must be string",
+			value.Type().FriendlyName(),
+		)
+	}
+	mode, err := strconv.ParseUint(value.AsString(), 8, 32)
+	if err != nil || mode == 0 || mode > uint64(fs.ModePerm) {
+		return 0, errors.E(
+			ErrInvalidFileMode,
+			attr.Expr.Range(),
+			"file_mode %q must be an octal number between 0001 and 0777",
+			value.AsString(),
+		)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// evalTemplateFile renders the template file using Terraform template syntax.
+// The template has access to the same namespaces and functions as the content
+// attribute, plus each of the template_vars as a top-level variable.
+func evalTemplateFile(tmpl *hcl.TemplateFile, evalctx *eval.Context) (cty.Value, error) {
+	src, err := os.ReadFile(tmpl.HostPath)
+	if err != nil {
+		return cty.NilVal, errors.E(ErrTemplateEval, tmpl.Attr.Expr.Range(), err,
+			"reading template file %s", tmpl.Path)
+	}
+
+	expr, diags := hclsyntax.ParseTemplate(src, tmpl.HostPath, hhcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, errors.E(ErrTemplateEval, diags)
+	}
+
+	tmplctx := evalctx
+	if tmpl.Vars != nil {
+		vars, err := evalctx.Eval(tmpl.Vars.Expr)
+		if err != nil {
+			return cty.NilVal, errors.E(ErrTemplateEval, err)
+		}
+		if !vars.Type().IsObjectType() && !vars.Type().IsMapType() {
+			return cty.NilVal, errors.E(
+				ErrInvalidTemplateVarsType,
+				tmpl.Vars.Expr.Range(),
+				"template_vars has type %s but must be an object",
+				vars.Type().FriendlyName(),
+			)
+		}
+
+		// the rendered content of sensitive vars is also sensitive.
+		vars, varsMarks := vars.Unmark()
+		tmplctx = evalctx.Copy()
+		for name, val := range vars.AsValueMap() {
+			val = val.WithMarks(varsMarks)
+			if tmplctx.HasNamespace(name) {
+				return cty.NilVal, errors.E(
+					ErrInvalidTemplateVarsType,
+					tmpl.Vars.Expr.Range(),
+					"template_vars.%s conflicts with the %s namespace",
+					name, name,
+				)
+			}
+			tmplctx.Unwrap().Variables[name] = val
+		}
+	}
+
+	value, err := tmplctx.Eval(expr)
+	if err != nil {
+		return cty.NilVal, errors.E(ErrTemplateEval, err)
+	}
+	return value, nil
+}
+
 // loadGenFileBlocks will load all generate_file blocks.
 // The returned map maps the name of the block (its label)
 // to the original block and the path (relative to project root) of the config
@@ -299,3 +523,20 @@ func loadGenFileBlocks(tree *config.Root, cfgdir project.Path) ([]hcl.GenFileBlo
 	res = append(res, parentRes...)
 	return res, nil
 }
+
+// loadGenSymlinkBlocks will load all generate_symlink blocks from the given
+// dir and its parents.
+func loadGenSymlinkBlocks(tree *config.Root, cfgdir project.Path) []hcl.GenSymlinkBlock {
+	res := []hcl.GenSymlinkBlock{}
+	for {
+		cfg, ok := tree.Lookup(cfgdir)
+		if ok && !cfg.IsEmptyConfig() {
+			res = append(res, cfg.Node.Generate.Symlinks...)
+		}
+		parent := cfgdir.Dir()
+		if parent == cfgdir {
+			return res
+		}
+		cfgdir = parent
+	}
+}
@@ -922,6 +922,283 @@ stack_id=stack-id
 			},
 			wantErr: errors.E(genfile.ErrContentEval),
 		},
+		{
+			name:  "content_base64 is decoded",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("test.bin"),
+						Expr("content_base64", `tm_base64encode("binary")`),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "test.bin",
+					file: genFile{
+						condition: true,
+						body:      "binary",
+					},
+				},
+			},
+		},
+		{
+			name:  "invalid content_base64 fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("test.bin"),
+						Str("content_base64", "not base64"),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrInvalidContentBase64),
+		},
+		{
+			name:  "file_mode with invalid type fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("test.sh"),
+						Str("content", "echo"),
+						Number("file_mode", 755),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrInvalidFileMode),
+		},
+		{
+			name:  "file_mode must be octal permission bits",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("test.sh"),
+						Str("content", "echo"),
+						Str("file_mode", "01777"),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrInvalidFileMode),
+		},
+		{
+			name:  "generate_symlink target with invalid type fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: Block("generate_symlink",
+						Labels("link"),
+						Number("target", 1),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrInvalidTargetType),
+		},
+	}
+
+	for _, tcase := range tcases {
+		testGenfile(t, tcase)
+	}
+}
+
+func TestLoadGenerateFilesWithTemplate(t *testing.T) {
+	t.Parallel()
+
+	tcases := []testcase{
+		{
+			name:  "template with globals, lets, metadata and vars",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/values.yaml.tpl",
+					add: rawConfig(`region: ${global.region}
+name: ${let.name}
+path: ${terramate.stack.path.absolute}
+%{ for r in replicas ~}
+- ${r}
+%{ endfor ~}
+`),
+				},
+				{
+					path: "/stack/globals.tm",
+					add: Globals(
+						Str("region", "eu-west-1"),
+					),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("values.yaml"),
+						Lets(
+							Expr("name", `"${terramate.stack.name}-values"`),
+						),
+						Str("template_file", "values.yaml.tpl"),
+						Expr("template_vars", `{
+							replicas = ["a", "b"]
+						}`),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "values.yaml",
+					file: genFile{
+						condition: true,
+						body: `
+region: eu-west-1
+name: stack-values
+path: /stack
+- a
+- b
+`,
+					},
+				},
+			},
+		},
+		{
+			name:  "template with project absolute path defined on parent dir",
+			stack: "/stacks/stack",
+			configs: []hclconfig{
+				{
+					path: "/templates/file.tpl",
+					add:  rawConfig(`stack=${terramate.stack.path.basename}`),
+				},
+				{
+					path: "/stacks/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Str("template_file", "/templates/file.tpl"),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "file.txt",
+					file: genFile{
+						condition: true,
+						body:      "\nstack=stack",
+					},
+				},
+			},
+		},
+		{
+			name:  "template is not rendered if condition is false",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Bool("condition", false),
+						Str("template_file", "missing.tpl"),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "file.txt",
+					file: genFile{
+						condition: false,
+					},
+				},
+			},
+		},
+		{
+			name:  "missing template file fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Str("template_file", "missing.tpl"),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrTemplateEval),
+		},
+		{
+			name:  "template with invalid syntax fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/file.tpl",
+					add:  rawConfig(`${`),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Str("template_file", "file.tpl"),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrTemplateEval),
+		},
+		{
+			name:  "template referencing undefined variable fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/file.tpl",
+					add:  rawConfig(`${undefined}`),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Str("template_file", "file.tpl"),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrTemplateEval),
+		},
+		{
+			name:  "template_vars must be an object",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/file.tpl",
+					add:  rawConfig(`test`),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Str("template_file", "file.tpl"),
+						Expr("template_vars", `["a"]`),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrInvalidTemplateVarsType),
+		},
+		{
+			name:  "template_vars conflicting with namespaces fails",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/file.tpl",
+					add:  rawConfig(`test`),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("file.txt"),
+						Str("template_file", "file.tpl"),
+						Expr("template_vars", `{ global = 1 }`),
+					),
+				},
+			},
+			wantErr: errors.E(genfile.ErrInvalidTemplateVarsType),
+		},
 	}
 
 	for _, tcase := range tcases {
@@ -930,6 +1207,11 @@ stack_id=stack-id
 }
 
 type (
+	// rawConfig is used to create files with arbitrary content.
+	// Beware that the files are created with test.AppendFile, which adds
+	// a leading newline.
+	rawConfig string
+
 	hclconfig struct {
 		path string
 		add  fmt.Stringer
@@ -1021,3 +1303,5 @@ func testGenfile(t *testing.T, tcase testcase) {
 func init() {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 }
+
+func (r rawConfig) String() string { return string(r) }
@@ -0,0 +1,107 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package genfile_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/eval"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestGenerateFileSensitive(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []testcase{
+		{
+			name:  "sensitive global is not generated",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/globals.tm",
+					add: Globals(
+						Expr("password", `tm_sensitive("secret")`),
+					),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("password.txt"),
+						Expr("content", "global.password"),
+					),
+				},
+			},
+			wantErr: errors.E(eval.ErrSensitive),
+		},
+		{
+			name:  "sensitive template variable is not generated",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/config.tpl",
+					add:  rawConfig(`password: ${password}`),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("config.yaml"),
+						Str("template_file", "config.tpl"),
+						Expr("template_vars", `{
+							password = tm_sensitive("secret")
+						}`),
+					),
+				},
+			},
+			wantErr: errors.E(eval.ErrSensitive),
+		},
+		{
+			name:  "allow_sensitive generates sensitive values",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/globals.tm",
+					add: Globals(
+						Expr("password", `tm_sensitive("secret")`),
+					),
+				},
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("password.txt"),
+						Bool("allow_sensitive", true),
+						Expr("content", `"password=${global.password}"`),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "password.txt",
+					file: genFile{
+						condition: true,
+						body:      "password=secret",
+					},
+				},
+			},
+		},
+		{
+			name:  "allow_sensitive must be a literal bool",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack/test.tm",
+					add: GenerateFile(
+						Labels("password.txt"),
+						Str("allow_sensitive", "yes"),
+						Str("content", "a"),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+	} {
+		testGenfile(t, tc)
+	}
+}
@@ -5,11 +5,14 @@
 package genhcl
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	stdfmt "fmt"
+	"io/fs"
 	"path"
 	"sort"
+	"strings"
 
-	"github.com/gobwas/glob"
 	hhcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
@@ -17,6 +20,7 @@ import (
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/event"
+	"github.com/terramate-io/terramate/generate/header"
 	"github.com/terramate-io/terramate/hcl"
 	"github.com/terramate-io/terramate/hcl/ast"
 	"github.com/terramate-io/terramate/hcl/fmt"
@@ -28,6 +32,7 @@ import (
 	"github.com/terramate-io/terramate/project"
 	"github.com/terramate-io/terramate/stack"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
 )
 
 // HCL represents generated HCL code from a single block.
@@ -56,10 +61,14 @@ const (
 
 const (
 	// HeaderMagic is the current header magic string used by generate_hcl code generation.
-	HeaderMagic = "TERRAMATE: GENERATED AUTOMATICALLY DO NOT EDIT"
+	HeaderMagic = header.Magic
 
 	// HeaderV0 is the deprecated header string used by generate_hcl code generation.
-	HeaderV0 = "// GENERATED BY TERRAMATE: DO NOT EDIT"
+	HeaderV0 = header.V0
+
+	// ChecksumMagic is the magic string that precedes the checksum of the
+	// generated code on the header.
+	ChecksumMagic = "TERRAMATE: CHECKSUM sha256:"
 )
 
 const (
@@ -106,7 +115,17 @@ func (h HCL) Asserts() []config.Assert {
 }
 
 // Header returns the header of the generated HCL file.
+// The header includes the checksum of the body, so manual changes on the
+// generated file can be detected.
 func (h HCL) Header() string {
+	return HeaderWithChecksum(h.magicCommentStyle, h.Body())
+}
+
+// LegacyHeader returns the header used by the versions which didn't write the
+// checksum of the body. The files generated with it are still up to date if
+// their body didn't change, so the checksum is only written when the file is
+// regenerated.
+func (h HCL) LegacyHeader() string {
 	return Header(h.magicCommentStyle)
 }
 
@@ -116,6 +135,18 @@ func (h HCL) Body() string {
 	return string(h.body)
 }
 
+// Mode returns zero because generated HCL files always use the default
+// file mode.
+func (h HCL) Mode() fs.FileMode {
+	return 0
+}
+
+// LinkTarget returns an empty string because generated HCL files are never
+// symlinks.
+func (h HCL) LinkTarget() string {
+	return ""
+}
+
 // Range returns the range information of the generate_file block.
 func (h HCL) Range() info.Range {
 	return h.origin
@@ -141,11 +172,36 @@ func Header(comment CommentStyle) string {
 	return stdfmt.Sprintf("%s "+HeaderMagic+"\n\n", comment)
 }
 
+// HeaderWithChecksum returns the HCL header based on the comment style,
+// including the checksum of the given body.
+func HeaderWithChecksum(comment CommentStyle, body string) string {
+	return stdfmt.Sprintf("%s %s\n%s %s%s\n\n",
+		comment, HeaderMagic, comment, ChecksumMagic, Checksum(body))
+}
+
 // DefaultHeader returns the header for the default comment style.
 func DefaultHeader() string {
 	return Header(DefaultComment)
 }
 
+// Checksum returns the checksum of the given generated code body.
+func Checksum(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseChecksum parses the header of the given generated code, returning the
+// checksum stored on the header and the body of the code. It returns false if
+// the code has no header with a checksum, like code generated by older versions.
+func ParseChecksum(comment CommentStyle, code string) (checksum string, body string, ok bool) {
+	prefix := stdfmt.Sprintf("%s %s\n%s %s", comment, HeaderMagic, comment, ChecksumMagic)
+	if !strings.HasPrefix(code, prefix) {
+		return "", "", false
+	}
+	checksum, body, ok = strings.Cut(code[len(prefix):], "\n\n")
+	return checksum, body, ok
+}
+
 // commentStyleFromString returns the comment style given an string.
 func commentStyleFromString(str string) CommentStyle {
 	switch str {
@@ -196,139 +252,244 @@ func Load(
 
 	commentStyle := CommentStyleFromConfig(root.Tree())
 
-	var hcls []HCL
-	for _, hclBlock := range hclBlocks {
-		name := hclBlock.Label
-
-		matchedAnyStackFilter := len(hclBlock.StackFilters) == 0
-		for _, cond := range hclBlock.StackFilters {
-			matched := true
-
-			for n, globs := range map[string][]glob.Glob{
-				"project path":    cond.ProjectPaths,
-				"repository path": cond.RepositoryPaths,
-			} {
-				if globs != nil && !hcl.MatchAnyGlob(globs, st.Dir.String()) {
-					log.Logger.Trace().Msgf("Skipping %q, %s doesn't match any filter in %v", st.Dir, n, globs)
-					matched = false
-					break
-				}
-			}
-
-			matchedAnyStackFilter = matchedAnyStackFilter || matched
-		}
-
-		if !matchedAnyStackFilter {
-			hcls = append(hcls, HCL{
-				magicCommentStyle: commentStyle,
-				label:             name,
-				origin:            hclBlock.Range,
-				condition:         false,
-			})
+	// The blocks calling the provider functions are evaluated after the other
+	// blocks, so the functions see the up-to-date content of the generated
+	// files instead of their content on disk, which may be outdated.
+	hcls := make([]HCL, len(hclBlocks))
+	var deferred []int
+	for i, hclBlock := range hclBlocks {
+		called := false
+		gen, err := loadHCL(root, st, globals, vendorDir, vendorRequests,
+			commentStyle, hclBlock, deferredProvidersFuncs(&called))
+		if called {
+			deferred = append(deferred, i)
 			continue
 		}
-
-		evalctx := stack.NewEvalCtx(root, st, globals)
-
-		vendorTargetDir := project.NewPath(path.Join(
-			st.Dir.String(),
-			path.Dir(name)))
-
-		evalctx.SetFunction(
-			stdlib.Name("vendor"),
-			stdlib.VendorFunc(vendorTargetDir, vendorDir, vendorRequests),
-		)
-
-		err := lets.Load(hclBlock.Lets, evalctx.Context)
 		if err != nil {
 			return nil, err
 		}
+		hcls[i] = gen
+	}
 
-		condition := true
-		if hclBlock.Condition != nil {
-			value, err := evalctx.Eval(hclBlock.Condition.Expr)
-			if err != nil {
-				return nil, errors.E(ErrConditionEval, err)
+	if len(deferred) > 0 {
+		generated := map[string]string{}
+		for _, i := range deferred {
+			generated[hclBlocks[i].Label] = ""
+		}
+		for _, gen := range hcls {
+			if gen.Condition() {
+				generated[gen.Label()] = gen.Body()
+			} else if _, ok := generated[gen.Label()]; !ok && gen.Label() != "" {
+				generated[gen.Label()] = ""
 			}
-			if value.Type() != cty.Bool {
-				return nil, errors.E(
-					ErrInvalidConditionType,
-					"condition has type %s but must be boolean",
-					value.Type().FriendlyName(),
-				)
+		}
+		funcs := providersFuncs(st.HostDir(root), generated)
+		for _, i := range deferred {
+			gen, err := loadHCL(root, st, globals, vendorDir, vendorRequests,
+				commentStyle, hclBlocks[i], funcs)
+			if err != nil {
+				return nil, err
 			}
-			condition = value.True()
+			hcls[i] = gen
 		}
+	}
 
-		if !condition {
-			hcls = append(hcls, HCL{
-				magicCommentStyle: commentStyle,
-				label:             name,
-				origin:            hclBlock.Range,
-				condition:         condition,
-			})
-			continue
-		}
+	sort.SliceStable(hcls, func(i, j int) bool {
+		return hcls[i].Label() < hcls[j].Label()
+	})
 
-		asserts := make([]config.Assert, len(hclBlock.Asserts))
-		assertsErrs := errors.L()
-		assertFailed := false
+	return hcls, nil
+}
 
-		for i, assertCfg := range hclBlock.Asserts {
-			assert, err := config.EvalAssert(evalctx.Context, assertCfg)
-			if err != nil {
-				assertsErrs.Append(err)
-				continue
-			}
-			asserts[i] = assert
-			if !assert.Assertion && !assert.Warning {
-				assertFailed = true
-			}
-		}
+// loadHCL evaluates the generate_hcl block for the stack, using the given
+// implementations of the provider functions.
+func loadHCL(
+	root *config.Root,
+	st *config.Stack,
+	globals *eval.Object,
+	vendorDir project.Path,
+	vendorRequests chan<- event.VendorRequest,
+	commentStyle CommentStyle,
+	hclBlock hcl.GenHCLBlock,
+	funcs map[string]function.Function,
+) (HCL, error) {
+	name := hclBlock.Label
+
+	if !hcl.MatchStackFilters(hclBlock.StackFilters, st.Dir.String()) {
+		log.Logger.Trace().Msgf("Skipping %q, it doesn't match any stack_filter", st.Dir)
+		return HCL{
+			magicCommentStyle: commentStyle,
+			label:             name,
+			origin:            hclBlock.Range,
+			condition:         false,
+		}, nil
+	}
 
-		if err := assertsErrs.AsError(); err != nil {
-			return nil, err
-		}
+	evalctx := stack.NewEvalCtx(root, st, globals)
 
-		if assertFailed {
-			hcls = append(hcls, HCL{
-				magicCommentStyle: commentStyle,
-				label:             name,
-				origin:            hclBlock.Range,
-				condition:         condition,
-				asserts:           asserts,
-			})
-			continue
-		}
+	vendorTargetDir := project.NewPath(path.Join(
+		st.Dir.String(),
+		path.Dir(name)))
 
-		evalctx.SetFunction(stdlib.Name("hcl_expression"), stdlib.HCLExpressionFunc())
+	evalctx.SetFunction(
+		stdlib.Name("vendor"),
+		stdlib.VendorFunc(vendorTargetDir, vendorDir, vendorRequests),
+	)
+	for fname, fn := range funcs {
+		evalctx.SetFunction(fname, fn)
+	}
 
-		gen := hclwrite.NewEmptyFile()
-		if err := copyBody(gen.Body(), hclBlock.Content.Body, evalctx); err != nil {
-			return nil, errors.E(ErrContentEval, err, "generate_hcl %q", name)
+	err := lets.Load(hclBlock.Lets, evalctx.Context)
+	if err != nil {
+		return HCL{}, err
+	}
+
+	condition := true
+	if hclBlock.Condition != nil {
+		value, err := evalctx.Eval(hclBlock.Condition.Expr)
+		if err != nil {
+			return HCL{}, errors.E(ErrConditionEval, err)
 		}
+		value, _ = value.Unmark()
+		if value.Type() != cty.Bool {
+			return HCL{}, errors.E(
+				ErrInvalidConditionType,
+				"condition has type %s but must be boolean",
+				value.Type().FriendlyName(),
+			)
+		}
+		condition = value.True()
+	}
+
+	if !condition {
+		return HCL{
+			magicCommentStyle: commentStyle,
+			label:             name,
+			origin:            hclBlock.Range,
+			condition:         condition,
+		}, nil
+	}
 
-		formatted, err := fmt.FormatMultiline(string(gen.Bytes()), hclBlock.Range.HostPath())
+	asserts := make([]config.Assert, len(hclBlock.Asserts))
+	assertsErrs := errors.L()
+	assertFailed := false
+
+	for i, assertCfg := range hclBlock.Asserts {
+		assert, err := config.EvalAssert(evalctx.Context, assertCfg)
 		if err != nil {
-			panic(errors.E(err,
-				"internal error: formatting generated code for generate_hcl %q:%s", name, string(gen.Bytes()),
-			))
+			assertsErrs.Append(err)
+			continue
 		}
-		hcls = append(hcls, HCL{
+		asserts[i] = assert
+		if !assert.Assertion && !assert.Warning {
+			assertFailed = true
+		}
+	}
+
+	if err := assertsErrs.AsError(); err != nil {
+		return HCL{}, err
+	}
+
+	if assertFailed {
+		return HCL{
 			magicCommentStyle: commentStyle,
 			label:             name,
 			origin:            hclBlock.Range,
-			body:              formatted,
 			condition:         condition,
 			asserts:           asserts,
-		})
+		}, nil
 	}
 
-	sort.SliceStable(hcls, func(i, j int) bool {
-		return hcls[i].Label() < hcls[j].Label()
+	evalctx.SetFunction(stdlib.Name("hcl_expression"), stdlib.HCLExpressionFunc())
+
+	gen := hclwrite.NewEmptyFile()
+	evaluator := sensitiveEvaluator{
+		Evaluator: evalctx,
+		allow:     hclBlock.AllowSensitive,
+	}
+	if err := copyBody(gen.Body(), hclBlock.Content.Body, evaluator); err != nil {
+		return HCL{}, errors.E(ErrContentEval, err, "generate_hcl %q", name)
+	}
+
+	formatted, err := fmt.FormatMultiline(string(gen.Bytes()), hclBlock.Range.HostPath())
+	if err != nil {
+		panic(errors.E(err,
+			"internal error: formatting generated code for generate_hcl %q:%s", name, string(gen.Bytes()),
+		))
+	}
+	return HCL{
+		magicCommentStyle: commentStyle,
+		label:             name,
+		origin:            hclBlock.Range,
+		body:              formatted,
+		condition:         condition,
+		asserts:           asserts,
+	}, nil
+}
+
+// providersFuncs returns the provider functions which detect the providers
+// using the given content of the generated files.
+func providersFuncs(stackdir string, generated map[string]string) map[string]function.Function {
+	return map[string]function.Function{
+		stdlib.Name("terraform_required_providers"): stdlib.TerraformRequiredProvidersFunc(stackdir, generated),
+		stdlib.Name("terraform_lock_providers"):     stdlib.TerraformLockProvidersFunc(stackdir, generated),
+	}
+}
+
+// deferredProvidersFuncs returns provider functions which fail and set called
+// to true, so the block calling them is evaluated later.
+func deferredProvidersFuncs(called *bool) map[string]function.Function {
+	deferred := function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name: "args",
+			Type: cty.DynamicPseudoType,
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(_ []cty.Value, _ cty.Type) (cty.Value, error) {
+			*called = true
+			return cty.NilVal, errors.E(errors.ErrInternal, "provider functions evaluated before the generated files")
+		},
 	})
+	return map[string]function.Function{
+		stdlib.Name("terraform_required_providers"): deferred,
+		stdlib.Name("terraform_lock_providers"):     deferred,
+	}
+}
 
-	return hcls, nil
+// sensitiveEvaluator is an evaluator which fails if the expressions evaluate
+// to sensitive values, unless they are allowed, so they are never written to
+// the generated code by accident.
+type sensitiveEvaluator struct {
+	hcl.Evaluator
+
+	allow bool
+}
+
+func (e sensitiveEvaluator) Eval(expr hhcl.Expression) (cty.Value, error) {
+	val, err := e.Evaluator.Eval(expr)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if !e.allow && eval.IsSensitive(val) {
+		return cty.NilVal, errors.E(eval.ErrSensitive, expr.Range(),
+			"sensitive values are not allowed in generated code unless allow_sensitive = true")
+	}
+	val, _ = val.UnmarkDeep()
+	return val, nil
+}
+
+func (e sensitiveEvaluator) PartialEval(expr hhcl.Expression) (hhcl.Expression, error) {
+	newexpr, err := e.Evaluator.PartialEval(expr)
+	if err != nil {
+		return nil, err
+	}
+	if !e.allow {
+		if err := eval.CheckSensitive(newexpr); err != nil {
+			return nil, errors.E(err,
+				"sensitive values are not allowed in generated code unless allow_sensitive = true")
+		}
+	}
+	return newexpr, nil
 }
 
 type dynBlockAttributes struct {
@@ -1801,3 +1801,20 @@ func assertHCLEquals(t *testing.T, got string, want string) {
 func init() {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 }
+
+func TestHeaderWithChecksum(t *testing.T) {
+	t.Parallel()
+
+	for _, style := range []genhcl.CommentStyle{genhcl.SlashComment, genhcl.HashComment} {
+		const body = "a = 1\n\nb = 2\n"
+		code := genhcl.HeaderWithChecksum(style, body) + body
+
+		checksum, gotBody, ok := genhcl.ParseChecksum(style, code)
+		assert.IsTrue(t, ok, "checksum not found on header: %s", code)
+		assert.EqualStrings(t, genhcl.Checksum(body), checksum)
+		assert.EqualStrings(t, body, gotBody)
+
+		_, _, ok = genhcl.ParseChecksum(style, genhcl.Header(style)+body)
+		assert.IsTrue(t, !ok, "header without checksum must not be parsed")
+	}
+}
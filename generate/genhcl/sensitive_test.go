@@ -0,0 +1,150 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package genhcl_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/eval"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestGenerateHCLSensitive(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []testcase{
+		{
+			name:  "sensitive global is not generated",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Doc(
+						Globals(
+							Expr("password", `tm_sensitive("secret")`),
+						),
+						GenerateHCL(
+							Labels("test.tf"),
+							Content(
+								Expr("password", "global.password"),
+							),
+						),
+					),
+				},
+			},
+			wantErr: errors.E(eval.ErrSensitive),
+		},
+		{
+			name:  "value derived from sensitive global is not generated",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Doc(
+						Globals(
+							Expr("password", `tm_sensitive("secret")`),
+						),
+						GenerateHCL(
+							Labels("test.tf"),
+							Content(
+								Block("provider",
+									Labels("db"),
+									Str("dsn", "admin:${global.password}@localhost"),
+								),
+							),
+						),
+					),
+				},
+			},
+			wantErr: errors.E(eval.ErrSensitive),
+		},
+		{
+			name:  "allow_sensitive generates sensitive values",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Doc(
+						Globals(
+							Expr("password", `tm_sensitive("secret")`),
+							Str("user", "admin"),
+						),
+						GenerateHCL(
+							Labels("test.tf"),
+							Bool("allow_sensitive", true),
+							Content(
+								Str("dsn", "${global.user}:${global.password}@localhost"),
+								Expr("user", "global.user"),
+							),
+						),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "test.tf",
+					hcl: genHCL{
+						condition: true,
+						body: Doc(
+							Str("dsn", "admin:secret@localhost"),
+							Str("user", "admin"),
+						),
+					},
+				},
+			},
+		},
+		{
+			name:  "non-sensitive values of sensitive globals are generated",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Doc(
+						Globals(
+							Expr("password", `tm_sensitive("secret")`),
+						),
+						GenerateHCL(
+							Labels("test.tf"),
+							Content(
+								Expr("password", "tm_nonsensitive(global.password)"),
+							),
+						),
+					),
+				},
+			},
+			want: []result{
+				{
+					name: "test.tf",
+					hcl: genHCL{
+						condition: true,
+						body: Doc(
+							Str("password", "secret"),
+						),
+					},
+				},
+			},
+		},
+		{
+			name:  "allow_sensitive must be a literal bool",
+			stack: "/stack",
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: GenerateHCL(
+						Labels("test.tf"),
+						Expr("allow_sensitive", "global.allow"),
+						Content(
+							Str("a", "b"),
+						),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+	} {
+		tc.run(t)
+	}
+}
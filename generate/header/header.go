@@ -0,0 +1,34 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+// Package header defines the header of the HCL files generated by Terramate.
+// It has no dependencies, so the packages that must recognize the generated
+// files can use it without depending on the code generation.
+package header
+
+import "strings"
+
+const (
+	// Magic is the current header magic string used by generate_hcl code generation.
+	Magic = "TERRAMATE: GENERATED AUTOMATICALLY DO NOT EDIT"
+
+	// V0 is the deprecated header string used by generate_hcl code generation.
+	V0 = "// GENERATED BY TERRAMATE: DO NOT EDIT"
+)
+
+// IsGenerated tells if the content starts with the header of the files
+// generated by Terramate, using any of the supported comment styles.
+func IsGenerated(content string) bool {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	firstLine = strings.TrimSpace(firstLine)
+	if firstLine == V0 {
+		return true
+	}
+	for _, comment := range []string{"//", "#"} {
+		magic, ok := strings.CutPrefix(firstLine, comment)
+		if ok && strings.TrimSpace(magic) == Magic {
+			return true
+		}
+	}
+	return false
+}
@@ -1206,6 +1206,151 @@ func TestOutdatedDetection(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "editing template files outdates generated files",
+			steps: []step{
+				{
+					layout: []string{
+						"s:stack",
+						"f:templates/file.tpl:stack ${terramate.stack.name} v1",
+					},
+					files: []file{
+						{
+							path: "config.tm",
+							body: GenerateFile(
+								Labels("test.txt"),
+								Str("template_file", "/templates/file.tpl"),
+							),
+						},
+					},
+					want: []string{
+						"stack/test.txt",
+					},
+				},
+				{
+					layout: []string{
+						"f:templates/file.tpl:stack ${terramate.stack.name} v2",
+					},
+					want: []string{
+						"stack/test.txt",
+					},
+				},
+			},
+		},
+		{
+			name: "editing globals data files outdates generated files",
+			steps: []step{
+				{
+					layout: []string{
+						"s:stack",
+						"f:data/accounts.yaml:account: \"111\"",
+					},
+					files: []file{
+						{
+							path: "config.tm",
+							body: Doc(
+								Globals(
+									Block("data_file",
+										Str("path", "/data/accounts.yaml"),
+									),
+								),
+								GenerateFile(
+									Labels("account.txt"),
+									Expr("content", "global.account"),
+								),
+							),
+						},
+					},
+					want: []string{
+						"stack/account.txt",
+					},
+				},
+				{
+					layout: []string{
+						"f:data/accounts.yaml:account: \"222\"",
+					},
+					want: []string{
+						"stack/account.txt",
+					},
+				},
+			},
+		},
+		{
+			name: "file_mode and symlink target changes",
+			steps: []step{
+				{
+					layout: []string{
+						"s:stack",
+						"f:shared/file.txt:shared",
+					},
+					files: []file{
+						{
+							path: "stack/config.tm",
+							body: Doc(
+								GenerateFile(
+									Labels("script.sh"),
+									Str("content", "echo hello"),
+									Str("file_mode", "0755"),
+								),
+								Block("generate_symlink",
+									Labels("file.txt"),
+									Str("target", "../shared/file.txt"),
+								),
+							),
+						},
+					},
+					want: []string{
+						"stack/file.txt",
+						"stack/script.sh",
+					},
+				},
+				{
+					files: []file{
+						{
+							path: "stack/config.tm",
+							body: Doc(
+								GenerateFile(
+									Labels("script.sh"),
+									Str("content", "echo hello"),
+									Str("file_mode", "0700"),
+								),
+								Block("generate_symlink",
+									Labels("file.txt"),
+									Str("target", "../shared/file.txt"),
+								),
+							),
+						},
+					},
+					want: []string{
+						"stack/script.sh",
+					},
+				},
+				{
+					layout: []string{
+						"f:shared/other.txt:other",
+					},
+					files: []file{
+						{
+							path: "stack/config.tm",
+							body: Doc(
+								GenerateFile(
+									Labels("script.sh"),
+									Str("content", "echo hello"),
+									Str("file_mode", "0700"),
+								),
+								Block("generate_symlink",
+									Labels("file.txt"),
+									Str("target", "../shared/other.txt"),
+								),
+							),
+						},
+					},
+					want: []string{
+						"stack/file.txt",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tcases {
@@ -0,0 +1,350 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package generate
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Kinds of provenance entries.
+const (
+	// ProvenanceAttribute is the kind of entries representing top-level attributes.
+	ProvenanceAttribute = "attribute"
+	// ProvenanceBlock is the kind of entries representing top-level blocks.
+	ProvenanceBlock = "block"
+)
+
+type (
+	// ProvenanceResult represents the provenance of all generated files of a
+	// single directory.
+	ProvenanceResult struct {
+		// Dir is the directory of the generated files, or where a failure
+		// occurred if Err is not nil.
+		Dir project.Path `json:"dir"`
+		// Files is the provenance of each generated file of the directory.
+		Files []FileProvenance `json:"files"`
+		// Err will be non-nil if loading the provenance for the dir failed.
+		Err error `json:"-"`
+	}
+
+	// FileProvenance describes where the content of a generated file comes from.
+	FileProvenance struct {
+		// File is the project path of the generated file.
+		File project.Path `json:"file"`
+		// Origin is the range of the generate block that generated the file.
+		Origin info.Range `json:"origin"`
+		// Entries are the top-level blocks and attributes of the generated file.
+		// For generate_file blocks there's a single entry for the content attribute.
+		Entries []ProvenanceEntry `json:"entries"`
+	}
+
+	// ProvenanceEntry describes where a top-level block or attribute of a
+	// generated file is defined.
+	ProvenanceEntry struct {
+		// Kind is either [ProvenanceAttribute] or [ProvenanceBlock].
+		Kind string `json:"kind"`
+		// Name is the attribute name or the block type.
+		Name string `json:"name"`
+		// Labels are the block labels, if any.
+		Labels []string `json:"labels,omitempty"`
+		// Range is the range of the attribute or block definition.
+		Range info.Range `json:"range"`
+		// Globals are the globals referenced to compute the entry, including
+		// the ones referenced indirectly through lets and other globals.
+		Globals []GlobalProvenance `json:"globals,omitempty"`
+	}
+
+	// GlobalProvenance describes where a referenced global is defined.
+	GlobalProvenance struct {
+		// Path is the global accessor, like global.a.b.
+		Path string `json:"path"`
+		// Dir is the directory where the global is instantiated.
+		Dir project.Path `json:"dir"`
+		// Range is the range of the global definition.
+		Range info.Range `json:"range"`
+	}
+)
+
+// LoadProvenance loads the provenance of all generated files inside the given
+// tree, which maps each generated file and each of its top-level blocks and
+// attributes to the configuration that defines it.
+// Only files whose condition evaluates to true are included.
+//
+// The errors are handled the same way as in [Load].
+func LoadProvenance(root *config.Root, vendorDir project.Path) ([]ProvenanceResult, error) {
+	loadResults, err := Load(root, vendorDir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ProvenanceResult, 0, len(loadResults))
+	for _, loadres := range loadResults {
+		res := ProvenanceResult{Dir: loadres.Dir}
+		if loadres.Err != nil {
+			res.Err = loadres.Err
+			results = append(results, res)
+			continue
+		}
+
+		cfg, _ := root.Lookup(loadres.Dir)
+
+		var stackReport *globals.EvalReport
+		if cfg.IsStack() {
+			st, err := cfg.Stack()
+			if err != nil {
+				res.Err = err
+				results = append(results, res)
+				continue
+			}
+			report := globals.ForStack(root, st)
+			if err := report.AsError(); err != nil {
+				res.Err = err
+				results = append(results, res)
+				continue
+			}
+			stackReport = &report
+		}
+
+		for _, file := range loadres.Files {
+			if !file.Condition() {
+				continue
+			}
+			prov, err := fileProvenance(root, loadres.Dir, file, stackReport)
+			if err != nil {
+				res.Err = errors.L(res.Err, err).AsError()
+				continue
+			}
+			res.Files = append(res.Files, prov)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func fileProvenance(
+	root *config.Root,
+	dir project.Path,
+	file GenFile,
+	report *globals.EvalReport,
+) (FileProvenance, error) {
+	prov := FileProvenance{
+		File:   project.NewPath(path.Join(dir.String(), file.Label())),
+		Origin: file.Range(),
+	}
+
+	for cfgdir := dir; ; cfgdir = cfgdir.Dir() {
+		cfg, ok := root.Lookup(cfgdir)
+		if ok && !cfg.IsEmptyConfig() {
+			for _, block := range cfg.Node.Generate.HCLs {
+				if block.Range == file.Range() && block.Label == file.Label() {
+					prov.Entries = genHCLEntries(root.HostDir(), block, report)
+					return prov, nil
+				}
+			}
+			for _, block := range cfg.Node.Generate.Files {
+				if block.Range == file.Range() && block.Label == file.Label() {
+					prov.Entries = genFileEntries(root.HostDir(), block, report)
+					return prov, nil
+				}
+			}
+			for _, block := range cfg.Node.Generate.Symlinks {
+				if block.Range == file.Range() && block.Label == file.Label() {
+					prov.Entries = []ProvenanceEntry{
+						attributeEntry(root.HostDir(), block.Target, block.Lets, report),
+					}
+					return prov, nil
+				}
+			}
+		}
+		if cfgdir == cfgdir.Dir() {
+			break
+		}
+	}
+	return FileProvenance{}, errors.E(
+		"generate block for %s defined at %s not found", prov.File, file.Range())
+}
+
+func genHCLEntries(rootdir string, block hcl.GenHCLBlock, report *globals.EvalReport) []ProvenanceEntry {
+	var entries []ProvenanceEntry
+
+	for _, attr := range block.Content.Body.Attributes {
+		entries = append(entries, ProvenanceEntry{
+			Kind:    ProvenanceAttribute,
+			Name:    attr.Name,
+			Range:   info.NewRange(rootdir, attr.Range()),
+			Globals: referencedGlobals([]hclsyntax.Node{attr}, block.Lets, report),
+		})
+	}
+	for _, subblock := range block.Content.Body.Blocks {
+		entry := ProvenanceEntry{
+			Kind:    ProvenanceBlock,
+			Name:    subblock.Type,
+			Labels:  subblock.Labels,
+			Range:   info.NewRange(rootdir, subblock.Range()),
+			Globals: referencedGlobals([]hclsyntax.Node{subblock}, block.Lets, report),
+		}
+		if subblock.Type == "tm_dynamic" && len(subblock.Labels) == 1 {
+			entry.Name = subblock.Labels[0]
+			entry.Labels = nil
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Range.Start().Byte() < entries[j].Range.Start().Byte()
+	})
+	return entries
+}
+
+func genFileEntries(rootdir string, block hcl.GenFileBlock, report *globals.EvalReport) []ProvenanceEntry {
+	if block.TemplateFile == nil {
+		content := block.Content
+		if content == nil {
+			content = block.ContentBase64
+		}
+		return []ProvenanceEntry{attributeEntry(rootdir, content, block.Lets, report)}
+	}
+
+	tmpl := block.TemplateFile
+	nodes := []hclsyntax.Node{tmpl.Attr}
+	if tmpl.Vars != nil {
+		nodes = append(nodes, tmpl.Vars)
+	}
+	// errors are ignored here because the template was already successfully
+	// rendered when the file was loaded.
+	if src, err := os.ReadFile(tmpl.HostPath); err == nil {
+		if expr, diags := hclsyntax.ParseTemplate(src, tmpl.HostPath, hhcl.InitialPos); !diags.HasErrors() {
+			nodes = append(nodes, expr)
+		}
+	}
+	return []ProvenanceEntry{
+		{
+			Kind:    ProvenanceAttribute,
+			Name:    tmpl.Attr.Name,
+			Range:   info.NewRange(rootdir, tmpl.Attr.Range()),
+			Globals: referencedGlobals(nodes, block.Lets, report),
+		},
+	}
+}
+
+func attributeEntry(rootdir string, attr *hclsyntax.Attribute, lets *ast.MergedBlock, report *globals.EvalReport) ProvenanceEntry {
+	return ProvenanceEntry{
+		Kind:    ProvenanceAttribute,
+		Name:    attr.Name,
+		Range:   info.NewRange(rootdir, attr.Range()),
+		Globals: referencedGlobals([]hclsyntax.Node{attr}, lets, report),
+	}
+}
+
+// referencedGlobals returns the globals referenced by the given nodes, following
+// references to lets and the references of the referenced globals to other
+// globals. The globals are sorted by path and never repeated.
+func referencedGlobals(nodes []hclsyntax.Node, lets *ast.MergedBlock, report *globals.EvalReport) []GlobalProvenance {
+	if report == nil {
+		return nil
+	}
+
+	found := map[string]GlobalProvenance{}
+	visitedLets := map[string]struct{}{}
+
+	var visit func(node hclsyntax.Node)
+	visit = func(node hclsyntax.Node) {
+		_ = hclsyntax.VisitAll(node, func(n hclsyntax.Node) hhcl.Diagnostics {
+			expr, ok := n.(*hclsyntax.ScopeTraversalExpr)
+			if !ok {
+				return nil
+			}
+			switch expr.Traversal.RootName() {
+			case "global":
+				objpath := traversalPath(expr.Traversal)
+				for len(objpath) > 0 {
+					val, ok := report.Globals.GetKeyPath(objpath)
+					if !ok {
+						objpath = objpath[:len(objpath)-1]
+						continue
+					}
+					accessor := "global." + strings.Join(objpath, ".")
+					if _, ok := found[accessor]; ok {
+						break
+					}
+					found[accessor] = GlobalProvenance{
+						Path:  accessor,
+						Dir:   val.Info().ConfigDir,
+						Range: val.Info().Range,
+					}
+					if globalExpr, ok := report.Expr(val.Info().Range); ok {
+						if expr, ok := globalExpr.(hclsyntax.Expression); ok {
+							visit(expr)
+						}
+					}
+					break
+				}
+			case "let":
+				objpath := traversalPath(expr.Traversal)
+				if len(objpath) == 0 || lets == nil {
+					return nil
+				}
+				if _, ok := visitedLets[objpath[0]]; ok {
+					return nil
+				}
+				visitedLets[objpath[0]] = struct{}{}
+				attr, ok := lets.Attributes[objpath[0]]
+				if !ok {
+					return nil
+				}
+				if letExpr, ok := attr.Expr.(hclsyntax.Expression); ok {
+					visit(letExpr)
+				}
+			}
+			return nil
+		})
+	}
+	for _, node := range nodes {
+		visit(node)
+	}
+
+	res := make([]GlobalProvenance, 0, len(found))
+	for _, g := range found {
+		res = append(res, g)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+// traversalPath returns the object path of the traversal, without the root
+// name. The path stops at the first step that is not a static key.
+func traversalPath(traversal hhcl.Traversal) eval.ObjectPath {
+	var objpath eval.ObjectPath
+	for _, step := range traversal[1:] {
+		switch s := step.(type) {
+		case hhcl.TraverseAttr:
+			objpath = append(objpath, s.Name)
+		case hhcl.TraverseIndex:
+			if s.Key.Type() != cty.String || !s.Key.IsKnown() {
+				return objpath
+			}
+			objpath = append(objpath, s.Key.AsString())
+		default:
+			return objpath
+		}
+	}
+	return objpath
+}
@@ -0,0 +1,287 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package generate_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/generate"
+	"github.com/terramate-io/terramate/project"
+	. "github.com/terramate-io/terramate/test/hclutils"
+	. "github.com/terramate-io/terramate/test/hclutils/info"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestLoadProvenance(t *testing.T) {
+	t.Parallel()
+	type (
+		file struct {
+			path string
+			body fmt.Stringer
+		}
+		testcase struct {
+			name    string
+			layout  []string
+			configs []file
+			want    []generate.ProvenanceResult
+		}
+	)
+
+	tcases := []testcase{
+		{
+			name: "no generate blocks",
+			layout: []string{
+				"s:stack",
+			},
+			want: []generate.ProvenanceResult{
+				{
+					Dir: project.NewPath("/stack"),
+				},
+			},
+		},
+		{
+			name: "generate_hcl entries with globals referenced directly and through lets",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []file{
+				{
+					path: "globals.tm",
+					body: Globals(
+						Str("region", "eu"),
+						Str("bucket", "root"),
+					),
+				},
+				{
+					path: "stack/globals.tm",
+					body: Globals(
+						Str("bucket", "stack"),
+					),
+				},
+				{
+					path: "gen.tm",
+					body: GenerateHCL(
+						Labels("backend.tf"),
+						Lets(
+							Expr("name", `"${global.bucket}-state"`),
+						),
+						Content(
+							Str("a", "b"),
+							Block("terraform",
+								Expr("bucket", "let.name"),
+							),
+							Expr("region", "global.region"),
+						),
+					),
+				},
+			},
+			want: []generate.ProvenanceResult{
+				{
+					Dir: project.NewPath("/stack"),
+					Files: []generate.FileProvenance{
+						{
+							File:   project.NewPath("/stack/backend.tf"),
+							Origin: Range("gen.tm", Start(1, 1, 0), End(12, 2, 179)),
+							Entries: []generate.ProvenanceEntry{
+								{
+									Kind:  generate.ProvenanceAttribute,
+									Name:  "a",
+									Range: Range("gen.tm", Start(6, 5, 93), End(6, 12, 100)),
+								},
+								{
+									Kind:  generate.ProvenanceBlock,
+									Name:  "terraform",
+									Range: Range("gen.tm", Start(7, 5, 105), End(9, 6, 146)),
+									Globals: []generate.GlobalProvenance{
+										{
+											Path:  "global.bucket",
+											Dir:   project.NewPath("/stack"),
+											Range: Range("stack/globals.tm", Start(2, 3, 12), End(2, 19, 28)),
+										},
+									},
+								},
+								{
+									Kind:  generate.ProvenanceAttribute,
+									Name:  "region",
+									Range: Range("gen.tm", Start(10, 5, 151), End(10, 27, 173)),
+									Globals: []generate.GlobalProvenance{
+										{
+											Path:  "global.region",
+											Dir:   project.NewPath("/"),
+											Range: Range("globals.tm", Start(2, 3, 12), End(2, 16, 25)),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "globals referenced through other globals",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []file{
+				{
+					path: "globals.tm",
+					body: Globals(
+						Str("region", "eu"),
+						Expr("prefix", `"${global.region}-app"`),
+						Expr("bucket", "global.prefix"),
+						Str("unused", "x"),
+					),
+				},
+				{
+					path: "gen.tm",
+					body: GenerateHCL(
+						Labels("backend.tf"),
+						Content(
+							Expr("bucket", "global.bucket"),
+						),
+					),
+				},
+			},
+			want: []generate.ProvenanceResult{
+				{
+					Dir: project.NewPath("/stack"),
+					Files: []generate.FileProvenance{
+						{
+							File:   project.NewPath("/stack/backend.tf"),
+							Origin: Range("gen.tm", Start(1, 1, 0), End(5, 2, 72)),
+							Entries: []generate.ProvenanceEntry{
+								{
+									Kind:  generate.ProvenanceAttribute,
+									Name:  "bucket",
+									Range: Range("gen.tm", Start(3, 5, 44), End(3, 27, 66)),
+									Globals: []generate.GlobalProvenance{
+										{
+											Path:  "global.bucket",
+											Dir:   project.NewPath("/"),
+											Range: Range("globals.tm", Start(4, 3, 62), End(4, 25, 84)),
+										},
+										{
+											Path:  "global.prefix",
+											Dir:   project.NewPath("/"),
+											Range: Range("globals.tm", Start(3, 3, 28), End(3, 34, 59)),
+										},
+										{
+											Path:  "global.region",
+											Dir:   project.NewPath("/"),
+											Range: Range("globals.tm", Start(2, 3, 12), End(2, 16, 25)),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "generate_file content entry",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []file{
+				{
+					path: "globals.tm",
+					body: Globals(
+						Expr("obj", `{ a = "b" }`),
+					),
+				},
+				{
+					path: "gen.tm",
+					body: GenerateFile(
+						Labels("file.txt"),
+						Expr("content", `global.obj.a`),
+					),
+				},
+			},
+			want: []generate.ProvenanceResult{
+				{
+					Dir: project.NewPath("/stack"),
+					Files: []generate.FileProvenance{
+						{
+							File:   project.NewPath("/stack/file.txt"),
+							Origin: Range("gen.tm", Start(1, 1, 0), End(3, 2, 53)),
+							Entries: []generate.ProvenanceEntry{
+								{
+									Kind:  generate.ProvenanceAttribute,
+									Name:  "content",
+									Range: Range("gen.tm", Start(2, 3, 29), End(2, 25, 51)),
+									Globals: []generate.GlobalProvenance{
+										{
+											Path:  "global.obj.a",
+											Dir:   project.NewPath("/"),
+											Range: Range("globals.tm", Start(2, 3, 12), End(2, 20, 29)),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "files with false condition are ignored",
+			layout: []string{
+				"s:stack",
+			},
+			configs: []file{
+				{
+					path: "gen.tm",
+					body: GenerateFile(
+						Labels("file.txt"),
+						Bool("condition", false),
+						Str("content", "data"),
+					),
+				},
+			},
+			want: []generate.ProvenanceResult{
+				{
+					Dir: project.NewPath("/stack"),
+				},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tcase := tc
+		t.Run(tcase.name, func(t *testing.T) {
+			t.Parallel()
+			s := sandbox.NoGit(t, true)
+			s.BuildTree(tcase.layout)
+			root := s.RootEntry()
+			for _, cfg := range tcase.configs {
+				root.CreateFile(cfg.path, cfg.body.String())
+			}
+
+			got, err := generate.LoadProvenance(s.Config(), project.NewPath("/modules"))
+			assert.NoError(t, err)
+
+			for _, res := range got {
+				assert.NoError(t, res.Err)
+			}
+
+			// The JSON representation doesn't include host paths, which are
+			// only known at runtime.
+			gotJSON, err := json.MarshalIndent(got, "", "  ")
+			assert.NoError(t, err)
+			wantJSON, err := json.MarshalIndent(tcase.want, "", "  ")
+			assert.NoError(t, err)
+
+			if diff := cmp.Diff(string(wantJSON), string(gotJSON)); diff != "" {
+				t.Fatalf("-(want) +(got):\n%s", diff)
+			}
+		})
+	}
+}
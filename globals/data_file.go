@@ -0,0 +1,107 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals
+
+import (
+	"os"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ErrDataFile indicates that a globals data file can't be loaded.
+const ErrDataFile errors.Kind = "invalid globals data file"
+
+// loadDataFile loads the top-level keys of the data file as global values.
+func loadDataFile(datafile hcl.GlobalsDataFile) (map[string]cty.Value, error) {
+	content, err := os.ReadFile(datafile.HostPath)
+	if err != nil {
+		return nil, errors.E(ErrDataFile, datafile.Range, err,
+			"reading globals data file %s", datafile.Path)
+	}
+
+	var val cty.Value
+	switch datafile.Format {
+	case hcl.DataFileJSON:
+		typ, err := ctyjson.ImpliedType(content)
+		if err == nil {
+			val, err = ctyjson.Unmarshal(content, typ)
+		}
+		if err != nil {
+			return nil, errors.E(ErrDataFile, datafile.Range, err,
+				"decoding JSON globals data file %s", datafile.Path)
+		}
+	case hcl.DataFileYAML:
+		val, err = ctyyaml.Standard.Unmarshal(content, cty.DynamicPseudoType)
+		if err != nil {
+			return nil, errors.E(ErrDataFile, datafile.Range, err,
+				"decoding YAML globals data file %s", datafile.Path)
+		}
+	case hcl.DataFileTFVars:
+		return loadTFVars(datafile, content)
+	default:
+		panic(errors.E(errors.ErrInternal, "unexpected data file format %q", datafile.Format))
+	}
+
+	if val.IsNull() || !(val.Type().IsObjectType() || val.Type().IsMapType()) {
+		return nil, errors.E(ErrDataFile, datafile.Range,
+			"globals data file %s must contain an object but has type %s",
+			datafile.Path, val.Type().FriendlyName())
+	}
+	return val.AsValueMap(), nil
+}
+
+func loadTFVars(datafile hcl.GlobalsDataFile, content []byte) (map[string]cty.Value, error) {
+	file, diags := hclsyntax.ParseConfig(content, datafile.HostPath, hhcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, errors.E(ErrDataFile, diags,
+			"parsing tfvars globals data file %s", datafile.Path)
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, errors.E(ErrDataFile, diags,
+			"tfvars globals data file %s must only contain attributes", datafile.Path)
+	}
+
+	res := map[string]cty.Value{}
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, errors.E(ErrDataFile, diags,
+				"tfvars globals data file %s must only contain literal values", datafile.Path)
+		}
+		res[name] = val
+	}
+	return res, nil
+}
+
+// dataFileExprs returns the global expressions for the values of the data
+// file of the tree. The origin of all expressions is the data_file block.
+func dataFileExprs(tree *config.Tree, datafile hcl.GlobalsDataFile) (map[GlobalPathKey]Expr, error) {
+	values, err := loadMemoizedDataFile(tree, datafile)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[GlobalPathKey]Expr{}
+	for name, val := range values {
+		key := NewGlobalAttrPath(datafile.Labels, name)
+		res[key] = Expr{
+			Origin:    datafile.Range,
+			ConfigDir: tree.Dir(),
+			LabelPath: key.Path(),
+			Expression: &hclsyntax.LiteralValueExpr{
+				Val:      val,
+				SrcRange: datafile.Range.ToHCLRange(),
+			},
+		}
+	}
+	return res, nil
+}
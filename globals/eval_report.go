@@ -21,6 +21,10 @@ type (
 
 		// Errors is a map of errors for each global.
 		Errors map[GlobalPathKey]EvalError // map of GlobalPath to its EvalError.
+
+		// exprs are the evaluated expressions, used to track overridden
+		// definitions.
+		exprs HierarchicalExprs
 	}
 
 	// EvalError carries the error and the expression which resulted in it.
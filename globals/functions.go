@@ -0,0 +1,103 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals
+
+import (
+	"path"
+	"strings"
+
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// StackGlobalsFuncName is the name of the function which returns the globals
+// of other stacks.
+const StackGlobalsFuncName = "tm_stack_globals"
+
+// ErrStackGlobalsCycle indicates that the globals of stacks depend on each
+// other through tm_stack_globals calls.
+const ErrStackGlobalsCycle errors.Kind = "cycle evaluating stack globals"
+
+// SetFunctions sets the project functions on the evaluation context.
+// These are the functions set by [config.Root.SetFunctions] and the
+// tm_stack_globals function.
+func SetFunctions(root *config.Root, ctx *eval.Context) {
+	setFunctions(root, ctx, nil)
+}
+
+// setFunctions sets the project functions on the context. The chain is the
+// list of stacks whose globals are being evaluated, used to detect cycles.
+func setFunctions(root *config.Root, ctx *eval.Context, chain []project.Path) {
+	root.SetFunctions(ctx)
+	ctx.SetFunction(StackGlobalsFuncName, stackGlobalsFunc(root, chain))
+}
+
+// stackGlobalsFunc returns the tm_stack_globals function, which evaluates the
+// globals of the stack at the absolute project path and returns the global
+// with the given name. The name can be a dotted path to a nested global
+// attribute, like "vpc.id".
+func stackGlobalsFunc(root *config.Root, chain []project.Path) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name: "name",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			pathstr := args[0].AsString()
+			if !path.IsAbs(pathstr) {
+				return cty.NilVal, function.NewArgErrorf(0,
+					"stack path must be an absolute project path but got %q", pathstr)
+			}
+			stackdir := project.NewPath(pathstr)
+
+			for i, dir := range chain {
+				if dir == stackdir {
+					cycle := append(chain[i:len(chain):len(chain)], stackdir)
+					return cty.NilVal, errors.E(ErrStackGlobalsCycle,
+						"%s", strings.Join(project.Paths(cycle).Strings(), " -> "))
+				}
+			}
+
+			tree, ok := root.Lookup(stackdir)
+			if !ok || !tree.IsStack() {
+				return cty.NilVal, function.NewArgErrorf(0, "%s is not a stack", stackdir)
+			}
+			st, err := tree.Stack()
+			if err != nil {
+				return cty.NilVal, errors.E(err, "loading stack %s", stackdir)
+			}
+
+			report := forStack(root, st, chain)
+			if err := report.AsError(); err != nil {
+				return cty.NilVal, errors.E(err, "evaluating globals of stack %s", stackdir)
+			}
+
+			name := args[1].AsString()
+			val, ok := report.Globals.GetKeyPath(strings.Split(name, "."))
+			if !ok {
+				return cty.NilVal, function.NewArgErrorf(1,
+					"global.%s is not defined in stack %s", name, stackdir)
+			}
+			switch v := val.(type) {
+			case *eval.Object:
+				return cty.ObjectVal(v.AsValueMap()), nil
+			case eval.CtyValue:
+				return v.Raw(), nil
+			default:
+				panic("unreachable")
+			}
+		},
+	})
+}
@@ -23,8 +23,10 @@ import (
 
 // Errors returned when parsing and evaluating globals.
 const (
-	ErrEval      errors.Kind = "global eval"
-	ErrRedefined errors.Kind = "global redefined"
+	ErrEval          errors.Kind = "global eval"
+	ErrRedefined     errors.Kind = "global redefined"
+	ErrOverrideFinal errors.Kind = "final global overridden"
+	ErrMergeStrategy errors.Kind = "global merge strategy"
 )
 
 type (
@@ -41,6 +43,17 @@ type (
 		LabelPath eval.ObjectPath
 
 		hhcl.Expression
+
+		// isDefault tells if the expression is the default of a globals
+		// schema, which must never override other definitions.
+		isDefault bool
+
+		// strategy is how the expression is combined with the definitions
+		// of the same global in parent directories.
+		strategy hcl.MergeStrategy
+
+		// memo memoizes the value of the expression, if set.
+		memo *exprMemo
 	}
 
 	// GlobalPathKey represents a global object accessor to be used as map key.
@@ -94,6 +107,8 @@ func ForDir(root *config.Root, cfgdir project.Path, ctx *eval.Context) EvalRepor
 type ExprSet struct {
 	origin      project.Path
 	expressions map[GlobalPathKey]Expr
+	schemas     []hcl.GlobalSchema
+	isStack     bool
 }
 
 // HierarchicalExprs contains all loaded global expressions from multiple
@@ -115,6 +130,9 @@ func newExprSet(origin project.Path) *ExprSet {
 // specific globals (closer or at the root dir).
 func LoadExprs(tree *config.Tree) (HierarchicalExprs, error) {
 	exprs := newExprSet(tree.Dir())
+	exprs.schemas = tree.Node.GlobalsSchemas
+	exprs.isStack = tree.IsStack()
+	memo := newExprMemo(tree)
 
 	globalsBlocks := tree.Node.Globals.AsList()
 	for _, block := range globalsBlocks {
@@ -127,6 +145,7 @@ func LoadExprs(tree *config.Tree) (HierarchicalExprs, error) {
 		}
 
 		attrs := block.Attributes.SortedList()
+		strategies := hcl.GlobalsMergeStrategies(block)
 		if len(block.Labels) > 0 && len(attrs) == 0 {
 			expr := &hclsyntax.ObjectConsExpr{
 				SrcRange: block.RawOrigins[0].Range.ToHCLRange(),
@@ -141,6 +160,10 @@ func LoadExprs(tree *config.Tree) (HierarchicalExprs, error) {
 		}
 
 		for _, varsBlock := range block.Blocks {
+			if varsBlock.Type == hcl.GlobalsDataFileBlockType ||
+				varsBlock.Type == hcl.GlobalsMergeStrategyBlockType {
+				continue
+			}
 			varName := varsBlock.Labels[0]
 			if _, ok := block.Attributes[varName]; ok {
 				return HierarchicalExprs{}, errors.E(
@@ -157,6 +180,7 @@ func LoadExprs(tree *config.Tree) (HierarchicalExprs, error) {
 				Origin:     varsBlock.RawOrigins[0].Range,
 				LabelPath:  key.Path(),
 				Expression: expr,
+				strategy:   strategies[varName],
 			}
 		}
 
@@ -168,10 +192,28 @@ func LoadExprs(tree *config.Tree) (HierarchicalExprs, error) {
 				ConfigDir:  tree.Dir(),
 				LabelPath:  key.Path(),
 				Expression: attr.Expr,
+				strategy:   strategies[attr.Name],
+				memo:       memo,
 			}
 		}
 	}
 
+	for _, datafile := range tree.Node.GlobalsDataFiles {
+		dataExprs, err := dataFileExprs(tree, datafile)
+		if err != nil {
+			return nil, err
+		}
+		for key, expr := range dataExprs {
+			if other, ok := exprs.expressions[key]; ok {
+				return nil, errors.E(
+					ErrRedefined, datafile.Range,
+					"global.%s from data file %s conflicts with the definition at %s",
+					key.name(), datafile.Path, other.Origin.String())
+			}
+			exprs.expressions[key] = expr
+		}
+	}
+
 	globals := HierarchicalExprs{
 		tree.Dir(): exprs,
 	}
@@ -259,6 +301,7 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 		Logger()
 
 	report := NewEvalReport()
+	report.exprs = dirExprs
 	globals := report.Globals
 	pendingExprsErrs := map[GlobalPathKey]*errors.List{}
 
@@ -268,10 +311,52 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 	// Here we will override values, but since
 	// we ordered by config dir the more specific global expressions
 	// will override the parent ones.
+	// final globals only restrict the definitions of child directories.
+	finals := map[GlobalPathKey]Expr{}
 	for _, xp := range sortedLoadedExprs {
-		for k, v := range xp.expressions {
+		dirFinals := map[GlobalPathKey]Expr{}
+		for _, k := range xp.sort() {
+			v := xp.expressions[k]
+			if final, ok := overriddenFinal(finals, k); ok {
+				report.Errors[k] = EvalError{
+					Expr: v,
+					Err: errors.E(ErrEval, errors.E(ErrOverrideFinal, v.Range(),
+						"global.%s overrides global.%s which is final at %s",
+						k.name(), strings.Join(final.LabelPath, "."), final.Origin.String())),
+				}
+				continue
+			}
+
+			switch v.strategy {
+			case hcl.MergeStrategyDeepMerge, hcl.MergeStrategyAppend:
+				if base, ok := pendingExprs[k]; ok {
+					v.Expression = &mergeExpr{
+						strategy:   v.strategy,
+						base:       base.Expression,
+						Expression: v.Expression,
+					}
+					v.memo = nil
+				}
+			case hcl.MergeStrategyFinal:
+				dirFinals[k] = v
+			}
 			pendingExprs[k] = v
 		}
+		for k, v := range dirFinals {
+			finals[k] = v
+		}
+	}
+
+	// the definitions are kept for reporting schema errors.
+	definitions := make(map[GlobalPathKey]Expr, len(pendingExprs))
+	for k, v := range pendingExprs {
+		definitions[k] = v
+	}
+
+	schemas := dirExprs.schemas()
+	defaults := schemas.defaults(pendingExprs)
+	for k, v := range defaults {
+		pendingExprs[k] = v
 	}
 
 	// Here we will sort each set of globals from each dir independently
@@ -284,6 +369,15 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 		accessors []GlobalPathKey
 	}
 	sortedGlobalAccessors := []globalAccessors{}
+	if len(defaults) > 0 {
+		// the schema defaults are postponed until all the expressions of
+		// the hierarchy they could depend on are evaluated, which is why
+		// they have the most specific origin.
+		sortedGlobalAccessors = append(sortedGlobalAccessors, globalAccessors{
+			origin:    sortedLoadedExprs[len(sortedLoadedExprs)-1].origin,
+			accessors: ExprSet{expressions: defaults}.sort(),
+		})
+	}
 	for _, exprset := range sortedLoadedExprs {
 		// for now we are allowing repeated access paths for different
 		// directories, should not affect results since pendingExprs already
@@ -298,6 +392,8 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 		ctx.SetNamespace("global", map[string]cty.Value{})
 	}
 
+	prods := producers{}
+
 	for len(pendingExprs) > 0 {
 		amountEvaluated := 0
 
@@ -324,6 +420,7 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 							panic(errors.E(errors.ErrInternal, err))
 						}
 					}
+					prods.set(accessor, nil)
 
 					amountEvaluated++
 					delete(pendingExprs, accessor)
@@ -429,6 +526,15 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 				// When a nested object is defined either by literal or funcalls,
 				// it can't be detected at the parser.
 				oldValue, hasOldValue := globals.GetKeyPath(accessor.Path())
+				if hasOldValue && expr.isDefault {
+					logger.Trace().Msg("ignoring schema default of defined global")
+
+					amountEvaluated++
+					delete(pendingExprs, accessor)
+					delete(pendingExprsErrs, accessor)
+					continue
+				}
+
 				if hasOldValue &&
 					accessor.isattr &&
 					oldValue.Info().DefinedAt.Dir().String() == expr.Origin.Path().Dir().String() {
@@ -443,12 +549,29 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 				// This is to avoid setting a label defined extension on the child
 				// and later overwriting that with an object definition on the parent
 
-				val, err := ctx.Eval(expr)
+				val, producer, err := expr.eval(ctx, prods)
 				if err != nil {
 					pendingExprsErrs[accessor].Append(errors.E(
 						ErrEval, err, "global.%s (%t)", accessor.rootname(), accessor.isattr))
 					continue
 				}
+
+				// the global is part of an object defined by a parent, so
+				// there's no base expression to merge with.
+				if _, isMergeExpr := expr.Expression.(*mergeExpr); hasOldValue && !isMergeExpr &&
+					(expr.strategy == hcl.MergeStrategyDeepMerge || expr.strategy == hcl.MergeStrategyAppend) {
+					producer = nil
+					oldRaw, err := ctyValue(oldValue)
+					if err != nil {
+						pendingExprsErrs[accessor].Append(errors.E(err, expr.Range()))
+						continue
+					}
+					val, err = mergeValues(expr.strategy, oldRaw, val)
+					if err != nil {
+						pendingExprsErrs[accessor].Append(errors.E(err, expr.Range()))
+						continue
+					}
+				}
 				if hasOldValue && oldValue.IsObject() && !accessor.isattr {
 					// all the `attr = expr` inside global blocks become an entry
 					// in the globalExprs map but we have the special case that
@@ -469,6 +592,8 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 						eval.Info{
 							DefinedAt: expr.Origin.Path(),
 							Dir:       sortedGlobals.origin,
+							ConfigDir: expr.ConfigDir,
+							Range:     expr.Origin,
 						},
 					))
 
@@ -476,6 +601,7 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 						pendingExprsErrs[accessor].Append(errors.E(err, "setting global"))
 						continue
 					}
+					prods.set(accessor, producer)
 				}
 
 				amountEvaluated++
@@ -503,9 +629,21 @@ func (dirExprs HierarchicalExprs) Eval(ctx *eval.Context) EvalReport {
 		}
 	}
 
+	// required globals are only enforced when evaluating the globals of a
+	// stack, as other directories may not define them by design.
+	forStack := len(sortedLoadedExprs) > 0 && sortedLoadedExprs[len(sortedLoadedExprs)-1].isStack
+	schemas.check(report, definitions, forStack)
 	return report
 }
 
+func (e Expr) eval(ctx *eval.Context, prods producers) (cty.Value, *memoResult, error) {
+	if e.memo == nil {
+		val, err := ctx.Eval(e)
+		return val, nil, err
+	}
+	return e.memo.eval(ctx, e, prods)
+}
+
 func (dirExprs HierarchicalExprs) merge(other HierarchicalExprs) {
 	for k, v := range other {
 		if _, ok := dirExprs[k]; !ok {
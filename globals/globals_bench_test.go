@@ -0,0 +1,77 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/stack"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func BenchmarkGlobalsForStacks(b *testing.B) {
+	// benchmarks the case of a project with 1000 stacks sharing the globals
+	// of parent directories. The expressions which don't depend on the stack
+	// must be evaluated only once per directory.
+
+	b.StopTimer()
+	s := sandbox.NoGit(b, true)
+
+	const (
+		numGroups         = 10
+		numStacksPerGroup = 100
+		numRootGlobals    = 20
+	)
+
+	layout := []string{}
+	for g := 0; g < numGroups; g++ {
+		for i := 0; i < numStacksPerGroup; i++ {
+			layout = append(layout, fmt.Sprintf("s:group-%d/stack-%d", g, i))
+		}
+	}
+	s.BuildTree(layout)
+
+	content := `globals {
+		env     = "dev"
+		list    = tm_range(1000)
+		squares = [for i in global.list : i * i]
+		subnets = tm_cidrsubnets("10.0.0.0/8", 8, 8, 8, 8)
+		name    = "${terramate.stack.name}-${global.env}"
+	`
+	for i := 0; i < numRootGlobals; i++ {
+		content += fmt.Sprintf("\tg_%d = [for i in global.squares : \"${global.env}-${i}-%d\"]\n", i, i)
+	}
+	content += "}\n"
+	s.RootEntry().CreateFile("globals.tm", content)
+
+	for g := 0; g < numGroups; g++ {
+		s.DirEntry(fmt.Sprintf("group-%d", g)).CreateFile("globals.tm", fmt.Sprintf(`globals {
+			group   = "group-%d"
+			offsets = [for i in global.list : i + %d]
+		}`, g, g))
+	}
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		root, err := config.LoadRoot(s.RootDir())
+		assert.NoError(b, err)
+		stacks, err := stack.List(root.Tree())
+		assert.NoError(b, err)
+		if len(stacks) != numGroups*numStacksPerGroup {
+			b.Fatalf("got %d stacks", len(stacks))
+		}
+		b.StartTimer()
+
+		for _, entry := range stacks {
+			report := globals.ForStack(root, entry.Stack)
+			if err := report.AsError(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
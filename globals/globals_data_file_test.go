@@ -0,0 +1,302 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/test/hclwrite"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestLoadGlobalsDataFile(t *testing.T) {
+	t.Parallel()
+
+	dataFile := func(builders ...hclwrite.BlockBuilder) *hclwrite.Block {
+		return Block("data_file", builders...)
+	}
+
+	for _, tcase := range []testcase{
+		{
+			name: "JSON data file",
+			layout: []string{
+				"s:stack",
+				`f:data/accounts.json:{"account": "111", "cidrs": {"a": "10.0.0.0/16"}}`,
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/accounts.json")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("account", "111"),
+					EvalExpr(t, "cidrs", `{
+						a = "10.0.0.0/16"
+					}`),
+				),
+			},
+		},
+		{
+			name: "YAML data file with relative path",
+			layout: []string{
+				"s:stack",
+				"f:stack/data.yml:account: \"111\"\nregions:\n  - us-east-1\n  - eu-west-1\n",
+			},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Globals(
+						dataFile(Str("path", "data.yml")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("account", "111"),
+					EvalExpr(t, "regions", `["us-east-1", "eu-west-1"]`),
+				),
+			},
+		},
+		{
+			name: "tfvars data file",
+			layout: []string{
+				"s:stack",
+				"f:data/values.tfvars:replicas = 3\nname = \"app\"\n",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.tfvars")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Number("replicas", 3),
+					Str("name", "app"),
+				),
+			},
+		},
+		{
+			name: "explicit format",
+			layout: []string{
+				"s:stack",
+				"f:data/values.txt:replicas: 3",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(
+							Str("path", "/data/values.txt"),
+							Str("format", "yaml"),
+						),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Number("replicas", 3),
+				),
+			},
+		},
+		{
+			name: "labeled globals data file",
+			layout: []string{
+				"s:stack",
+				"f:data/aws.yaml:region: us-east-1",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Labels("aws"),
+						Str("account", "111"),
+						dataFile(Str("path", "/data/aws.yaml")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{
+						account = "111"
+						region  = "us-east-1"
+					}`),
+				),
+			},
+		},
+		{
+			name: "data file globals are overridden by child globals",
+			layout: []string{
+				"s:stack",
+				"f:data/values.yaml:env: dev\nteam: platform",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.yaml")),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("env", "prd"),
+						Expr("owner", "global.team"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("env", "prd"),
+					Str("team", "platform"),
+					Str("owner", "platform"),
+				),
+			},
+		},
+		{
+			name: "data file global conflicting with attribute fails",
+			layout: []string{
+				"s:stack",
+				"f:data/values.yaml:env: dev",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "prd"),
+						dataFile(Str("path", "/data/values.yaml")),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrRedefined),
+		},
+		{
+			name:   "missing data file fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.yaml")),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrDataFile),
+		},
+		{
+			name: "data file not containing an object fails",
+			layout: []string{
+				"s:stack",
+				"f:data/values.json:[1, 2]",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.json")),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrDataFile),
+		},
+		{
+			name: "invalid data file fails",
+			layout: []string{
+				"s:stack",
+				"f:data/values.json:{",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.json")),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrDataFile),
+		},
+		{
+			name: "tfvars data file with expressions fails",
+			layout: []string{
+				"s:stack",
+				"f:data/values.tfvars:name = var.name",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.tfvars")),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrDataFile),
+		},
+		{
+			name:   "unknown data file format fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "/data/values.txt")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "data file outside the project fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Str("path", "../values.json")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "data file path must be a literal string",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(Expr("path", "global.file")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "data file with unknown attribute fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						dataFile(
+							Str("path", "/values.json"),
+							Str("key", "a"),
+						),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+	} {
+		testGlobals(t, tcase)
+	}
+}
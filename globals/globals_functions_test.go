@@ -0,0 +1,263 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/test/hclwrite"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestLoadGlobalsProjectFunctions(t *testing.T) {
+	t.Parallel()
+
+	for _, tcase := range []testcase{
+		{
+			name: "tm_stacks with tag filter",
+			layout: []string{
+				`s:stacks/vpc:id=vpc;tags=["network"]`,
+				`s:stacks/dns:tags=["network", "dns"]`,
+				`s:stacks/app:tags=["app"]`,
+			},
+			configs: []hclconfig{
+				{
+					path: "/stacks/app",
+					add: Globals(
+						Expr("network", `[for s in tm_stacks("network") : s.path]`),
+						Expr("ids", `[for s in tm_stacks("network,app") : s.id if s.id != null]`),
+						Expr("all", `tm_length(tm_stacks(""))`),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stacks/app": Globals(
+					EvalExpr(t, "network", `["/stacks/dns", "/stacks/vpc"]`),
+					EvalExpr(t, "ids", `["vpc"]`),
+					Number("all", 3),
+				),
+			},
+		},
+		{
+			name:   "tm_stacks with invalid filter fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("stacks", `tm_stacks("a:")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name: "tm_stack_globals returns globals of other stacks",
+			layout: []string{
+				"s:network",
+				"s:app",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("region", `"eu-west-1"`),
+					),
+				},
+				{
+					path: "/network",
+					add: Globals(
+						Expr("vpc", `{ id = "vpc-${global.region}", cidr = "10.0.0.0/16" }`),
+					),
+				},
+				{
+					path: "/app",
+					add: Globals(
+						Expr("vpc_id", `tm_stack_globals("/network", "vpc.id")`),
+						Expr("vpc", `tm_stack_globals("/network", "vpc")`),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/network": Globals(
+					Str("region", "eu-west-1"),
+					EvalExpr(t, "vpc", `{ id = "vpc-eu-west-1", cidr = "10.0.0.0/16" }`),
+				),
+				"/app": Globals(
+					Str("region", "eu-west-1"),
+					Str("vpc_id", "vpc-eu-west-1"),
+					EvalExpr(t, "vpc", `{ id = "vpc-eu-west-1", cidr = "10.0.0.0/16" }`),
+				),
+			},
+		},
+		{
+			name: "tm_stack_globals chained across stacks",
+			layout: []string{
+				"s:a",
+				"s:b",
+				"s:c",
+			},
+			configs: []hclconfig{
+				{
+					path: "/a",
+					add: Globals(
+						Expr("val", `"${tm_stack_globals("/b", "val")}-a"`),
+					),
+				},
+				{
+					path: "/b",
+					add: Globals(
+						Expr("val", `"${tm_stack_globals("/c", "val")}-b"`),
+					),
+				},
+				{
+					path: "/c",
+					add: Globals(
+						Str("val", "c"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/a": Globals(Str("val", "c-b-a")),
+				"/b": Globals(Str("val", "c-b")),
+				"/c": Globals(Str("val", "c")),
+			},
+		},
+		{
+			name: "tm_stack_globals of undefined global fails",
+			layout: []string{
+				"s:a",
+				"s:b",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("val", `tm_stack_globals("/a", "undefined")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name:   "tm_stack_globals of non-stack fails",
+			layout: []string{"s:stack", "d:dir"},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("val", `tm_stack_globals("/dir", "val")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name:   "tm_stack_globals with relative path fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("val", `tm_stack_globals("stack", "val")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name:   "tm_stack_globals of own stack is a cycle",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Globals(
+						Str("a", "a"),
+						Expr("b", `tm_stack_globals("/stack", "a")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name: "tm_stack_globals cycle between stacks",
+			layout: []string{
+				"s:a",
+				"s:b",
+			},
+			configs: []hclconfig{
+				{
+					path: "/a",
+					add: Globals(
+						Expr("val", `tm_stack_globals("/b", "val")`),
+					),
+				},
+				{
+					path: "/b",
+					add: Globals(
+						Expr("val", `tm_stack_globals("/a", "val")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name: "tm_stack_globals cycle through parent globals",
+			layout: []string{
+				"s:dir/a",
+				"s:b",
+			},
+			configs: []hclconfig{
+				{
+					path: "/dir",
+					add: Globals(
+						Expr("from_b", `tm_stack_globals("/b", "val")`),
+					),
+				},
+				{
+					path: "/b",
+					add: Globals(
+						Expr("val", `tm_stack_globals("/dir/a", "from_b")`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+	} {
+		testGlobals(t, tcase)
+	}
+}
+
+func TestStackGlobalsCycleError(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		`f:a/terramate.tm.hcl:stack {}
+globals {
+  val = tm_stack_globals("/b", "val")
+}`,
+		`f:b/terramate.tm.hcl:stack {}
+globals {
+  val = tm_stack_globals("/a", "val")
+}`,
+	})
+
+	st, err := config.LoadStack(s.Config(), project.NewPath("/a"))
+	assert.NoError(t, err)
+
+	report := globals.ForStack(s.Config(), st)
+	err = report.AsError()
+	assert.IsError(t, err, errors.E(globals.ErrEval))
+	if !strings.Contains(err.Error(), string(globals.ErrStackGlobalsCycle)+": /a -> /b -> /a") {
+		t.Fatalf("error does not report the cycle: %v", err)
+	}
+}
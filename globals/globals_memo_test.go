@@ -0,0 +1,121 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/test/hclwrite"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestLoadGlobalsMemoizedAcrossStacks(t *testing.T) {
+	t.Parallel()
+
+	// the stacks share the root configuration, so the memoized values of the
+	// root globals must never leak between stacks.
+	for _, tcase := range []testcase{
+		{
+			name: "parent global referencing a global overridden by a stack",
+			layout: []string{
+				"s:stacks/a",
+				"s:stacks/b",
+				"s:stacks/c",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "dev"),
+						Expr("list", "tm_range(3)"),
+						Expr("name", `"${global.env}-${tm_length(global.list)}"`),
+					),
+				},
+				{
+					path: "/stacks/b",
+					add: Globals(
+						Str("env", "prd"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stacks/a": Globals(
+					Str("env", "dev"),
+					EvalExpr(t, "list", "tolist([0, 1, 2])"),
+					Str("name", "dev-3"),
+				),
+				"/stacks/b": Globals(
+					Str("env", "prd"),
+					EvalExpr(t, "list", "tolist([0, 1, 2])"),
+					Str("name", "prd-3"),
+				),
+				"/stacks/c": Globals(
+					Str("env", "dev"),
+					EvalExpr(t, "list", "tolist([0, 1, 2])"),
+					Str("name", "dev-3"),
+				),
+			},
+		},
+		{
+			name: "parent global referencing stack metadata",
+			layout: []string{
+				"s:stacks/a",
+				"s:stacks/b",
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("name", "tm_upper(terramate.stack.name)"),
+						Expr("path", "terramate.stack.path.absolute"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stacks/a": Globals(
+					Str("name", "A"),
+					Str("path", "/stacks/a"),
+				),
+				"/stacks/b": Globals(
+					Str("name", "B"),
+					Str("path", "/stacks/b"),
+				),
+			},
+		},
+		{
+			name: "parent global reading files relative to the stack",
+			layout: []string{
+				"s:stacks/a",
+				"s:stacks/b",
+				"f:stacks/a/name.txt:a",
+				"f:stacks/b/name.txt:b",
+				`f:functions.tm:function "tm_read" {
+  params = ["name"]
+  result = tm_file(name)
+}`,
+			},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("file", `tm_file("name.txt")`),
+						Expr("func", `tm_read("name.txt")`),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stacks/a": Globals(
+					Str("file", "a"),
+					Str("func", "a"),
+				),
+				"/stacks/b": Globals(
+					Str("file", "b"),
+					Str("func", "b"),
+				),
+			},
+		},
+	} {
+		testGlobals(t, tcase)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/test/hclwrite"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestLoadGlobalsMergeStrategy(t *testing.T) {
+	t.Parallel()
+
+	strategy := func(builders ...hclwrite.BlockBuilder) *hclwrite.Block {
+		return Block("merge_strategy", builders...)
+	}
+
+	for _, tcase := range []testcase{
+		{
+			name:   "override is the default strategy",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("tags", `{ team = "platform", env = "dev" }`),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("tags", `{ env = "prd" }`),
+						strategy(Str("tags", "override")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "tags", `{ env = "prd" }`),
+				),
+			},
+		},
+		{
+			name:   "deep_merge objects across the hierarchy",
+			layout: []string{"s:dir/stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("tags", `{
+							team  = "platform"
+							owner = { name = "a", email = "a@example.com" }
+						}`),
+					),
+				},
+				{
+					path: "/dir",
+					add: Globals(
+						Expr("tags", `{ env = "dev" }`),
+						strategy(Str("tags", "deep_merge")),
+					),
+				},
+				{
+					path: "/dir/stack",
+					add: Globals(
+						Expr("tags", `{
+							env   = "prd"
+							owner = { name = "b" }
+						}`),
+						strategy(Str("tags", "deep_merge")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/dir/stack": Globals(
+					EvalExpr(t, "tags", `{
+						team  = "platform"
+						env   = "prd"
+						owner = { name = "b", email = "a@example.com" }
+					}`),
+				),
+			},
+		},
+		{
+			name:   "deep_merge with object defined by parent object",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("aws", `{ tags = { team = "platform" } }`),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Labels("aws"),
+						Expr("tags", `{ env = "prd" }`),
+						strategy(Str("tags", "deep_merge")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{
+						tags = { team = "platform", env = "prd" }
+					}`),
+				),
+			},
+		},
+		{
+			name:   "deep_merge without parent definition",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("tags", `{ env = "prd" }`),
+						strategy(Str("tags", "deep_merge")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "tags", `{ env = "prd" }`),
+				),
+			},
+		},
+		{
+			name:   "append lists across the hierarchy",
+			layout: []string{"s:dir/stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("cidrs", `["10.0.0.0/16"]`),
+					),
+				},
+				{
+					path: "/dir",
+					add: Globals(
+						Expr("cidrs", `["10.1.0.0/16"]`),
+						strategy(Str("cidrs", "append")),
+					),
+				},
+				{
+					path: "/dir/stack",
+					add: Globals(
+						Expr("cidrs", `tm_tolist(["10.2.0.0/16"])`),
+						strategy(Str("cidrs", "append")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/dir/stack": Globals(
+					EvalExpr(t, "cidrs", `["10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/16"]`),
+				),
+			},
+		},
+		{
+			name:   "append referencing other globals",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("base", "a"),
+						Expr("list", `[global.base]`),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("extra", "b"),
+						Expr("list", `[global.extra]`),
+						strategy(Str("list", "append")),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("base", "a"),
+					Str("extra", "b"),
+					EvalExpr(t, "list", `["a", "b"]`),
+				),
+			},
+		},
+		{
+			name:   "append to non-list fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("list", "a"),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("list", `["b"]`),
+						strategy(Str("list", "append")),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrEval),
+		},
+		{
+			name:   "final global defined in the same directory",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("aws", `{ region = "us-east-1" }`),
+						strategy(Str("aws", "final")),
+					),
+				},
+				{
+					path: "/",
+					add: Globals(
+						Labels("aws"),
+						Str("account", "111"),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("env", "prd"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{ region = "us-east-1", account = "111" }`),
+					Str("env", "prd"),
+				),
+			},
+		},
+		{
+			name:   "overriding final global fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "prd"),
+						strategy(Str("env", "final")),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("env", "dev"),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrOverrideFinal),
+		},
+		{
+			name:   "unsetting final global fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "prd"),
+						strategy(Str("env", "final")),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("env", "unset"),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrOverrideFinal),
+		},
+		{
+			name:   "extending final global fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("aws", `{ region = "us-east-1" }`),
+						strategy(Str("aws", "final")),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Labels("aws"),
+						Str("account", "111"),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrOverrideFinal),
+		},
+		{
+			name:   "replacing object containing final global fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Labels("aws"),
+						Str("region", "us-east-1"),
+						strategy(Str("region", "final")),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Expr("aws", `{ region = "eu-west-1" }`),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrOverrideFinal),
+		},
+		{
+			name:   "extending object containing final global works",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Labels("aws"),
+						Str("region", "us-east-1"),
+						strategy(Str("region", "final")),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Labels("aws"),
+						Str("account", "111"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{ region = "us-east-1", account = "111" }`),
+				),
+			},
+		},
+		{
+			name:   "unknown merge strategy fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "prd"),
+						strategy(Str("env", "merge")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "merge strategy of undefined global fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "prd"),
+						strategy(Str("team", "final")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "merge strategy must be a literal string",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Str("env", "prd"),
+						strategy(Expr("env", "global.strategy")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+	} {
+		testGlobals(t, tcase)
+	}
+}
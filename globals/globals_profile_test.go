@@ -0,0 +1,222 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/test/hclwrite"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+)
+
+func TestLoadGlobalsProfile(t *testing.T) {
+	t.Parallel()
+
+	profile := func(name string, builders ...hclwrite.BlockBuilder) *hclwrite.Block {
+		return Block("profile", append([]hclwrite.BlockBuilder{Labels(name)}, builders...)...)
+	}
+
+	configs := []hclconfig{
+		{
+			path: "/",
+			add: Globals(
+				Str("env", "dev"),
+				Number("replicas", 1),
+			),
+		},
+		{
+			path:     "/",
+			filename: "profiles.tm",
+			add: profile("prod",
+				Globals(
+					Str("env", "prod"),
+				),
+			),
+		},
+	}
+
+	for _, tcase := range []testcase{
+		{
+			name:    "inactive profile is ignored",
+			layout:  []string{"s:stack"},
+			configs: configs,
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("env", "dev"),
+					Number("replicas", 1),
+				),
+			},
+		},
+		{
+			name:    "active profile overrides globals of the directory",
+			layout:  []string{"s:stack"},
+			configs: configs,
+			profile: "prod",
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("env", "prod"),
+					Number("replicas", 1),
+				),
+			},
+		},
+		{
+			name:    "active profile exposed as terramate.profile",
+			layout:  []string{"s:stack"},
+			profile: "prod",
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("profile", "terramate.profile"),
+					),
+				},
+				{
+					path: "/",
+					add:  profile("prod"),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("profile", "prod"),
+				),
+			},
+		},
+		{
+			name:   "child directories still override the profile of parents",
+			layout: []string{"s:stacks/a", "s:stacks/b"},
+			configs: append([]hclconfig{
+				{
+					path: "/stacks/b",
+					add: Globals(
+						Str("env", "b"),
+					),
+				},
+			}, configs...),
+			profile: "prod",
+			want: map[string]*hclwrite.Block{
+				"/stacks/a": Globals(
+					Str("env", "prod"),
+					Number("replicas", 1),
+				),
+				"/stacks/b": Globals(
+					Str("env", "b"),
+					Number("replicas", 1),
+				),
+			},
+		},
+		{
+			name:   "profile declared in child directory",
+			layout: []string{"s:stacks/a", "s:stacks/b"},
+			configs: append([]hclconfig{
+				{
+					path: "/stacks/b",
+					add: profile("prod",
+						Globals(
+							Number("replicas", 3),
+						),
+					),
+				},
+			}, configs...),
+			profile: "prod",
+			want: map[string]*hclwrite.Block{
+				"/stacks/a": Globals(
+					Str("env", "prod"),
+					Number("replicas", 1),
+				),
+				"/stacks/b": Globals(
+					Str("env", "prod"),
+					Number("replicas", 3),
+				),
+			},
+		},
+		{
+			name:   "labeled profile globals",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Labels("aws"),
+						Str("region", "us-east-1"),
+						Str("account", "dev"),
+					),
+				},
+				{
+					path: "/",
+					add: profile("prod",
+						Globals(
+							Labels("aws"),
+							Str("account", "prod"),
+						),
+					),
+				},
+			},
+			profile: "prod",
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{
+						region  = "us-east-1"
+						account = "prod"
+					}`),
+				),
+			},
+		},
+		{
+			name:   "profile map block replaces global attribute",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Globals(
+						Expr("sizes", `{ a = "small" }`),
+					),
+				},
+				{
+					path: "/",
+					add: profile("prod",
+						Globals(
+							Block("map",
+								Labels("sizes"),
+								Expr("for_each", `["a", "b"]`),
+								Expr("key", "element.new"),
+								Str("value", "large"),
+							),
+						),
+					),
+				},
+			},
+			profile: "prod",
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "sizes", `{
+						a = "large"
+						b = "large"
+					}`),
+				),
+			},
+		},
+		{
+			name:   "profile defines globals in directory without globals",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/stack",
+					add: profile("prod",
+						Globals(
+							Str("env", "prod"),
+						),
+					),
+				},
+			},
+			profile: "prod",
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("env", "prod"),
+				),
+			},
+		},
+	} {
+		testGlobals(t, tcase)
+	}
+}
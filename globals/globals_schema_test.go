@@ -0,0 +1,444 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/terramate-io/terramate/test/hclwrite"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestLoadGlobalsSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := func(builders ...hclwrite.BlockBuilder) *hclwrite.Block {
+		return Block("globals_schema", builders...)
+	}
+
+	for _, tcase := range []testcase{
+		{
+			name:   "default is used when global is not defined",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("env"),
+						Expr("type", "string"),
+						Str("default", "dev"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("env", "dev"),
+				),
+			},
+		},
+		{
+			name:   "default of nested global extends the parent object",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(
+							Labels("aws", "region"),
+							Expr("type", "string"),
+							Str("default", "us-east-1"),
+						),
+						Globals(
+							Labels("aws"),
+							Str("account", "main"),
+						),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{
+						account = "main"
+						region  = "us-east-1"
+					}`),
+				),
+			},
+		},
+		{
+			name:   "default is not used when global is defined",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("env"),
+						Str("default", "dev"),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("env", "prd"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("env", "prd"),
+				),
+			},
+		},
+		{
+			name:   "default is not used when global is defined by parent object",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(
+							Labels("aws", "region"),
+							Str("default", "us-east-1"),
+						),
+						Globals(
+							Expr("aws", `{
+								region = "eu-west-1"
+							}`),
+						),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					EvalExpr(t, "aws", `{
+						region = "eu-west-1"
+					}`),
+				),
+			},
+		},
+		{
+			name:   "globals can reference defaults and defaults can reference globals",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(
+							Labels("name"),
+							Expr("default", `"${global.prefix}-${terramate.stack.name}"`),
+						),
+						Globals(
+							Str("prefix", "tm"),
+							Expr("bucket", `"${global.name}-bucket"`),
+						),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("prefix", "tm"),
+					Str("name", "tm-stack"),
+					Str("bucket", "tm-stack-bucket"),
+				),
+			},
+		},
+		{
+			name:   "globals conforming to the declared types",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(Labels("str"), Expr("type", "string")),
+						schema(Labels("num"), Expr("type", "number")),
+						schema(Labels("list"), Expr("type", "list(string)")),
+						schema(Labels("obj"), Expr("type", "object({ a = number })")),
+						schema(Labels("any"), Expr("type", "any")),
+						Globals(
+							Str("str", "a"),
+							Number("num", 1),
+							Expr("list", `["a", "b"]`),
+							Expr("obj", `{ a = 1 }`),
+							Bool("any", true),
+						),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Str("str", "a"),
+					Number("num", 1),
+					EvalExpr(t, "list", `tolist(["a", "b"])`),
+					EvalExpr(t, "obj", `{ a = 1 }`),
+					Bool("any", true),
+				),
+			},
+		},
+		{
+			name:   "globals are converted to the declared types",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(Labels("port"), Expr("type", "number")),
+						schema(Labels("enabled"), Expr("type", "string")),
+						Globals(
+							Str("port", "80"),
+							Bool("enabled", true),
+						),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/stack": Globals(
+					Number("port", 80),
+					Str("enabled", "true"),
+				),
+			},
+		},
+		{
+			name:   "global with wrong type fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("replicas"),
+						Expr("type", "number"),
+						Str("description", "amount of replicas"),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("replicas", "three"),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrSchema),
+		},
+		{
+			name:   "nested global with wrong type fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(
+							Labels("aws", "regions"),
+							Expr("type", "list(string)"),
+						),
+						Globals(
+							Expr("aws", `{
+								regions = "us-east-1"
+							}`),
+						),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrSchema),
+		},
+		{
+			name:   "missing required global fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("env"),
+						Bool("required", true),
+					),
+				},
+				{
+					path: "/stack",
+					add: Globals(
+						Str("enviroment", "prd"),
+					),
+				},
+			},
+			wantErr: errors.E(globals.ErrSchema),
+		},
+		{
+			name:   "child schema overrides parent schema",
+			layout: []string{"s:dir/stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("port"),
+						Expr("type", "number"),
+					),
+				},
+				{
+					path: "/dir",
+					add: schema(
+						Labels("port"),
+						Expr("type", "string"),
+						Str("default", "http"),
+					),
+				},
+			},
+			want: map[string]*hclwrite.Block{
+				"/dir/stack": Globals(
+					Str("port", "http"),
+				),
+			},
+		},
+		{
+			name:   "invalid type constraint fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("env"),
+						Expr("type", "strin"),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "required global with default fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("env"),
+						Bool("required", true),
+						Str("default", "dev"),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "redeclared schema in same dir fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: Doc(
+						schema(Labels("env")),
+						schema(Labels("env")),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+		{
+			name:   "unknown schema attribute fails",
+			layout: []string{"s:stack"},
+			configs: []hclconfig{
+				{
+					path: "/",
+					add: schema(
+						Labels("env"),
+						Str("doc", "the env"),
+					),
+				},
+			},
+			wantErr: errors.E(hcl.ErrTerramateSchema),
+		},
+	} {
+		testGlobals(t, tcase)
+	}
+}
+
+func TestGlobalsSchemaErrorRange(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.RootEntry().CreateFile("schema.tm", Block("globals_schema",
+		Labels("replicas"),
+		Expr("type", "number"),
+		Str("description", "amount of replicas"),
+	).String())
+	stackEntry := s.CreateStack("stack")
+	stackEntry.CreateFile("globals.tm", Globals(
+		Str("replicas", "three"),
+	).String())
+
+	report := globals.ForStack(s.Config(), stackEntry.Load(s.Config()))
+	assert.EqualInts(t, 1, len(report.Errors))
+
+	key := globals.NewGlobalAttrPath(nil, "replicas")
+	evalErr, ok := report.Errors[key]
+	assert.IsTrue(t, ok, "global.replicas error not found")
+	assert.EqualStrings(t, "/stack/globals.tm", evalErr.Expr.Origin.Path().String())
+
+	var tmerr *errors.Error
+	assert.IsTrue(t, errors.As(evalErr.Err, &tmerr))
+	assert.EqualStrings(t,
+		filepath.Join(stackEntry.Path(), "globals.tm"),
+		tmerr.FileRange.Filename)
+}
+
+func TestGlobalsSchemas(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.RootEntry().CreateFile("schema.tm", Doc(
+		Block("globals_schema",
+			Labels("env"),
+			Expr("type", "string"),
+			Str("description", "the environment"),
+		),
+		Block("globals_schema",
+			Labels("aws", "region"),
+			Str("description", "the AWS region"),
+		),
+	).String())
+	s.BuildTree([]string{"d:dir"})
+	s.DirEntry("dir").CreateFile("schema.tm", Block("globals_schema",
+		Labels("env"),
+		Str("description", "the dir environment"),
+	).String())
+
+	tree, ok := s.Config().Lookup(project.NewPath("/dir"))
+	assert.IsTrue(t, ok)
+
+	exprs, err := globals.LoadExprs(tree)
+	assert.NoError(t, err)
+
+	schemas := exprs.Schemas()
+	assert.EqualInts(t, 2, len(schemas))
+	assert.EqualStrings(t, "global.aws.region", schemas[0].Name())
+	assert.EqualStrings(t, "the AWS region", schemas[0].Description)
+	assert.EqualStrings(t, "/", schemas[0].Dir.String())
+	assert.EqualStrings(t, "global.env", schemas[1].Name())
+	assert.EqualStrings(t, "the dir environment", schemas[1].Description)
+	assert.EqualStrings(t, "/dir", schemas[1].Dir.String())
+
+	report := exprs.Eval(eval.NewContext(stdlib.Functions(s.RootDir())))
+	assert.NoError(t, report.AsError())
+}
+
+func TestGlobalsSchemaRequiredOnlyForStacks(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.RootEntry().CreateFile("schema.tm", Block("globals_schema",
+		Labels("env"),
+		Bool("required", true),
+	).String())
+	s.BuildTree([]string{"d:dir", "s:stack"})
+
+	report := globals.ForDir(s.Config(), project.NewPath("/dir"),
+		eval.NewContext(stdlib.Functions(s.RootDir())))
+	assert.NoError(t, report.AsError())
+
+	report = globals.ForStack(s.Config(), s.LoadStack(project.NewPath("/stack")))
+	assert.IsError(t, report.AsError(), errors.E(globals.ErrSchema))
+}
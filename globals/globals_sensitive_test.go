@@ -0,0 +1,80 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/globals"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+func TestLoadGlobalsSensitive(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		`f:globals.tm.hcl:globals {
+  password = tm_sensitive("secret")
+  database = tm_sensitive({
+    user = "admin"
+    pass = "secret"
+  })
+}`,
+		`f:stack/terramate.tm.hcl:stack {}
+globals {
+  dsn     = "admin:${global.password}@localhost"
+  user    = "admin"
+  db_user = global.database.user
+}`,
+		`f:other/terramate.tm.hcl:stack {}
+globals {
+  remote = tm_stack_globals("/stack", "dsn")
+}`,
+	})
+
+	for _, tc := range []struct {
+		stack     string
+		sensitive []string
+		plain     []string
+	}{
+		{
+			stack:     "/stack",
+			sensitive: []string{"password", "database", "dsn", "db_user"},
+			plain:     []string{"user"},
+		},
+		{
+			stack:     "/other",
+			sensitive: []string{"password", "database", "remote"},
+		},
+	} {
+		st, err := config.LoadStack(s.Config(), project.NewPath(tc.stack))
+		assert.NoError(t, err)
+
+		report := globals.ForStack(s.Config(), st)
+		assert.NoError(t, report.AsError())
+
+		attrs := report.Globals.AsValueMap()
+		for _, name := range tc.sensitive {
+			if !eval.IsSensitive(attrs[name]) {
+				t.Errorf("%s: global.%s should be sensitive", tc.stack, name)
+			}
+		}
+		for _, name := range tc.plain {
+			if eval.IsSensitive(attrs[name]) {
+				t.Errorf("%s: global.%s should not be sensitive", tc.stack, name)
+			}
+		}
+
+		str := report.Globals.String()
+		if strings.Contains(str, "secret") || !strings.Contains(str, eval.RedactedValue) {
+			t.Errorf("%s: globals string exposes sensitive values:\n%s", tc.stack, str)
+		}
+	}
+}
@@ -35,6 +35,7 @@ type (
 		name    string
 		layout  []string
 		configs []hclconfig
+		profile string
 		want    map[string]*hclwrite.Block
 		wantErr error
 	}
@@ -4108,6 +4109,10 @@ func testGlobals(t *testing.T, tcase testcase) {
 			return
 		}
 
+		if tcase.profile != "" {
+			assert.NoError(t, s.Config().SetProfile(tcase.profile))
+		}
+
 		stackEntries, err := stack.List(cfg)
 		assert.NoError(t, err)
 
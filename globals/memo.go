@@ -0,0 +1,283 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals
+
+import (
+	"sync"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// exprMemo memoizes the values of the global expressions of a directory which
+// don't depend on the stack being evaluated, so the stacks sharing the
+// directory evaluate them only once.
+//
+// An expression is memoized if it doesn't reference the terramate.stack
+// namespace and only calls pure functions. Globals are lazily evaluated, so a
+// parent expression may still see different values for the globals it
+// references, which is why the memoized value is only reused when all the
+// variables referenced by the expression have the same values.
+type exprMemo struct {
+	values *sync.Map
+	impure map[string]bool
+}
+
+// memoEntry is the memoization state of a single expression.
+type memoEntry struct {
+	cacheable  bool
+	traversals []hhcl.Traversal
+	result     *memoResult
+}
+
+// memoResult is a memoized value of an expression. A result is never
+// modified, so globals set from the same result have the same value.
+type memoResult struct {
+	val    cty.Value
+	inputs []memoInput
+}
+
+// memoInput is the value of a variable referenced by a memoized expression.
+type memoInput struct {
+	val cty.Value
+
+	// producer is the result which the referenced global was set from, if any.
+	producer *memoResult
+}
+
+// producers maps the top-level globals to the memoized results they were set
+// from while evaluating the globals of a stack, which avoids comparing their
+// values when checking if the inputs of other expressions changed.
+type producers map[string]*memoResult
+
+// set records the producer of the value set at the global path, which is nil
+// if the value was not memoized.
+func (p producers) set(accessor GlobalPathKey, producer *memoResult) {
+	if producer != nil && accessor.isattr && accessor.numPaths == 1 {
+		p[accessor.rootname()] = producer
+		return
+	}
+	delete(p, accessor.rootname())
+}
+
+func (p producers) of(traversal hhcl.Traversal) *memoResult {
+	if traversal.RootName() != "global" || len(traversal) < 2 {
+		return nil
+	}
+	attr, ok := traversal[1].(hhcl.TraverseAttr)
+	if !ok {
+		return nil
+	}
+	return p[attr.Name]
+}
+
+type (
+	impureFuncsKey struct{}
+	dataFileKey    string
+)
+
+// dataFileMemo is the memoized result of loading a data file.
+type dataFileMemo struct {
+	values map[string]cty.Value
+	err    error
+}
+
+func newExprMemo(tree *config.Tree) *exprMemo {
+	return &exprMemo{
+		values: tree.Memo(),
+		impure: impureFunctions(tree),
+	}
+}
+
+// impureFunctions returns the names of the functions whose results depend on
+// the stack being evaluated or which are not deterministic. The user defined
+// functions calling impure functions are also impure.
+func impureFunctions(tree *config.Tree) map[string]bool {
+	for tree.Parent != nil {
+		tree = tree.Parent
+	}
+	if v, ok := tree.Memo().Load(impureFuncsKey{}); ok {
+		return v.(map[string]bool)
+	}
+
+	impure := map[string]bool{StackGlobalsFuncName: true}
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range tree.Node.Functions {
+			if !impure[fn.Name] && callsImpure(fn.Result, impure) {
+				impure[fn.Name] = true
+				changed = true
+			}
+		}
+	}
+	tree.Memo().Store(impureFuncsKey{}, impure)
+	return impure
+}
+
+// callsImpure tells if the expression calls an impure function.
+func callsImpure(expr hhcl.Expression, impure map[string]bool) bool {
+	synexpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return true
+	}
+	found := false
+	_ = hclsyntax.VisitAll(synexpr, func(node hclsyntax.Node) hhcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok {
+			if impure[call.Name] || !stdlib.IsPure(call.Name) {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// eval evaluates the expression, reusing its memoized value if the variables
+// it references didn't change. It returns the memoized result the value comes
+// from, if any.
+func (m *exprMemo) eval(ctx *eval.Context, expr Expr, prods producers) (cty.Value, *memoResult, error) {
+	var entry memoEntry
+	if v, ok := m.values.Load(expr.Expression); ok {
+		entry = v.(memoEntry)
+	} else {
+		entry = m.analyze(expr.Expression)
+		m.values.Store(expr.Expression, entry)
+	}
+	if !entry.cacheable {
+		val, err := ctx.Eval(expr)
+		return val, nil, err
+	}
+
+	if entry.result != nil && sameInputs(ctx, entry.traversals, entry.result.inputs, prods) {
+		return entry.result.val, entry.result, nil
+	}
+
+	val, err := ctx.Eval(expr)
+	if err != nil {
+		return val, nil, err
+	}
+	inputs, ok := traverse(ctx, entry.traversals, prods)
+	if !ok {
+		return val, nil, nil
+	}
+	entry.result = &memoResult{
+		val:    val,
+		inputs: inputs,
+	}
+	m.values.Store(expr.Expression, entry)
+	return val, entry.result, nil
+}
+
+func (m *exprMemo) analyze(expr hhcl.Expression) memoEntry {
+	if _, ok := expr.(hclsyntax.Expression); !ok || callsImpure(expr, m.impure) {
+		return memoEntry{}
+	}
+	traversals := expr.Variables()
+	for _, traversal := range traversals {
+		if traversal.RootName() != "terramate" {
+			continue
+		}
+		if len(traversal) == 1 {
+			return memoEntry{}
+		}
+		if attr, ok := traversal[1].(hhcl.TraverseAttr); !ok || attr.Name == "stack" {
+			return memoEntry{}
+		}
+	}
+	return memoEntry{
+		cacheable:  true,
+		traversals: traversals,
+	}
+}
+
+// traverse returns the values of the traversals in the context, or false if
+// any of them cannot be resolved.
+func traverse(ctx *eval.Context, traversals []hhcl.Traversal, prods producers) ([]memoInput, bool) {
+	inputs := make([]memoInput, len(traversals))
+	for i, traversal := range traversals {
+		val, diags := traversal.TraverseAbs(ctx.Unwrap())
+		if diags.HasErrors() {
+			return nil, false
+		}
+		inputs[i] = memoInput{
+			val:      val,
+			producer: prods.of(traversal),
+		}
+	}
+	return inputs, true
+}
+
+// sameInputs tells if the traversals have the same values in the context as
+// the memoized inputs.
+func sameInputs(ctx *eval.Context, traversals []hhcl.Traversal, inputs []memoInput, prods producers) bool {
+	for i, traversal := range traversals {
+		if producer := prods.of(traversal); producer != nil && producer == inputs[i].producer {
+			continue
+		}
+		val, diags := traversal.TraverseAbs(ctx.Unwrap())
+		if diags.HasErrors() || !equalValue(val, inputs[i].val) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValue is like [cty.Value.RawEquals] but compares the numbers directly
+// instead of by their string representation, which is too slow for comparing
+// big collections.
+func equalValue(a, b cty.Value) bool {
+	if !a.Type().Equals(b.Type()) {
+		return false
+	}
+	a, amarks := a.Unmark()
+	b, bmarks := b.Unmark()
+	if !amarks.Equal(bmarks) {
+		return false
+	}
+	if a.IsNull() || b.IsNull() {
+		return a.IsNull() && b.IsNull()
+	}
+	if !a.IsKnown() || !b.IsKnown() {
+		return false
+	}
+
+	ty := a.Type()
+	switch {
+	case ty == cty.Number:
+		return a.AsBigFloat().Cmp(b.AsBigFloat()) == 0
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType() ||
+		ty.IsMapType() || ty.IsObjectType():
+		if a.LengthInt() != b.LengthInt() {
+			return false
+		}
+		ait, bit := a.ElementIterator(), b.ElementIterator()
+		for ait.Next() && bit.Next() {
+			akey, aval := ait.Element()
+			bkey, bval := bit.Element()
+			if !equalValue(akey, bkey) || !equalValue(aval, bval) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.RawEquals(b)
+	}
+}
+
+// loadMemoizedDataFile loads the data file only once per directory.
+func loadMemoizedDataFile(tree *config.Tree, datafile hcl.GlobalsDataFile) (map[string]cty.Value, error) {
+	key := dataFileKey(datafile.HostPath)
+	if v, ok := tree.Memo().Load(key); ok {
+		res := v.(dataFileMemo)
+		return res.values, res.err
+	}
+	values, err := loadDataFile(datafile)
+	tree.Memo().Store(key, dataFileMemo{values: values, err: err})
+	return values, err
+}
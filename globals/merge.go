@@ -0,0 +1,112 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals
+
+import (
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// mergeExpr is an expression which combines the value of a global with the
+// value of the parent definition it overrides, using a merge strategy.
+type mergeExpr struct {
+	strategy hcl.MergeStrategy
+	base     hhcl.Expression
+
+	hhcl.Expression
+}
+
+// Value evaluates the base and the overriding expressions and merges them.
+func (m *mergeExpr) Value(ctx *hhcl.EvalContext) (cty.Value, hhcl.Diagnostics) {
+	base, diags := m.base.Value(ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	val, diags := m.Expression.Value(ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	merged, err := mergeValues(m.strategy, base, val)
+	if err != nil {
+		rng := m.Expression.Range()
+		return cty.NilVal, hhcl.Diagnostics{
+			&hhcl.Diagnostic{
+				Severity: hhcl.DiagError,
+				Summary:  "invalid merge",
+				Detail:   err.Error(),
+				Subject:  &rng,
+			},
+		}
+	}
+	return merged, nil
+}
+
+// Variables returns the variables of both the base and overriding expressions.
+func (m *mergeExpr) Variables() []hhcl.Traversal {
+	return append(m.base.Variables(), m.Expression.Variables()...)
+}
+
+// mergeValues merges the value with the base value using the given strategy.
+func mergeValues(strategy hcl.MergeStrategy, base, val cty.Value) (cty.Value, error) {
+	switch strategy {
+	case hcl.MergeStrategyDeepMerge:
+		if !stdlib.IsObject(base) || !stdlib.IsObject(val) {
+			return cty.NilVal, errors.E(ErrMergeStrategy,
+				"deep_merge requires objects but got %s and %s",
+				base.Type().FriendlyName(), val.Type().FriendlyName())
+		}
+		return stdlib.DeepMerge(base, val), nil
+	case hcl.MergeStrategyAppend:
+		if !isList(base) || !isList(val) {
+			return cty.NilVal, errors.E(ErrMergeStrategy,
+				"append requires lists but got %s and %s",
+				base.Type().FriendlyName(), val.Type().FriendlyName())
+		}
+		base, baseMarks := base.Unmark()
+		val, valMarks := val.Unmark()
+		elems := append(base.AsValueSlice(), val.AsValueSlice()...)
+		if len(elems) == 0 {
+			return cty.EmptyTupleVal.WithMarks(baseMarks, valMarks), nil
+		}
+		return cty.TupleVal(elems).WithMarks(baseMarks, valMarks), nil
+	default:
+		return val, nil
+	}
+}
+
+func isList(val cty.Value) bool {
+	return !val.IsNull() && val.IsKnown() &&
+		(val.Type().IsListType() || val.Type().IsTupleType() || val.Type().IsSetType())
+}
+
+// overriddenFinal returns the final global overridden by the global path,
+// if any. The global path overrides a final global if it's the same path,
+// if it's inside the final global or if it's an attribute replacing an
+// object which contains the final global.
+func overriddenFinal(finals map[GlobalPathKey]Expr, key GlobalPathKey) (Expr, bool) {
+	for finalKey, final := range finals {
+		if hasPathPrefix(key.Path(), finalKey.Path()) {
+			return final, true
+		}
+		if key.isattr && hasPathPrefix(finalKey.Path(), key.Path()) {
+			return final, true
+		}
+	}
+	return Expr{}, false
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i, p := range prefix {
+		if path[i] != p {
+			return false
+		}
+	}
+	return true
+}
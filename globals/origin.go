@@ -0,0 +1,157 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals
+
+import (
+	"sort"
+	"strings"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	// Origin describes where the value of a global comes from.
+	Origin struct {
+		// Path is the global accessor, like global.a.b.
+		Path string `json:"path"`
+		// Value is the evaluated value of the global.
+		Value cty.Value `json:"-"`
+		// Dir is the directory where the winning definition is instantiated.
+		Dir project.Path `json:"dir"`
+		// Range is the range of the winning definition.
+		Range info.Range `json:"range"`
+		// Overridden are the lower precedence definitions of the same global
+		// path, sorted from the most specific to the least specific directory.
+		Overridden []Definition `json:"overridden,omitempty"`
+	}
+
+	// Definition is a global definition of a configuration directory.
+	Definition struct {
+		// Dir is the configuration directory of the definition.
+		Dir project.Path `json:"dir"`
+		// Range is the range of the definition.
+		Range info.Range `json:"range"`
+	}
+)
+
+// Origins returns the origin of each evaluated global, sorted by the global
+// path. Objects are described by the origin of each of its values, so only
+// empty objects have an origin of their own.
+func (r EvalReport) Origins() []Origin {
+	res := []Origin{}
+	r.collectOrigins(r.Globals, nil, &res)
+	return res
+}
+
+func (r EvalReport) collectOrigins(obj *eval.Object, basepath []string, res *[]Origin) {
+	keys := make([]string, 0, len(obj.Keys))
+	for key := range obj.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := make([]string, len(basepath), len(basepath)+1)
+		copy(path, basepath)
+		path = append(path, key)
+
+		val := obj.Keys[key]
+		if subobj, ok := val.(*eval.Object); ok && len(subobj.Keys) > 0 {
+			r.collectOrigins(subobj, path, res)
+			continue
+		}
+
+		// the evaluated globals only hold objects and cty values, so the
+		// conversion never fails.
+		value, _ := ctyValue(val)
+		valinfo := val.Info()
+		*res = append(*res, Origin{
+			Path:       "global." + strings.Join(path, "."),
+			Value:      value,
+			Dir:        valinfo.ConfigDir,
+			Range:      valinfo.Range,
+			Overridden: r.exprs.overridden(path, valinfo.Range),
+		})
+	}
+}
+
+// Expr returns the expression of the global definition with the given range,
+// like the range of the origin of an evaluated global.
+func (r EvalReport) Expr(defined info.Range) (hhcl.Expression, bool) {
+	for _, set := range r.exprs {
+		for _, expr := range set.expressions {
+			if expr.Origin.String() == defined.String() {
+				return expr.Expression, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// overridden returns the definitions of the given global path, except the
+// winner one, from the most specific to the least specific directory. The
+// definitions of parent objects which may define the path, like
+// global.a = { b = 1 } for global.a.b, are also included.
+func (dirExprs HierarchicalExprs) overridden(path []string, winner info.Range) []Definition {
+	var res []Definition
+	sets := dirExprs.sort()
+	for i := len(sets) - 1; i >= 0; i-- {
+		set := sets[i]
+		var defs []Definition
+		for key, expr := range set.expressions {
+			if !key.isattr || !isObjectPathPrefix(key.Path(), path) {
+				continue
+			}
+			if expr.Origin.String() == winner.String() {
+				continue
+			}
+			if !mayDefine(expr.Expression, path[len(key.Path()):]) {
+				continue
+			}
+			defs = append(defs, Definition{
+				Dir:   set.origin,
+				Range: expr.Origin,
+			})
+		}
+		sort.Slice(defs, func(i, j int) bool {
+			return defs[i].Range.String() < defs[j].Range.String()
+		})
+		res = append(res, defs...)
+	}
+	return res
+}
+
+// isObjectPathPrefix tells if prefix is the same path or a parent object path
+// of path.
+func isObjectPathPrefix(prefix, path eval.ObjectPath) bool {
+	return len(prefix) <= len(path) && isSameObjectPath(prefix, path[:len(prefix)])
+}
+
+// mayDefine tells if the expression may define the subpath of its value. Only
+// object constructors with literal keys are inspected, any other expression
+// may define it.
+func mayDefine(expr hhcl.Expression, subpath []string) bool {
+	if len(subpath) == 0 {
+		return true
+	}
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return true
+	}
+	for _, item := range obj.Items {
+		key, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || !key.IsKnown() || key.IsNull() || key.Type() != cty.String {
+			return true
+		}
+		if key.AsString() == subpath[0] {
+			return mayDefine(item.ValueExpr, subpath[1:])
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals_test
+
+import (
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/globals"
+	. "github.com/terramate-io/terramate/test/hclwrite/hclutils"
+	"github.com/terramate-io/terramate/test/sandbox"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestGlobalsOrigins(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.RootEntry().CreateFile("globals.tm", Doc(
+		Globals(
+			Str("env", "dev"),
+			Str("team", "platform"),
+		),
+		Globals(
+			Labels("aws"),
+			Str("region", "us-east-1"),
+		),
+	).String())
+	s.BuildTree([]string{"d:dir"})
+	s.DirEntry("dir").CreateFile("globals.tm", Globals(
+		Str("env", "stg"),
+		Expr("empty", "{}"),
+	).String())
+	stackEntry := s.CreateStack("dir/stack")
+	stackEntry.CreateFile("globals.tm", Doc(
+		Globals(
+			Str("env", "prd"),
+		),
+		Globals(
+			Labels("aws"),
+			Str("region", "eu-west-1"),
+		),
+	).String())
+
+	report := globals.ForStack(s.Config(), stackEntry.Load(s.Config()))
+	assert.NoError(t, report.AsError())
+
+	type definition struct {
+		dir, file string
+	}
+	type want struct {
+		path       string
+		value      cty.Value
+		dir, file  string
+		overridden []definition
+	}
+
+	wants := []want{
+		{
+			path:  "global.aws.region",
+			value: cty.StringVal("eu-west-1"),
+			dir:   "/dir/stack",
+			file:  "/dir/stack/globals.tm",
+			overridden: []definition{
+				{dir: "/", file: "/globals.tm"},
+			},
+		},
+		{
+			path:  "global.empty",
+			value: cty.EmptyObjectVal,
+			dir:   "/dir",
+			file:  "/dir/globals.tm",
+		},
+		{
+			path:  "global.env",
+			value: cty.StringVal("prd"),
+			dir:   "/dir/stack",
+			file:  "/dir/stack/globals.tm",
+			overridden: []definition{
+				{dir: "/dir", file: "/dir/globals.tm"},
+				{dir: "/", file: "/globals.tm"},
+			},
+		},
+		{
+			path:  "global.team",
+			value: cty.StringVal("platform"),
+			dir:   "/",
+			file:  "/globals.tm",
+		},
+	}
+
+	got := report.Origins()
+	assert.EqualInts(t, len(wants), len(got), "origins: %v", got)
+
+	for i, want := range wants {
+		origin := got[i]
+		assert.EqualStrings(t, want.path, origin.Path)
+		assert.IsTrue(t, want.value.RawEquals(origin.Value),
+			"%s: want %s but got %s", want.path, want.value.GoString(), origin.Value.GoString())
+		assert.EqualStrings(t, want.dir, origin.Dir.String(), want.path)
+		assert.EqualStrings(t, want.file, origin.Range.Path().String(), want.path)
+		assert.EqualInts(t, len(want.overridden), len(origin.Overridden),
+			"%s: overridden: %v", want.path, origin.Overridden)
+		for j, def := range want.overridden {
+			assert.EqualStrings(t, def.dir, origin.Overridden[j].Dir.String(), want.path)
+			assert.EqualStrings(t, def.file, origin.Overridden[j].Range.Path().String(), want.path)
+		}
+	}
+}
+
+func TestGlobalsOriginsOverriddenByParentObject(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.RootEntry().CreateFile("globals.tm", Globals(
+		Expr("a", `{
+			b = 1
+			c = 2
+		}`),
+		Expr("x", `{
+			y = 1
+		}`),
+	).String())
+	stackEntry := s.CreateStack("stack")
+	stackEntry.CreateFile("globals.tm", Doc(
+		Globals(
+			Labels("a"),
+			Number("b", 3),
+		),
+		Globals(
+			Labels("x"),
+			Number("z", 1),
+		),
+	).String())
+
+	report := globals.ForStack(s.Config(), stackEntry.Load(s.Config()))
+	assert.NoError(t, report.AsError())
+
+	type want struct {
+		path       string
+		file       string
+		overridden []string
+	}
+
+	wants := []want{
+		{
+			path:       "global.a.b",
+			file:       "/stack/globals.tm",
+			overridden: []string{"/globals.tm"},
+		},
+		{
+			path: "global.a.c",
+			file: "/globals.tm",
+		},
+		{
+			path: "global.x.y",
+			file: "/globals.tm",
+		},
+		{
+			path: "global.x.z",
+			file: "/stack/globals.tm",
+		},
+	}
+
+	got := report.Origins()
+	assert.EqualInts(t, len(wants), len(got), "origins: %v", got)
+
+	for i, want := range wants {
+		origin := got[i]
+		assert.EqualStrings(t, want.path, origin.Path)
+		assert.EqualStrings(t, want.file, origin.Range.Path().String(), want.path)
+		assert.EqualInts(t, len(want.overridden), len(origin.Overridden),
+			"%s: overridden: %v", want.path, origin.Overridden)
+		for j, file := range want.overridden {
+			assert.EqualStrings(t, file, origin.Overridden[j].Range.Path().String(), want.path)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package globals
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ErrSchema indicates that a global doesn't conform to its globals_schema.
+const ErrSchema errors.Kind = "global schema"
+
+// Schema is a globals schema declaration and the directory declaring it.
+type Schema struct {
+	// Dir is the configuration directory declaring the schema.
+	Dir project.Path
+
+	hcl.GlobalSchema
+}
+
+// schemaSet is the set of globals schemas of a hierarchy, keyed by the
+// declared global path.
+type schemaSet map[GlobalPathKey]Schema
+
+// Schemas returns the globals schemas visible from the loaded hierarchy,
+// sorted by the global name. Schemas declared in more specific directories
+// override the ones declared in parent directories.
+func (dirExprs HierarchicalExprs) Schemas() []Schema {
+	schemas := dirExprs.schemas()
+	keys := make([]GlobalPathKey, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].name() < keys[j].name()
+	})
+
+	res := make([]Schema, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, schemas[key])
+	}
+	return res
+}
+
+func (dirExprs HierarchicalExprs) schemas() schemaSet {
+	res := schemaSet{}
+	for _, exprset := range dirExprs.sort() {
+		for _, schema := range exprset.schemas {
+			res[schemaKey(schema.Path)] = Schema{
+				Dir:          exprset.origin,
+				GlobalSchema: schema,
+			}
+		}
+	}
+	return res
+}
+
+// defaults returns the default expressions of the schemas declaring globals
+// not defined by any of the given expressions.
+func (schemas schemaSet) defaults(exprs map[GlobalPathKey]Expr) map[GlobalPathKey]Expr {
+	res := map[GlobalPathKey]Expr{}
+	for key, schema := range schemas {
+		if schema.Default == nil {
+			continue
+		}
+		if _, ok := exprs[key]; ok {
+			continue
+		}
+		res[key] = Expr{
+			Origin:     schema.Default.Range,
+			ConfigDir:  schema.Dir,
+			LabelPath:  key.Path(),
+			Expression: schema.Default.Expr,
+			isDefault:  true,
+		}
+	}
+	return res
+}
+
+// check checks the evaluated globals of the report against the schemas,
+// adding an error to the report for each global that doesn't conform.
+// Globals which already failed to evaluate are not checked and missing
+// required globals are only reported if checkRequired is true.
+func (schemas schemaSet) check(report EvalReport, definitions map[GlobalPathKey]Expr, checkRequired bool) {
+	for key, schema := range schemas {
+		if _, failed := report.Errors[key]; failed {
+			continue
+		}
+
+		val, ok := report.Globals.GetKeyPath(schema.Path)
+		if !ok {
+			if schema.Required && checkRequired {
+				report.Errors[key] = EvalError{
+					Expr: Expr{
+						Origin:    schema.Range,
+						ConfigDir: schema.Dir,
+						LabelPath: schema.Path,
+						Expression: &hclsyntax.LiteralValueExpr{
+							Val:      cty.NullVal(cty.DynamicPseudoType),
+							SrcRange: schema.Range.ToHCLRange(),
+						},
+					},
+					Err: errors.E(ErrEval, errors.E(ErrSchema, schema.Range,
+						"%s is required but it's not defined", schema.Name())),
+				}
+			}
+			continue
+		}
+
+		if schema.Type == cty.DynamicPseudoType {
+			continue
+		}
+
+		rawval, err := ctyValue(val)
+		if err != nil {
+			report.Errors[key] = EvalError{
+				Expr: Expr{
+					Origin:    schema.Range,
+					ConfigDir: schema.Dir,
+					LabelPath: schema.Path,
+					Expression: &hclsyntax.LiteralValueExpr{
+						Val:      cty.NullVal(cty.DynamicPseudoType),
+						SrcRange: schema.Range.ToHCLRange(),
+					},
+				},
+				Err: errors.E(ErrEval, err),
+			}
+			continue
+		}
+
+		valinfo := val.Info()
+		converted, err := convert.Convert(rawval, schema.Type)
+		if err == nil {
+			if converted.RawEquals(rawval) {
+				continue
+			}
+			// the global is stored with the declared type, so "80" declared
+			// as a number is a number everywhere the global is used.
+			err = report.Globals.SetAt(schema.Path, eval.NewValue(converted, valinfo))
+			if err == nil {
+				continue
+			}
+		}
+
+		expr, ok := definitions[key]
+		if !ok {
+			// the global is defined as part of a parent object.
+			expr = Expr{
+				Origin:    valinfo.Range,
+				ConfigDir: valinfo.ConfigDir,
+				LabelPath: schema.Path,
+				Expression: &hclsyntax.LiteralValueExpr{
+					Val:      rawval,
+					SrcRange: valinfo.Range.ToHCLRange(),
+				},
+			}
+		}
+		report.Errors[key] = EvalError{
+			Expr: expr,
+			Err: errors.E(ErrEval, errors.E(ErrSchema, valinfo.Range, err,
+				"%s must be of type %s (declared at %s)",
+				schema.Name(), typeexpr.TypeString(schema.Type),
+				schema.Range.String())),
+		}
+	}
+}
+
+func schemaKey(path []string) GlobalPathKey {
+	return NewGlobalAttrPath(path[:len(path)-1], path[len(path)-1])
+}
+
+func ctyValue(val eval.Value) (cty.Value, error) {
+	switch v := val.(type) {
+	case *eval.Object:
+		return cty.ObjectVal(v.AsValueMap()), nil
+	case eval.CtyValue:
+		return v.Raw(), nil
+	default:
+		return cty.NilVal, errors.E(ErrSchema, "unexpected global value type %T", val)
+	}
+}
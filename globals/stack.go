@@ -6,14 +6,22 @@ package globals
 import (
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/project"
 	"github.com/terramate-io/terramate/stdlib"
 )
 
 // ForStack loads from the config tree all globals defined for a given stack.
 func ForStack(root *config.Root, stack *config.Stack) EvalReport {
+	return forStack(root, stack, nil)
+}
+
+// forStack loads the globals of the stack while the globals of the stacks in
+// the chain are being evaluated.
+func forStack(root *config.Root, stack *config.Stack, chain []project.Path) EvalReport {
 	ctx := eval.NewContext(
 		stdlib.Functions(stack.HostDir(root)),
 	)
+	setFunctions(root, ctx, append(chain[:len(chain):len(chain)], stack.Dir))
 	runtime := root.Runtime()
 	runtime.Merge(stack.RuntimeValues(root))
 	ctx.SetNamespace("terramate", runtime)
@@ -33,6 +33,7 @@ func TokensForExpression(expr hcl.Expression) hclwrite.Tokens {
 }
 
 // TokensForValue returns the tokens for the provided value.
+// The marks of the value are ignored.
 func TokensForValue(value cty.Value) hclwrite.Tokens {
 	if value.Type() == customdecode.ExpressionClosureType {
 		closureExpr := value.EncapsulatedValue().(*customdecode.ExpressionClosure)
@@ -40,6 +41,7 @@ func TokensForValue(value cty.Value) hclwrite.Tokens {
 	} else if value.Type() == customdecode.ExpressionType {
 		return TokensForExpression(customdecode.ExpressionFromVal(value))
 	}
+	value, _ = value.UnmarkDeep()
 	return hclwrite.TokensForValue(value)
 }
 
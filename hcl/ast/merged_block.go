@@ -172,8 +172,8 @@ func (mb *MergedBlock) ValidateSubBlocks(allowed ...string) error {
 			}
 
 			if !found {
-				errs.Append(errors.E(rawblock.DefRange(), "unrecognized block %q",
-					rawblock.Type))
+				errs.Append(errors.E(rawblock.DefRange(), "unrecognized block %q%s",
+					rawblock.Type, DidYouMean(rawblock.Type, allowed)))
 			}
 		}
 	}
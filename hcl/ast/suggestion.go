@@ -0,0 +1,31 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package ast
+
+import (
+	"fmt"
+
+	"github.com/agext/levenshtein"
+)
+
+// DidYouMean returns a suggestion, in the form ` (did you mean "name"?)`,
+// of the known name which is most similar to the given unknown name. It returns
+// an empty string if no known name is similar enough.
+func DidYouMean(name string, known []string) string {
+	const maxDistance = 3
+
+	best := ""
+	bestDistance := maxDistance
+	for _, candidate := range known {
+		distance := levenshtein.Distance(name, candidate, nil)
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
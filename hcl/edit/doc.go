@@ -0,0 +1,7 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+// Package edit changes the stack attributes and globals of the Terramate
+// configuration files of a directory, preserving comments and the parts of
+// the files which are not edited.
+package edit
@@ -0,0 +1,172 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package edit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/rs/zerolog/log"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/fs"
+	hclfmt "github.com/terramate-io/terramate/hcl/fmt"
+	"github.com/terramate-io/terramate/project"
+)
+
+// ErrEdit indicates that the configuration could not be edited.
+const ErrEdit errors.Kind = "editing configuration"
+
+// defaultFileMode is the file mode of the files created by the edits.
+const defaultFileMode = 0644
+
+// File is a configuration file changed by the edits.
+type File struct {
+	// Path is the path of the file relative to the project root.
+	Path project.Path
+
+	// Original is the content of the file before the edits. It's empty if
+	// the file is created by the edits.
+	Original []byte
+
+	// Edited is the content of the file after the edits.
+	Edited []byte
+
+	hostpath string
+	mode     os.FileMode
+}
+
+// Dir is a directory whose Terramate files are being edited. The edits are
+// kept in memory until [Dir.Save] is called.
+type Dir struct {
+	rootdir string
+	dir     string
+	files   []*file
+}
+
+type file struct {
+	File
+	parsed *hclwrite.File
+
+	// edited tells if the file was touched by the edits. The untouched files
+	// are never rewritten.
+	edited bool
+}
+
+// LoadDir loads the Terramate files of the directory dir for editing. The
+// rootdir is the project root and both must be absolute host paths.
+func LoadDir(rootdir, dir string) (*Dir, error) {
+	logger := log.With().
+		Str("action", "edit.LoadDir()").
+		Str("dir", dir).
+		Logger()
+
+	filenames, err := fs.ListTerramateFiles(dir)
+	if err != nil {
+		return nil, errors.E(ErrEdit, err)
+	}
+
+	d := &Dir{
+		rootdir: rootdir,
+		dir:     dir,
+	}
+	errs := errors.L()
+	for _, filename := range filenames {
+		logger.Trace().Str("file", filename).Msg("loading file")
+
+		hostpath := filepath.Join(dir, filename)
+		st, err := os.Stat(hostpath)
+		if err != nil {
+			errs.Append(errors.E(ErrEdit, err, "stat file"))
+			continue
+		}
+		original, err := os.ReadFile(hostpath)
+		if err != nil {
+			errs.Append(errors.E(ErrEdit, err, "reading file"))
+			continue
+		}
+		parsed, diags := hclwrite.ParseConfig(original, hostpath, hhcl.InitialPos)
+		if diags.HasErrors() {
+			errs.Append(errors.E(ErrEdit, diags))
+			continue
+		}
+		d.files = append(d.files, &file{
+			File: File{
+				Path:     project.PrjAbsPath(rootdir, hostpath),
+				Original: original,
+				hostpath: hostpath,
+				mode:     st.Mode(),
+			},
+			parsed: parsed,
+		})
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Files returns the files changed by the edits, sorted by path.
+func (d *Dir) Files() []File {
+	var changed []File
+	for _, f := range d.files {
+		if !f.edited {
+			continue
+		}
+		edited := hclfmt.KeepFormatting(f.Original, f.parsed.Bytes())
+		if bytes.Equal(f.Original, edited) {
+			continue
+		}
+		f.Edited = edited
+		changed = append(changed, f.File)
+	}
+	return changed
+}
+
+// Save writes the files changed by the edits.
+func (d *Dir) Save() error {
+	for _, f := range d.Files() {
+		if err := f.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff returns the changes of the file as an unified diff.
+func (f File) Diff() string {
+	name := f.Path.String()[1:]
+	return hclfmt.Diff(name, string(f.Original), string(f.Edited))
+}
+
+// Write writes the edited content to the file, keeping its file mode.
+func (f File) Write() error {
+	if err := os.WriteFile(f.hostpath, f.Edited, f.mode); err != nil {
+		return errors.E(ErrEdit, err, "writing file %s", f.Path)
+	}
+	return nil
+}
+
+// newFile adds a new empty file to the directory, named after the default
+// Terramate configuration file.
+func (d *Dir) newFile() (*file, error) {
+	hostpath := filepath.Join(d.dir, config.DefaultFilename)
+	if _, err := os.Lstat(hostpath); err == nil {
+		return nil, errors.E(ErrEdit, "file %s already exists and is not a Terramate file",
+			project.PrjAbsPath(d.rootdir, hostpath))
+	}
+	f := &file{
+		File: File{
+			Path:     project.PrjAbsPath(d.rootdir, hostpath),
+			hostpath: hostpath,
+			mode:     defaultFileMode,
+		},
+		parsed: hclwrite.NewEmptyFile(),
+	}
+	d.files = append(d.files, f)
+	return f, nil
+}
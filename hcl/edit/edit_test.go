@@ -0,0 +1,502 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package edit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/edit"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/test/sandbox"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type (
+	file struct {
+		path string
+		body string
+	}
+
+	testcase struct {
+		name    string
+		layout  []string
+		edit    func(d *edit.Dir) error
+		want    []file
+		wantErr error
+	}
+)
+
+func TestEdit(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []testcase{
+		{
+			name: "set tags keeping comments",
+			layout: []string{
+				"f:stack.tm:" + `# the stack
+stack {
+  name = "stack" # the name
+  tags = ["a"]   # old tags
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetStackList("tags", []string{"b", "c"})
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `# the stack
+stack {
+  name = "stack" # the name
+  tags = ["b", "c"] # old tags
+}
+`,
+				},
+			},
+		},
+		{
+			name: "set tags keeping tab indentation",
+			layout: []string{
+				"f:stack.tm:" + "stack {\n\tname = \"stack\"\n\ttags = [\"a\"]\n}\n\nglobals {\n\ta = 1\n}\n",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetStackList("tags", []string{"b"})
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: "stack {\n\tname = \"stack\"\n\ttags = [\"b\"]\n}\n\nglobals {\n\ta = 1\n}\n",
+				},
+			},
+		},
+		{
+			name: "add attribute keeping tab indentation and alignment",
+			layout: []string{
+				"f:stack.tm:" + "stack {\n\tname = \"stack\"\n\ttags = [\"a\"]\n}\n",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("after", "/b")
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: "stack {\n\tname = \"stack\"\n\ttags = [\"a\"]\n\tafter = [\"/b\"]\n}\n",
+				},
+			},
+		},
+		{
+			name: "add attribute to nested block keeping indentation",
+			layout: []string{
+				"f:globals.tm:" + "globals \"a\" {\n    b = 1\n}\n",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetGlobal([]string{"a", "c"}, cty.NumberIntVal(2))
+			},
+			want: []file{
+				{
+					path: "/globals.tm",
+					body: "globals \"a\" {\n    b = 1\n    c = 2\n}\n",
+				},
+			},
+		},
+		{
+			name: "add stack paths skipping the same project paths",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  after = ["/b", "tag:x"]
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("after", "../b", "b", "tag:x", "c")
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  after = ["/b", "tag:x", "c"]
+}
+`,
+				},
+			},
+		},
+		{
+			name: "set empty list removes the attribute",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  name = "stack"
+  tags = ["a"]
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetStackList("tags", nil)
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  name = "stack"
+}
+`,
+				},
+			},
+		},
+		{
+			name: "add values skipping duplicates",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  after = ["/a"]
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("after", "/b", "/a", "/b")
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  after = ["/a", "/b"]
+}
+`,
+				},
+			},
+		},
+		{
+			name: "add values to undefined attribute",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  # watched files
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("watch", "/file.txt")
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  # watched files
+  watch = ["/file.txt"]
+}
+`,
+				},
+			},
+		},
+		{
+			name: "add existing values does nothing",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  wants = [ "/a" ]
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("wants", "/a")
+			},
+		},
+		{
+			name: "remove values",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  tags = ["a", "b", "c"]
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.RemoveStackListValues("tags", "b", "z")
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  tags = ["a", "c"]
+}
+`,
+				},
+			},
+		},
+		{
+			name: "set and remove stack attributes",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  name        = "stack"
+  description = "old"
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				if err := d.SetStackAttribute("id", cty.StringVal("my-id")); err != nil {
+					return err
+				}
+				return d.RemoveStackAttribute("description")
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  name        = "stack"
+  id   = "my-id"
+}
+`,
+				},
+			},
+		},
+		{
+			name: "non literal list fails",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  tags = tm_concat(["a"], ["b"])
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("tags", "c")
+			},
+			wantErr: errors.E(edit.ErrEdit),
+		},
+		{
+			name: "unknown stack attribute fails",
+			layout: []string{
+				"f:stack.tm:stack {}",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.AddStackListValues("tag", "c")
+			},
+			wantErr: errors.E(edit.ErrEdit),
+		},
+		{
+			name: "non list stack attribute fails",
+			layout: []string{
+				"f:stack.tm:stack {}",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetStackList("name", []string{"a"})
+			},
+			wantErr: errors.E(edit.ErrEdit),
+		},
+		{
+			name: "no stack block fails",
+			layout: []string{
+				"f:globals.tm:globals {}",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetStackList("tags", []string{"a"})
+			},
+			wantErr: errors.E(edit.ErrEdit),
+		},
+		{
+			name: "set existing global in place",
+			layout: []string{
+				"f:stack.tm:stack {}",
+				"f:globals.tm:" + `# region config
+globals "aws" {
+  region = "us-east-1" # default region
+  zone   = "a"
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetGlobal([]string{"aws", "region"}, cty.StringVal("eu-west-1"))
+			},
+			want: []file{
+				{
+					path: "/globals.tm",
+					body: `# region config
+globals "aws" {
+  region = "eu-west-1" # default region
+  zone   = "a"
+}
+`,
+				},
+			},
+		},
+		{
+			name: "set global in existing block",
+			layout: []string{
+				"f:globals.tm:" + `globals {
+  a = 1
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetGlobalExpr([]string{"b"}, "global.a + 1")
+			},
+			want: []file{
+				{
+					path: "/globals.tm",
+					body: `globals {
+  a = 1
+  b = global.a + 1
+}
+`,
+				},
+			},
+		},
+		{
+			name: "set global appends block to stack file",
+			layout: []string{
+				"f:stack.tm:" + `stack {
+  name = "stack"
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				return d.SetGlobal([]string{"aws", "region"}, cty.StringVal("eu-west-1"))
+			},
+			want: []file{
+				{
+					path: "/stack.tm",
+					body: `stack {
+  name = "stack"
+}
+
+globals "aws" {
+  region = "eu-west-1"
+}
+`,
+				},
+			},
+		},
+		{
+			name: "set global creates file",
+			edit: func(d *edit.Dir) error {
+				return d.SetGlobal([]string{"env"}, cty.StringVal("prod"))
+			},
+			want: []file{
+				{
+					path: "/terramate.tm.hcl",
+					body: `globals {
+  env = "prod"
+}
+`,
+				},
+			},
+		},
+		{
+			name: "remove global and empty block",
+			layout: []string{
+				"f:globals.tm:" + `globals "aws" {
+  region = "us-east-1"
+}
+
+# kept
+globals {
+  a = 1
+  b = 2
+}
+`,
+			},
+			edit: func(d *edit.Dir) error {
+				if err := d.RemoveGlobal([]string{"aws", "region"}); err != nil {
+					return err
+				}
+				return d.RemoveGlobal([]string{"a"})
+			},
+			want: []file{
+				{
+					path: "/globals.tm",
+					body: `
+# kept
+globals {
+  b = 2
+}
+`,
+				},
+			},
+		},
+		{
+			name: "remove undefined global fails",
+			layout: []string{
+				"f:globals.tm:globals {}",
+			},
+			edit: func(d *edit.Dir) error {
+				return d.RemoveGlobal([]string{"a"})
+			},
+			wantErr: errors.E(edit.ErrEdit),
+		},
+		{
+			name: "invalid global path fails",
+			edit: func(d *edit.Dir) error {
+				return d.SetGlobal([]string{"a-b", "0c"}, cty.True)
+			},
+			wantErr: errors.E(edit.ErrEdit),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := sandbox.NoGit(t, true)
+			s.BuildTree(tc.layout)
+
+			dir, err := edit.LoadDir(s.RootDir(), s.RootDir())
+			assert.NoError(t, err)
+
+			err = tc.edit(dir)
+			assert.IsError(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+
+			files := dir.Files()
+			assert.EqualInts(t, len(tc.want), len(files))
+			for i, want := range tc.want {
+				assert.EqualStrings(t, want.path, files[i].Path.String())
+				assert.EqualStrings(t, want.body, string(files[i].Edited))
+			}
+		})
+	}
+}
+
+func TestEditSave(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"f:stack/stack.tm:" + `stack {
+  tags = ["a"]
+}
+`,
+	})
+	stackfile := filepath.Join(s.RootDir(), "stack", "stack.tm")
+	assert.NoError(t, os.Chmod(stackfile, 0600))
+
+	dir, err := edit.LoadDir(s.RootDir(), filepath.Join(s.RootDir(), "stack"))
+	assert.NoError(t, err)
+	assert.NoError(t, dir.AddStackListValues("tags", "b"))
+
+	files := dir.Files()
+	assert.EqualInts(t, 1, len(files))
+	assert.EqualStrings(t, `--- a/stack/stack.tm
++++ b/stack/stack.tm
+@@ -1,3 +1,3 @@
+ stack {
+-  tags = ["a"]
++  tags = ["a", "b"]
+ }
+`, files[0].Diff())
+
+	assert.NoError(t, dir.Save())
+	assert.EqualStrings(t, string(files[0].Edited),
+		string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+
+	st, err := os.Stat(stackfile)
+	assert.NoError(t, err)
+	assert.IsTrue(t, st.Mode().Perm() == 0600)
+
+	dir, err = edit.LoadDir(s.RootDir(), filepath.Join(s.RootDir(), "stack"))
+	assert.NoError(t, err)
+	tags, err := dir.StackList("tags")
+	assert.NoError(t, err)
+	assert.EqualInts(t, 2, len(tags))
+	assert.EqualInts(t, 0, len(dir.Files()))
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package edit
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/slices"
+)
+
+const globalsBlockType = "globals"
+
+// SetGlobal sets the global at path to the given value. The path has the
+// labels of the globals block followed by the attribute name, so the path
+// ["aws", "region"] sets the region attribute of the globals "aws" block.
+// If the directory has no globals block with the labels, one is appended to
+// the file defining the first globals block of the directory, or to the file
+// defining the stack block, or to a new terramate.tm.hcl file.
+func (d *Dir) SetGlobal(path []string, val cty.Value) error {
+	return d.setGlobal(path, ast.TokensForValue(val))
+}
+
+// SetGlobalExpr sets the global at path to the given expression, which is
+// kept unevaluated in the file. See [Dir.SetGlobal].
+func (d *Dir) SetGlobalExpr(path []string, exprStr string) error {
+	expr, err := ast.ParseExpression(exprStr, "<edit>")
+	if err != nil {
+		return errors.E(ErrEdit, err, "parsing global.%s", strings.Join(path, "."))
+	}
+	tokens := ast.TokensForExpression(expr)
+	// drop the EOF token, as the expression is embedded in the file.
+	return d.setGlobal(path, tokens[:len(tokens)-1])
+}
+
+// RemoveGlobal removes the global at path from all globals blocks of the
+// directory. The globals blocks left empty are removed as well.
+func (d *Dir) RemoveGlobal(path []string) error {
+	if err := checkGlobalPath(path); err != nil {
+		return err
+	}
+	labels, name := path[:len(path)-1], path[len(path)-1]
+
+	found := false
+	for _, f := range d.files {
+		for _, block := range globalsBlocks(f.parsed.Body(), labels) {
+			if block.Body().GetAttribute(name) == nil {
+				continue
+			}
+			found = true
+			f.edited = true
+			block.Body().RemoveAttribute(name)
+			if len(block.Body().Attributes()) == 0 && len(block.Body().Blocks()) == 0 {
+				f.parsed.Body().RemoveBlock(block)
+			}
+		}
+	}
+	if !found {
+		return errors.E(ErrEdit, "global.%s is not defined in directory %s",
+			strings.Join(path, "."), project.PrjAbsPath(d.rootdir, d.dir))
+	}
+	return nil
+}
+
+func (d *Dir) setGlobal(path []string, tokens hclwrite.Tokens) error {
+	if err := checkGlobalPath(path); err != nil {
+		return err
+	}
+	labels, name := path[:len(path)-1], path[len(path)-1]
+
+	var (
+		target     *hclwrite.Body
+		targetFile *file
+	)
+	for _, f := range d.files {
+		for _, block := range globalsBlocks(f.parsed.Body(), labels) {
+			if block.Body().GetAttribute(name) != nil {
+				f.edited = true
+				block.Body().SetAttributeRaw(name, tokens)
+				return nil
+			}
+			if target == nil {
+				target, targetFile = block.Body(), f
+			}
+		}
+	}
+	if target == nil {
+		f, err := d.globalsFile()
+		if err != nil {
+			return err
+		}
+		body := f.parsed.Body()
+		if len(body.Attributes()) > 0 || len(body.Blocks()) > 0 {
+			body.AppendNewline()
+		}
+		target, targetFile = body.AppendNewBlock(globalsBlockType, labels).Body(), f
+	}
+	targetFile.edited = true
+	target.SetAttributeRaw(name, tokens)
+	return nil
+}
+
+// globalsFile returns the file where new globals blocks are appended.
+func (d *Dir) globalsFile() (*file, error) {
+	for _, f := range d.files {
+		if hasAnyGlobals(f.parsed.Body()) {
+			return f, nil
+		}
+	}
+	for _, f := range d.files {
+		for _, block := range f.parsed.Body().Blocks() {
+			if block.Type() == hcl.StackBlockType {
+				return f, nil
+			}
+		}
+	}
+	return d.newFile()
+}
+
+func hasAnyGlobals(body *hclwrite.Body) bool {
+	for _, block := range body.Blocks() {
+		if block.Type() == globalsBlockType {
+			return true
+		}
+	}
+	return false
+}
+
+// globalsBlocks returns the top-level globals blocks of the body with the
+// given labels.
+func globalsBlocks(body *hclwrite.Body, labels []string) []*hclwrite.Block {
+	var blocks []*hclwrite.Block
+	for _, block := range body.Blocks() {
+		if block.Type() != globalsBlockType {
+			continue
+		}
+		if !slices.Equal(block.Labels(), labels) {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func checkGlobalPath(path []string) error {
+	if len(path) == 0 {
+		return errors.E(ErrEdit, "empty global path")
+	}
+	for _, name := range path {
+		if !hclsyntax.ValidIdentifier(name) {
+			return errors.E(ErrEdit, "global.%s: %q is not a valid identifier",
+				strings.Join(path, "."), name)
+		}
+	}
+	return nil
+}
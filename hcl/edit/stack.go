@@ -0,0 +1,236 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package edit
+
+import (
+	"path"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/slices"
+)
+
+// stackPathAttributes are the stack list attributes holding paths of stacks,
+// relative to the stack directory or project absolute, or tag queries.
+var stackPathAttributes = []string{"after", "before", "wants", "wanted_by"}
+
+// listAttributeType is the schema type of the stack attributes which hold a
+// list of strings, like tags and after.
+const listAttributeType = "set(string)"
+
+// SetStackAttribute sets the attribute of the stack block of the directory.
+// If the attribute is already defined its value is replaced in place,
+// otherwise it's appended to the stack block.
+func (d *Dir) SetStackAttribute(name string, val cty.Value) error {
+	if _, err := stackAttribute(name); err != nil {
+		return err
+	}
+	f, stack, err := d.stackBlock()
+	if err != nil {
+		return err
+	}
+	f.edited = true
+	stack.SetAttributeValue(name, val)
+	return nil
+}
+
+// RemoveStackAttribute removes the attribute of the stack block of the
+// directory. It does nothing if the attribute is not defined.
+func (d *Dir) RemoveStackAttribute(name string) error {
+	if _, err := stackAttribute(name); err != nil {
+		return err
+	}
+	f, stack, err := d.stackBlock()
+	if err != nil {
+		return err
+	}
+	if stack.GetAttribute(name) == nil {
+		return nil
+	}
+	f.edited = true
+	stack.RemoveAttribute(name)
+	return nil
+}
+
+// StackList returns the values of the stack list attribute, like tags or
+// after. The attribute must be a literal list of strings.
+func (d *Dir) StackList(name string) ([]string, error) {
+	if err := checkStackList(name); err != nil {
+		return nil, err
+	}
+	_, stack, err := d.stackBlock()
+	if err != nil {
+		return nil, err
+	}
+	return stackListValues(stack, name)
+}
+
+// SetStackList sets the values of the stack list attribute, like tags or
+// after. The attribute is removed if no values are given.
+func (d *Dir) SetStackList(name string, values []string) error {
+	if err := checkStackList(name); err != nil {
+		return err
+	}
+	f, stack, err := d.stackBlock()
+	if err != nil {
+		return err
+	}
+	f.edited = true
+	setStackList(stack, name, values)
+	return nil
+}
+
+// AddStackListValues appends the values to the stack list attribute, like tags
+// or after. The values already in the list are not added again, and stack paths
+// are the same if they resolve to the same project path.
+func (d *Dir) AddStackListValues(name string, values ...string) error {
+	if err := checkStackList(name); err != nil {
+		return err
+	}
+	f, stack, err := d.stackBlock()
+	if err != nil {
+		return err
+	}
+	current, err := stackListValues(stack, name)
+	if err != nil {
+		return err
+	}
+	updated := current
+	for _, val := range values {
+		if !d.containsListValue(name, updated, val) {
+			updated = append(updated, val)
+		}
+	}
+	if len(updated) == len(current) {
+		return nil
+	}
+	f.edited = true
+	setStackList(stack, name, updated)
+	return nil
+}
+
+// RemoveStackListValues removes the values from the stack list attribute, like
+// tags or after. The attribute is removed if no values are left.
+func (d *Dir) RemoveStackListValues(name string, values ...string) error {
+	if err := checkStackList(name); err != nil {
+		return err
+	}
+	f, stack, err := d.stackBlock()
+	if err != nil {
+		return err
+	}
+	current, err := stackListValues(stack, name)
+	if err != nil {
+		return err
+	}
+	var updated []string
+	for _, val := range current {
+		if !d.containsListValue(name, values, val) {
+			updated = append(updated, val)
+		}
+	}
+	if len(updated) == len(current) {
+		return nil
+	}
+	f.edited = true
+	setStackList(stack, name, updated)
+	return nil
+}
+
+// stackBlock returns the file and the body of the stack block of the directory.
+func (d *Dir) stackBlock() (*file, *hclwrite.Body, error) {
+	for _, f := range d.files {
+		for _, block := range f.parsed.Body().Blocks() {
+			if block.Type() == hcl.StackBlockType {
+				return f, block.Body(), nil
+			}
+		}
+	}
+	return nil, nil, errors.E(ErrEdit, "directory %s has no %s block",
+		project.PrjAbsPath(d.rootdir, d.dir), hcl.StackBlockType)
+}
+
+func stackAttribute(name string) (hcl.AttributeSpec, error) {
+	spec, _ := hcl.Schema().Block(hcl.StackBlockType)
+	attr, ok := spec.Attribute(name)
+	if !ok {
+		return hcl.AttributeSpec{}, errors.E(ErrEdit, "unrecognized attribute %s.%s%s",
+			hcl.StackBlockType, name, ast.DidYouMean(name, spec.AttributeNames()))
+	}
+	return attr, nil
+}
+
+func checkStackList(name string) error {
+	attr, err := stackAttribute(name)
+	if err != nil {
+		return err
+	}
+	if attr.Type != listAttributeType {
+		return errors.E(ErrEdit, "attribute %s.%s is not a list of strings",
+			hcl.StackBlockType, name)
+	}
+	return nil
+}
+
+// containsListValue tells if the values of the stack list attribute contain
+// val, comparing stack paths by their project path.
+func (d *Dir) containsListValue(name string, values []string, val string) bool {
+	key := d.listValueKey(name, val)
+	return slices.ContainsFunc(values, func(v string) bool {
+		return d.listValueKey(name, v) == key
+	})
+}
+
+func (d *Dir) listValueKey(name, val string) string {
+	if !slices.Contains(stackPathAttributes, name) || strings.HasPrefix(val, "tag:") {
+		return val
+	}
+	if path.IsAbs(val) {
+		return path.Clean(val)
+	}
+	return project.PrjAbsPath(d.rootdir, d.dir).Join(val).String()
+}
+
+func stackListValues(stack *hclwrite.Body, name string) ([]string, error) {
+	attr := stack.GetAttribute(name)
+	if attr == nil {
+		return nil, nil
+	}
+	expr, err := ast.ParseExpression(string(attr.Expr().BuildTokens(nil).Bytes()), "<edit>")
+	if err != nil {
+		return nil, errors.E(ErrEdit, err)
+	}
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !(val.Type().IsListType() || val.Type().IsTupleType() || val.Type().IsSetType()) {
+		return nil, errors.E(ErrEdit, "attribute %s.%s must be a literal list of strings to be edited",
+			hcl.StackBlockType, name)
+	}
+	var values []string
+	for it := val.ElementIterator(); it.Next(); {
+		_, elem := it.Element()
+		if elem.Type() != cty.String || elem.IsNull() {
+			return nil, errors.E(ErrEdit, "attribute %s.%s must be a literal list of strings to be edited",
+				hcl.StackBlockType, name)
+		}
+		values = append(values, elem.AsString())
+	}
+	return values, nil
+}
+
+func setStackList(stack *hclwrite.Body, name string, values []string) {
+	if len(values) == 0 {
+		stack.RemoveAttribute(name)
+		return
+	}
+	vals := make([]cty.Value, len(values))
+	for i, val := range values {
+		vals[i] = cty.StringVal(val)
+	}
+	stack.SetAttributeValue(name, cty.ListVal(vals))
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package eval
+
+import (
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TraceKind is the kind of node of an evaluation tree.
+type TraceKind string
+
+// Kinds of nodes of an evaluation tree.
+const (
+	// TraceExpr is an expression which is not a function call or a variable.
+	TraceExpr TraceKind = "expr"
+	// TraceCall is a function call.
+	TraceCall TraceKind = "call"
+	// TraceArg is a function argument which is not a function call or a variable.
+	TraceArg TraceKind = "arg"
+	// TraceVariable is a variable reference, like global.a.b.
+	TraceVariable TraceKind = "variable"
+)
+
+// Trace is a node of the evaluation tree of an expression.
+type Trace struct {
+	// Kind is the kind of the traced expression.
+	Kind TraceKind
+
+	// Expr is the traced expression.
+	Expr hhcl.Expression
+
+	// Value is the value of the expression or cty.NilVal if it failed.
+	Value cty.Value
+
+	// Err is the evaluation error of the expression, if any.
+	Err error
+
+	// Children are the traces of the function calls, function arguments and
+	// variables of the expression, in the order they appear.
+	Children []*Trace
+}
+
+// IsUnknown tells if the traced expression evaluated to a value which is not
+// wholly known.
+func (t *Trace) IsUnknown() bool {
+	return t.Err == nil && !t.Value.IsWhollyKnown()
+}
+
+// Explain evaluates the expression and returns its evaluation tree.
+// Each function call, function argument and variable of the expression is
+// evaluated on its own, so the tree tells where errors and unknowns come from,
+// even for arguments discarded by functions like tm_try.
+// Expressions inside for and splat expressions depend on their iterators and
+// are not traced.
+func (c *Context) Explain(expr hhcl.Expression) *Trace {
+	root := c.trace(TraceExpr, expr)
+	synexpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return root
+	}
+	if _, ok := synexpr.(*hclsyntax.FunctionCallExpr); ok {
+		root.Kind = TraceCall
+	} else if _, ok := synexpr.(*hclsyntax.ScopeTraversalExpr); ok {
+		root.Kind = TraceVariable
+	}
+	w := &traceWalker{
+		ctx:   c,
+		root:  synexpr,
+		stack: []*Trace{root},
+		args:  map[hclsyntax.Node]bool{},
+	}
+	_ = hclsyntax.Walk(synexpr, w)
+	return root
+}
+
+func (c *Context) trace(kind TraceKind, expr hhcl.Expression) *Trace {
+	val, err := c.Eval(expr)
+	return &Trace{
+		Kind:  kind,
+		Expr:  expr,
+		Value: val,
+		Err:   err,
+	}
+}
+
+// traceWalker builds the evaluation tree while walking the syntax tree.
+// The stack has a nil entry for each entered node which is not traced.
+type traceWalker struct {
+	ctx    *Context
+	root   hclsyntax.Node
+	stack  []*Trace
+	args   map[hclsyntax.Node]bool
+	scoped int
+}
+
+func (w *traceWalker) Enter(node hclsyntax.Node) hhcl.Diagnostics {
+	if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && w.scoped == 0 {
+		for _, arg := range call.Args {
+			w.args[arg] = true
+		}
+	}
+	if w.scoped > 0 || node == w.root {
+		w.enterScope(node)
+		w.stack = append(w.stack, nil)
+		return nil
+	}
+
+	var trace *Trace
+	switch n := node.(type) {
+	case *hclsyntax.FunctionCallExpr:
+		trace = w.ctx.trace(TraceCall, n)
+	case *hclsyntax.ScopeTraversalExpr:
+		trace = w.ctx.trace(TraceVariable, n)
+	case *hclsyntax.ForExpr, *hclsyntax.SplatExpr:
+		trace = w.ctx.trace(TraceExpr, n.(hclsyntax.Expression))
+	default:
+		if expr, ok := node.(hclsyntax.Expression); ok && w.args[node] {
+			trace = w.ctx.trace(TraceArg, expr)
+		}
+	}
+
+	w.enterScope(node)
+	if trace != nil {
+		parent := w.parent()
+		parent.Children = append(parent.Children, trace)
+	}
+	w.stack = append(w.stack, trace)
+	return nil
+}
+
+func (w *traceWalker) Exit(node hclsyntax.Node) hhcl.Diagnostics {
+	switch node.(type) {
+	case *hclsyntax.ForExpr, *hclsyntax.SplatExpr, *hclsyntax.ObjectConsKeyExpr:
+		w.scoped--
+	}
+	w.stack = w.stack[:len(w.stack)-1]
+	return nil
+}
+
+// enterScope stops the tracing of the expressions which can't be evaluated
+// on their own, like the body of for expressions and the object keys.
+func (w *traceWalker) enterScope(node hclsyntax.Node) {
+	switch node.(type) {
+	case *hclsyntax.ForExpr, *hclsyntax.SplatExpr, *hclsyntax.ObjectConsKeyExpr:
+		w.scoped++
+	}
+}
+
+func (w *traceWalker) parent() *Trace {
+	for i := len(w.stack) - 1; i >= 0; i-- {
+		if w.stack[i] != nil {
+			return w.stack[i]
+		}
+	}
+	panic("unreachable: evaluation tree without root")
+}
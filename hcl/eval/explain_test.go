@@ -0,0 +1,152 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package eval_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/terramate-io/terramate/test"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	type testcase struct {
+		name string
+		expr string
+		want []string
+	}
+
+	for _, tc := range []testcase{
+		{
+			name: "literal",
+			expr: `"a"`,
+			want: []string{
+				`expr "a" = "a"`,
+			},
+		},
+		{
+			name: "variable",
+			expr: `global.name`,
+			want: []string{
+				`variable global.name = "terramate"`,
+			},
+		},
+		{
+			name: "nested function calls",
+			expr: `tm_upper(tm_lower(global.name))`,
+			want: []string{
+				`call tm_upper(tm_lower(global.name)) = "TERRAMATE"`,
+				`  call tm_lower(global.name) = "terramate"`,
+				`    variable global.name = "terramate"`,
+			},
+		},
+		{
+			name: "function arguments",
+			expr: `tm_join("-", [global.name, "a"])`,
+			want: []string{
+				`call tm_join("-", [global.name, "a"]) = "terramate-a"`,
+				`  arg "-" = "-"`,
+				`  arg [global.name, "a"] = ["terramate","a"]`,
+				`    variable global.name = "terramate"`,
+			},
+		},
+		{
+			name: "errors discarded by tm_try",
+			expr: `tm_try(global.undefined, global.name)`,
+			want: []string{
+				`call tm_try(global.undefined, global.name) = "terramate"`,
+				`  variable global.undefined = error`,
+				`  variable global.name = "terramate"`,
+			},
+		},
+		{
+			name: "unknown values",
+			expr: `tm_upper(global.unknown)`,
+			want: []string{
+				`call tm_upper(global.unknown) = unknown`,
+				`  variable global.unknown = unknown`,
+			},
+		},
+		{
+			name: "for expressions are not traced inside",
+			expr: `[for v in global.list : tm_upper(v)]`,
+			want: []string{
+				`expr [for v in global.list : tm_upper(v)] = ["A","B"]`,
+			},
+		},
+		{
+			name: "object keys are not traced",
+			expr: `{ name = global.name }`,
+			want: []string{
+				`expr { name = global.name } = {"name":"terramate"}`,
+				`  variable global.name = "terramate"`,
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := eval.NewContext(stdlib.Functions(test.TempDir(t)))
+			ctx.SetNamespace("global", map[string]cty.Value{
+				"name":    cty.StringVal("terramate"),
+				"unknown": cty.UnknownVal(cty.String),
+				"list":    cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			})
+
+			expr, diags := hclsyntax.ParseExpression([]byte(tc.expr), "test.hcl", hhcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			var got []string
+			formatTrace(t, tc.expr, "", ctx.Explain(expr), &got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("-(want) +(got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func formatTrace(t *testing.T, src, indent string, trace *eval.Trace, lines *[]string) {
+	t.Helper()
+
+	rng := trace.Expr.Range()
+	text := src[rng.Start.Byte:rng.End.Byte]
+
+	var val string
+	switch {
+	case trace.Err != nil:
+		val = "error"
+	case trace.IsUnknown():
+		val = "unknown"
+	default:
+		data, err := ctyjsonMarshal(trace.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		val = data
+	}
+
+	*lines = append(*lines, fmt.Sprintf("%s%s %s = %s", indent, trace.Kind, text, val))
+	for _, child := range trace.Children {
+		formatTrace(t, src, indent+"  ", child, lines)
+	}
+}
+
+func ctyjsonMarshal(val cty.Value) (string, error) {
+	data, err := ctyjson.Marshal(val, val.Type())
+	return strings.TrimSpace(string(data)), err
+}
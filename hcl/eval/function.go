@@ -0,0 +1,47 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package eval
+
+import (
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// SetUserFunction sets a function in the context which evaluates the result
+// expression with the call arguments bound to variables named after params.
+// The result expression has access to all the functions of the context but to
+// no other variables.
+func (c *Context) SetUserFunction(name string, params []string, result hhcl.Expression) {
+	funcs := c.hclctx.Functions
+	specs := make([]function.Parameter, len(params))
+	for i, param := range params {
+		specs[i] = function.Parameter{
+			Name:             param,
+			Type:             cty.DynamicPseudoType,
+			AllowNull:        true,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+		}
+	}
+	c.SetFunction(name, function.New(&function.Spec{
+		Params: specs,
+		Type:   function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			vars := make(map[string]cty.Value, len(params))
+			for i, param := range params {
+				vars[param] = args[i]
+			}
+			val, diags := result.Value(&hhcl.EvalContext{
+				Variables: vars,
+				Functions: funcs,
+			})
+			if diags.HasErrors() {
+				return cty.NilVal, errors.E(ErrEval, diags)
+			}
+			return val, nil
+		},
+	}))
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/terramate-io/terramate/test"
+	errtest "github.com/terramate-io/terramate/test/errors"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestUserFunction(t *testing.T) {
+	t.Parallel()
+
+	type function struct {
+		name   string
+		params []string
+		result string
+	}
+
+	for _, tc := range []struct {
+		name  string
+		funcs []function
+		expr  string
+		want  want
+	}{
+		{
+			name: "function without params",
+			funcs: []function{
+				{name: "tm_region", result: `"us-east-1"`},
+			},
+			expr: `tm_region()`,
+			want: want{value: cty.StringVal("us-east-1")},
+		},
+		{
+			name: "params are bound to arguments",
+			funcs: []function{
+				{
+					name:   "tm_name",
+					params: []string{"env", "service"},
+					result: `tm_lower("${env}-${service}")`,
+				},
+			},
+			expr: `tm_name("PRD", global.service)`,
+			want: want{value: cty.StringVal("prd-api")},
+		},
+		{
+			name: "functions calling functions defined later",
+			funcs: []function{
+				{
+					name:   "tm_tags",
+					params: []string{"team"},
+					result: `{ team = team, name = tm_name("prd", team) }`,
+				},
+				{
+					name:   "tm_name",
+					params: []string{"env", "service"},
+					result: `"${env}-${service}"`,
+				},
+			},
+			expr: `tm_tags("core").name`,
+			want: want{value: cty.StringVal("prd-core")},
+		},
+		{
+			name: "unknown arguments",
+			funcs: []function{
+				{name: "tm_id", params: []string{"a"}, result: `a`},
+			},
+			expr: `tm_id(global.unknown)`,
+			want: want{value: cty.UnknownVal(cty.String)},
+		},
+		{
+			name: "result has no access to the caller variables",
+			funcs: []function{
+				{name: "tm_service", result: `global.service`},
+			},
+			expr: `tm_service()`,
+			want: want{err: errors.E(eval.ErrEval)},
+		},
+		{
+			name: "wrong number of arguments",
+			funcs: []function{
+				{name: "tm_id", params: []string{"a"}, result: `a`},
+			},
+			expr: `tm_id()`,
+			want: want{err: errors.E(eval.ErrEval)},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := eval.NewContext(stdlib.Functions(test.TempDir(t)))
+			ctx.SetNamespace("global", map[string]cty.Value{
+				"service": cty.StringVal("api"),
+				"unknown": cty.UnknownVal(cty.String),
+			})
+			for _, fn := range tc.funcs {
+				ctx.SetUserFunction(fn.name, fn.params, test.NewExpr(t, fn.result))
+			}
+
+			got, err := ctx.Eval(test.NewExpr(t, tc.expr))
+			errtest.Assert(t, err, tc.want.err)
+			if tc.want.err != nil {
+				return
+			}
+			if !got.RawEquals(tc.want.value) {
+				t.Fatalf("got %s but want %s", got.GoString(), tc.want.value.GoString())
+			}
+		})
+	}
+}
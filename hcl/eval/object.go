@@ -6,6 +6,7 @@ package eval
 import (
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/hcl/fmt"
+	"github.com/terramate-io/terramate/hcl/info"
 	"github.com/terramate-io/terramate/project"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -68,6 +69,15 @@ type (
 
 		// DefinedAt provides the source file where the value is defined.
 		DefinedAt project.Path
+
+		// ConfigDir is the directory of the configuration which loaded the
+		// value, used to report its provenance. It may be empty if the value
+		// has no source code counterpart.
+		ConfigDir project.Path
+
+		// Range provides the exact source range where the value is defined.
+		// It may be empty if the value has no source code counterpart.
+		Range info.Range
 	}
 
 	// ObjectPath represents a path inside the object.
@@ -123,7 +133,7 @@ func (obj *Object) SetFrom(values map[string]Value) *Object {
 // SetFromCtyValues sets the object from the values map.
 func (obj *Object) SetFromCtyValues(values map[string]cty.Value, origin Info) *Object {
 	for k, v := range values {
-		if v.Type().IsObjectType() {
+		if v.Type().IsObjectType() && !v.IsMarked() {
 			subtree := NewObject(origin)
 			subtree.SetFromCtyValues(v.AsValueMap(), origin)
 			obj.Set(k, subtree)
@@ -303,8 +313,13 @@ func (obj *Object) AsValueMap() map[string]cty.Value {
 }
 
 // String representation of the object.
+// Sensitive values are redacted.
 func (obj *Object) String() string {
-	return fmt.FormatAttributes(obj.AsValueMap())
+	vmap := obj.AsValueMap()
+	for k, v := range vmap {
+		vmap[k] = Redact(v)
+	}
+	return fmt.FormatAttributes(vmap)
 }
 
 // NewCtyValue creates a new cty.Value wrapper.
@@ -321,7 +336,7 @@ func NewCtyValue(val cty.Value, origin Info) CtyValue {
 // NewValue returns a new object Value from a cty.Value.
 // Note: this is not a wrapper as it returns an [Object] if val is a cty.Object.
 func NewValue(val cty.Value, origin Info) Value {
-	if val.Type().IsObjectType() {
+	if val.Type().IsObjectType() && !val.IsMarked() {
 		obj := NewObject(origin)
 		obj.SetFromCtyValues(val.AsValueMap(), origin)
 		return obj
@@ -335,8 +350,14 @@ func (v CtyValue) Info() Info { return v.origin }
 // IsObject returns false for CtyValue values.
 func (v CtyValue) IsObject() bool { return false }
 
-// Raw returns the original cty.Value value (unmarked).
+// Raw returns the original cty.Value value, without the origin marks.
+// Other marks, like the [SensitiveMark], are preserved.
 func (v CtyValue) Raw() cty.Value {
-	val, _ := v.Value.Unmark()
-	return val
+	val, marks := v.Value.Unmark()
+	for mark := range marks {
+		if _, ok := mark.(Info); ok {
+			delete(marks, mark)
+		}
+	}
+	return val.WithMarks(marks)
 }
@@ -0,0 +1,104 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package eval
+
+import (
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ErrSensitive indicates that a sensitive value would be exposed.
+const ErrSensitive errors.Kind = "sensitive value"
+
+// SensitiveMark is the mark of sensitive values, set by the tm_sensitive
+// function. Marks are preserved by the operations and function calls on the
+// values, so any value derived from a sensitive value is also sensitive.
+const SensitiveMark = "sensitive"
+
+// RedactedValue is the value shown in place of sensitive values.
+const RedactedValue = "(sensitive value)"
+
+// IsSensitive tells if the value or any value nested in it is sensitive.
+func IsSensitive(val cty.Value) bool {
+	if !val.ContainsMarked() {
+		return false
+	}
+	_, pvm := val.UnmarkDeepWithPaths()
+	for _, pm := range pvm {
+		if _, ok := pm.Marks[SensitiveMark]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns the value with all its sensitive values replaced with the
+// [RedactedValue] string and without any other marks. Collections containing
+// sensitive values are converted to tuples and objects, as the redacted
+// values may not have the type of the collection elements.
+func Redact(val cty.Value) cty.Value {
+	if val.HasMark(SensitiveMark) {
+		return cty.StringVal(RedactedValue)
+	}
+	val, _ = val.Unmark()
+	if !IsSensitive(val) {
+		val, _ = val.UnmarkDeep()
+		return val
+	}
+
+	ty := val.Type()
+	switch {
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		var elems []cty.Value
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			elems = append(elems, Redact(elem))
+		}
+		return cty.TupleVal(elems)
+	case ty.IsMapType() || ty.IsObjectType():
+		attrs := map[string]cty.Value{}
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			attrs[key.AsString()] = Redact(elem)
+		}
+		return cty.ObjectVal(attrs)
+	}
+	// any other value with nested sensitive values is redacted as a whole.
+	return cty.StringVal(RedactedValue)
+}
+
+// CheckSensitive returns an error of kind [ErrSensitive] if the expression,
+// possibly the result of a partial evaluation, has sensitive values.
+func CheckSensitive(expr hhcl.Expression) error {
+	synexpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return nil
+	}
+	var err error
+	_ = hclsyntax.VisitAll(synexpr, func(node hclsyntax.Node) hhcl.Diagnostics {
+		lit, ok := node.(*hclsyntax.LiteralValueExpr)
+		if ok && err == nil && IsSensitive(lit.Val) {
+			err = errors.E(ErrSensitive, lit.Range())
+		}
+		return nil
+	})
+	return err
+}
+
+// RedactExpr redacts the sensitive values of the expression, possibly the
+// result of a partial evaluation. The expression is modified in place.
+func RedactExpr(expr hhcl.Expression) {
+	synexpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return
+	}
+	_ = hclsyntax.VisitAll(synexpr, func(node hclsyntax.Node) hhcl.Diagnostics {
+		if lit, ok := node.(*hclsyntax.LiteralValueExpr); ok && IsSensitive(lit.Val) {
+			lit.Val = Redact(lit.Val)
+		}
+		return nil
+	})
+}
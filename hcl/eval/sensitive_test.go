@@ -0,0 +1,133 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/eval"
+	errtest "github.com/terramate-io/terramate/test/errors"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestRedactSensitive(t *testing.T) {
+	t.Parallel()
+
+	secret := cty.StringVal("secret").Mark(eval.SensitiveMark)
+	redacted := cty.StringVal(eval.RedactedValue)
+
+	for _, tc := range []struct {
+		name          string
+		val           cty.Value
+		wantSensitive bool
+		want          cty.Value
+	}{
+		{
+			name: "not sensitive",
+			val:  cty.StringVal("a"),
+			want: cty.StringVal("a"),
+		},
+		{
+			name: "other marks are removed",
+			val:  cty.StringVal("a").Mark("other"),
+			want: cty.StringVal("a"),
+		},
+		{
+			name:          "sensitive string",
+			val:           secret,
+			wantSensitive: true,
+			want:          redacted,
+		},
+		{
+			name:          "sensitive object",
+			val:           cty.ObjectVal(map[string]cty.Value{"a": cty.NumberIntVal(1)}).Mark(eval.SensitiveMark),
+			wantSensitive: true,
+			want:          redacted,
+		},
+		{
+			name: "list with sensitive element",
+			val: cty.ListVal([]cty.Value{
+				cty.StringVal("a"), secret,
+			}),
+			wantSensitive: true,
+			want: cty.TupleVal([]cty.Value{
+				cty.StringVal("a"), redacted,
+			}),
+		},
+		{
+			name: "nested sensitive number",
+			val: cty.MapVal(map[string]cty.Value{
+				"a": cty.ObjectVal(map[string]cty.Value{
+					"port":  cty.NumberIntVal(22).Mark(eval.SensitiveMark),
+					"proto": cty.StringVal("tcp"),
+				}),
+			}),
+			wantSensitive: true,
+			want: cty.ObjectVal(map[string]cty.Value{
+				"a": cty.ObjectVal(map[string]cty.Value{
+					"port":  redacted,
+					"proto": cty.StringVal("tcp"),
+				}),
+			}),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := eval.IsSensitive(tc.val); got != tc.wantSensitive {
+				t.Fatalf("IsSensitive() = %t but want %t", got, tc.wantSensitive)
+			}
+			got := eval.Redact(tc.val)
+			if got.ContainsMarked() {
+				t.Fatalf("redacted value %s has marks", got.GoString())
+			}
+			if !got.RawEquals(tc.want) {
+				t.Fatalf("Redact() = %s but want %s", got.GoString(), tc.want.GoString())
+			}
+		})
+	}
+}
+
+func TestSensitiveExpr(t *testing.T) {
+	t.Parallel()
+
+	ctx := eval.NewContext(nil)
+	ctx.SetNamespace("global", map[string]cty.Value{
+		"secret": cty.StringVal("secret").Mark(eval.SensitiveMark),
+		"name":   cty.StringVal("name"),
+	})
+
+	expr, err := ast.ParseExpression(`{
+		a = global.name
+		b = global.secret
+		c = unknown.ref
+	}`, "test.hcl")
+	assert.NoError(t, err)
+
+	nonSensitive, err := ast.ParseExpression(`[global.name, unknown.ref]`, "test.hcl")
+	assert.NoError(t, err)
+	partial, err := ctx.PartialEval(nonSensitive)
+	assert.NoError(t, err)
+	assert.NoError(t, eval.CheckSensitive(partial))
+
+	partial, err = ctx.PartialEval(expr)
+	assert.NoError(t, err)
+	errtest.Assert(t, eval.CheckSensitive(partial), errors.E(eval.ErrSensitive))
+
+	eval.RedactExpr(partial)
+	assert.NoError(t, eval.CheckSensitive(partial))
+
+	want := `{
+		a = "name"
+		b = "(sensitive value)"
+		c = unknown.ref
+	}`
+	got := ast.TokensForExpression(partial)
+	assert.EqualStrings(t, string(hclwrite.Format([]byte(want))), string(hclwrite.Format(got.Bytes())))
+}
@@ -9,12 +9,17 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/fs"
+	"golang.org/x/exp/slices"
 )
 
 // ErrHCLSyntax is the error kind for syntax errors.
@@ -26,9 +31,31 @@ const ErrReadFile errors.Kind = "failed to read file"
 // FormatResult represents the result of a formatting operation.
 type FormatResult struct {
 	path      string
+	original  string
 	formatted string
 }
 
+// Options are the formatting options.
+type Options struct {
+	// SortStackAttributes sorts the attributes of the stack blocks in the
+	// canonical order. See StackAttributesOrder.
+	SortStackAttributes bool
+}
+
+// StackAttributesOrder is the canonical order of the stack block attributes.
+// Unknown attributes are placed after them, keeping their original order.
+var StackAttributesOrder = []string{
+	"name",
+	"description",
+	"id",
+	"tags",
+	"after",
+	"before",
+	"wants",
+	"wanted_by",
+	"watch",
+}
+
 // FormatMultiline will format the given source code.
 // It enforces lists to be formatted as multiline, where each
 // element on the list resides on its own line followed by a comma.
@@ -46,10 +73,22 @@ func FormatMultiline(src, filename string) (string, error) {
 // Format will format the given source code using hcl.Format.
 // It returns an error if the given source is invalid HCL.
 func Format(src, filename string) (string, error) {
+	return FormatWithOptions(src, filename, Options{})
+}
+
+// FormatWithOptions is like Format but using the given options.
+func FormatWithOptions(src, filename string, opts Options) (string, error) {
 	parsed, diags := hclwrite.ParseConfig([]byte(src), filename, hcl.InitialPos)
 	if diags.HasErrors() {
 		return "", errors.E(ErrHCLSyntax, diags)
 	}
+	if opts.SortStackAttributes {
+		for _, block := range parsed.Body().Blocks() {
+			if block.Type() == "stack" {
+				sortStackAttributes(block.Body())
+			}
+		}
+	}
 	return string(hclwrite.Format(parsed.Bytes())), nil
 }
 
@@ -65,6 +104,11 @@ func Format(src, filename string) (string, error) {
 // All files will be left untouched. To save the formatted result on disk you
 // can use FormatResult.Save for each FormatResult.
 func FormatTree(dir string) ([]FormatResult, error) {
+	return FormatTreeWithOptions(dir, Options{})
+}
+
+// FormatTreeWithOptions is like FormatTree but using the given options.
+func FormatTreeWithOptions(dir string, opts Options) ([]FormatResult, error) {
 	files, err := fs.ListTerramateFiles(dir)
 	if err != nil {
 		return nil, errors.E(errFormatTree, err)
@@ -72,7 +116,7 @@ func FormatTree(dir string) ([]FormatResult, error) {
 	sort.Strings(files)
 
 	errs := errors.L()
-	results, err := FormatFiles(dir, files)
+	results, err := FormatFilesWithOptions(dir, files, opts)
 
 	errs.Append(err)
 
@@ -85,7 +129,7 @@ func FormatTree(dir string) ([]FormatResult, error) {
 	sort.Strings(dirs)
 
 	for _, d := range dirs {
-		subres, err := FormatTree(filepath.Join(dir, d))
+		subres, err := FormatTreeWithOptions(filepath.Join(dir, d), opts)
 		if err != nil {
 			errs.Append(err)
 			continue
@@ -114,6 +158,11 @@ func FormatTree(dir string) ([]FormatResult, error) {
 // All files will be left untouched. To save the formatted result on disk you
 // can use FormatResult.Save for each FormatResult.
 func FormatFiles(basedir string, files []string) ([]FormatResult, error) {
+	return FormatFilesWithOptions(basedir, files, Options{})
+}
+
+// FormatFilesWithOptions is like FormatFiles but using the given options.
+func FormatFilesWithOptions(basedir string, files []string, opts Options) ([]FormatResult, error) {
 	results := []FormatResult{}
 	errs := errors.L()
 
@@ -128,7 +177,7 @@ func FormatFiles(basedir string, files []string) ([]FormatResult, error) {
 			continue
 		}
 		currentCode := string(fileContents)
-		formatted, err := Format(currentCode, fname)
+		formatted, err := FormatWithOptions(currentCode, fname, opts)
 		if err != nil {
 			errs.Append(err)
 			continue
@@ -138,6 +187,7 @@ func FormatFiles(basedir string, files []string) ([]FormatResult, error) {
 		}
 		results = append(results, FormatResult{
 			path:      fname,
+			original:  currentCode,
 			formatted: formatted,
 		})
 	}
@@ -163,6 +213,208 @@ func (f FormatResult) Formatted() string {
 	return f.formatted
 }
 
+// Original is the contents of the original file before formatting.
+func (f FormatResult) Original() string {
+	return f.original
+}
+
+// Diff returns the formatting changes as an unified diff, using the given
+// name as the file name.
+func (f FormatResult) Diff(name string) string {
+	return Diff(name, f.original, f.formatted)
+}
+
+// Diff returns the changes from original to changed as an unified diff of the
+// named file, using the a/ and b/ prefixes of git.
+func Diff(name, original, changed string) string {
+	edits := myers.ComputeEdits(span.URIFromPath(name), original, changed)
+	return fmt.Sprint(gotextdiff.ToUnified("a/"+name, "b/"+name, original, edits))
+}
+
+// KeepFormatting returns the edited content with the lines not touched by the
+// edits copied verbatim from the original content. This is needed because
+// hclwrite normalizes the whitespace of the whole file, turning tabs into
+// spaces and realigning the attributes, and programmatic edits must not
+// reformat the file. The lines whose only change is their whitespace are kept
+// as they were and the new lines take the indentation of their neighbouring
+// lines.
+func KeepFormatting(original, edited []byte) []byte {
+	normalized, diags := hclwrite.ParseConfig(original, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return edited
+	}
+	origLines := splitLines(string(original))
+	normLines := splitLines(string(normalized.Bytes()))
+	if len(origLines) != len(normLines) {
+		return edited
+	}
+
+	edits := myers.ComputeEdits(span.URIFromPath(""), string(normalized.Bytes()), string(edited))
+	unified := gotextdiff.ToUnified("", "", string(normalized.Bytes()), edits)
+
+	var out strings.Builder
+	next := 0
+	for _, hunk := range unified.Hunks {
+		for ; next < hunk.FromLine-1; next++ {
+			out.WriteString(origLines[next])
+		}
+		// the deleted lines are kept if they are inserted back with only
+		// whitespace changes.
+		var deleted []int
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case gotextdiff.Equal:
+				out.WriteString(origLines[next])
+				next++
+				deleted = nil
+			case gotextdiff.Delete:
+				deleted = append(deleted, next)
+				next++
+			case gotextdiff.Insert:
+				kept := -1
+				for i, lineno := range deleted {
+					if sameTokens(normLines[lineno], line.Content) {
+						kept = i
+						break
+					}
+				}
+				if kept >= 0 {
+					out.WriteString(origLines[deleted[kept]])
+					deleted = append(deleted[:kept:kept], deleted[kept+1:]...)
+					continue
+				}
+				out.WriteString(reindent(line.Content, origLines, normLines, next))
+			}
+		}
+	}
+	for ; next < len(origLines); next++ {
+		out.WriteString(origLines[next])
+	}
+	return []byte(out.String())
+}
+
+// reindent returns the line, indented by hclwrite, with the indentation style
+// of the original lines nearest to the line number at.
+func reindent(line string, origLines, normLines []string, at int) string {
+	level := len(indentation(line)) / 2
+	if level == 0 {
+		return line
+	}
+	for dist := 0; dist <= len(origLines); dist++ {
+		for _, i := range []int{at - 1 - dist, at + dist} {
+			if i < 0 || i >= len(origLines) {
+				continue
+			}
+			unit, ok := indentUnit(indentation(origLines[i]), len(indentation(normLines[i]))/2)
+			if ok {
+				return strings.Repeat(unit, level) + strings.TrimLeft(line, " \t")
+			}
+		}
+	}
+	return line
+}
+
+// indentUnit returns the indentation of a single nesting level, given the
+// indentation of a line nested at the given level.
+func indentUnit(indent string, level int) (string, bool) {
+	if level == 0 || len(indent)%level != 0 {
+		return "", false
+	}
+	unit := indent[:len(indent)/level]
+	if unit == "" || strings.Repeat(unit, level) != indent {
+		return "", false
+	}
+	return unit, true
+}
+
+// sameTokens tells if the lines only differ in their whitespace.
+func sameTokens(a, b string) bool {
+	return slices.Equal(strings.Fields(a), strings.Fields(b))
+}
+
+func indentation(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// sortStackAttributes sorts the attributes of the stack body in the canonical
+// order. The attributes are moved with their comments and the other tokens of
+// the body are kept in place. Bodies with blocks are left untouched, as they
+// are invalid stack configurations.
+func sortStackAttributes(body *hclwrite.Body) {
+	if len(body.Blocks()) > 0 {
+		return
+	}
+
+	attrOf := map[*hclwrite.Token]*hclwrite.Attribute{}
+	for _, attr := range body.Attributes() {
+		for _, tok := range attr.BuildTokens(nil) {
+			attrOf[tok] = attr
+		}
+	}
+
+	// the body tokens are split in attribute slots and the tokens between them.
+	type segment struct {
+		attr   *hclwrite.Attribute
+		tokens hclwrite.Tokens
+	}
+	var (
+		segments []segment
+		attrs    []*hclwrite.Attribute
+	)
+	for _, tok := range body.BuildTokens(nil) {
+		attr := attrOf[tok]
+		if attr == nil || len(segments) == 0 || segments[len(segments)-1].attr != attr {
+			segments = append(segments, segment{attr: attr})
+			if attr != nil {
+				attrs = append(attrs, attr)
+			}
+		}
+		segments[len(segments)-1].tokens = append(segments[len(segments)-1].tokens, tok)
+	}
+
+	rank := func(attr *hclwrite.Attribute) int {
+		var name string
+		for _, tok := range attr.BuildTokens(nil) {
+			if tok.Type == hclsyntax.TokenIdent {
+				name = string(tok.Bytes)
+				break
+			}
+		}
+		for i, known := range StackAttributesOrder {
+			if known == name {
+				return i
+			}
+		}
+		return len(StackAttributesOrder)
+	}
+	sorted := make([]*hclwrite.Attribute, len(attrs))
+	copy(sorted, attrs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+
+	var tokens hclwrite.Tokens
+	next := 0
+	for _, seg := range segments {
+		if seg.attr == nil {
+			tokens = append(tokens, seg.tokens...)
+			continue
+		}
+		tokens = append(tokens, sorted[next].BuildTokens(nil)...)
+		next++
+	}
+	body.Clear()
+	body.AppendUnstructuredTokens(tokens)
+}
+
 const (
 	errFormatTree errors.Kind = "formatting tree"
 )
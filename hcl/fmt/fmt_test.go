@@ -1506,6 +1506,144 @@ a = 1
 	assert.EqualInts(t, 0, len(got), "want no results, got: %v", got)
 }
 
+func TestFormatSortStackAttributes(t *testing.T) {
+	t.Parallel()
+
+	type testcase struct {
+		name  string
+		input string
+		want  string
+	}
+
+	for _, tc := range []testcase{
+		{
+			name: "already sorted",
+			input: `stack {
+  name        = "name"
+  description = "desc"
+  id          = "id"
+}
+`,
+			want: `stack {
+  name        = "name"
+  description = "desc"
+  id          = "id"
+}
+`,
+		},
+		{
+			name: "all known attributes",
+			input: `stack {
+  watch       = ["/file"]
+  wanted_by   = ["/a"]
+  wants       = ["/b"]
+  before      = ["/c"]
+  after       = ["/d"]
+  tags        = ["tag"]
+  id          = "id"
+  description = "desc"
+  name        = "name"
+}
+`,
+			want: `stack {
+  name        = "name"
+  description = "desc"
+  id          = "id"
+  tags        = ["tag"]
+  after       = ["/d"]
+  before      = ["/c"]
+  wants       = ["/b"]
+  wanted_by   = ["/a"]
+  watch       = ["/file"]
+}
+`,
+		},
+		{
+			name: "comments and blank lines are kept",
+			input: `# stack config
+stack {
+  # the id
+  id = "id" # unique
+
+  name = "name"
+}
+`,
+			want: `# stack config
+stack {
+  name = "name"
+
+  # the id
+  id = "id" # unique
+}
+`,
+		},
+		{
+			name: "unknown attributes go last in the original order",
+			input: `stack {
+  b    = 1
+  name = "name"
+  a    = 2
+}
+`,
+			want: `stack {
+  name = "name"
+  b    = 1
+  a    = 2
+}
+`,
+		},
+		{
+			name: "other blocks are not sorted",
+			input: `globals {
+  name = "name"
+  id   = "id"
+}
+`,
+			want: `globals {
+  name = "name"
+  id   = "id"
+}
+`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := fmt.FormatWithOptions(tc.input, "stack.tm", fmt.Options{
+				SortStackAttributes: true,
+			})
+			assert.NoError(t, err)
+			assert.EqualStrings(t, tc.want, got)
+
+			unsorted, err := fmt.Format(tc.input, "stack.tm")
+			assert.NoError(t, err)
+			assert.EqualStrings(t, tc.input, unsorted, "sorting must be opt-in")
+		})
+	}
+}
+
+func TestFormatResultDiff(t *testing.T) {
+	t.Parallel()
+
+	tmpdir := test.TempDir(t)
+	test.WriteFile(t, tmpdir, "file.tm", "globals {\n a = 1\n}\n")
+
+	got, err := fmt.FormatFiles(tmpdir, []string{"file.tm"})
+	assert.NoError(t, err)
+	assert.EqualInts(t, 1, len(got))
+
+	assert.EqualStrings(t, "globals {\n a = 1\n}\n", got[0].Original())
+	assert.EqualStrings(t, `--- a/file.tm
++++ b/file.tm
+@@ -1,3 +1,3 @@
+ globals {
+- a = 1
++  a = 1
+ }
+`, got[0].Diff("file.tm"))
+}
+
 func assertFileContains(t *testing.T, filepath, got string) {
 	t.Helper()
 
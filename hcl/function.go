@@ -0,0 +1,211 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/slices"
+)
+
+// FunctionBlockType is the block type used to declare user defined functions.
+const FunctionBlockType = "function"
+
+// Function represents a function block, which declares a function that can be
+// used in any Terramate expression of the project.
+type Function struct {
+	// Range is the range of the entire block definition.
+	Range info.Range
+
+	// Name is the name of the function, which is always prefixed with tm_.
+	Name string
+
+	// Params are the names of the function parameters. The arguments of a
+	// call are available in the result expression as variables with these names.
+	Params []string
+
+	// Result is the expression evaluated on each call.
+	Result hcl.Expression
+}
+
+// projectFunctions are the Terramate functions which query the project and
+// are not part of the stdlib.
+var projectFunctions = []string{"tm_stacks", "tm_stack_globals"}
+
+func (p *TerramateParser) parseFunctionBlock(block *ast.Block) (Function, error) {
+	errs := errors.L()
+	if p.dir != p.rootdir {
+		errs.Append(errors.E(ErrTerramateSchema, block.DefRange(),
+			"%s blocks are only allowed at the project root directory", FunctionBlockType))
+	}
+
+	if len(block.Labels) != 1 {
+		errs.Append(errors.E(ErrTerramateSchema, block.DefRange(),
+			"%s block must have a single label with the function name", FunctionBlockType))
+		return Function{}, errs.AsError()
+	}
+
+	name := block.Labels[0]
+	if !hclsyntax.ValidIdentifier(name) || !strings.HasPrefix(name, "tm_") || name == "tm_" {
+		errs.Append(errors.E(ErrTerramateSchema, block.LabelRanges(),
+			"function name must be a valid identifier prefixed with tm_ but got %q", name))
+	} else if _, ok := p.evalctx.Unwrap().Functions[name]; ok || slices.Contains(projectFunctions, name) {
+		errs.Append(errors.E(ErrTerramateSchema, block.LabelRanges(),
+			"function %s redefines a Terramate function", name))
+	}
+
+	schema := mustSchemaBlock(FunctionBlockType).bodySchema()
+
+	_, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		errs.Append(errors.E(ErrTerramateSchema, diags))
+	}
+
+	fn := Function{
+		Range: block.Range,
+		Name:  name,
+	}
+
+	if attr, ok := block.Attributes["params"]; ok {
+		params, err := parseFunctionParams(name, attr)
+		errs.Append(err)
+		fn.Params = params
+	}
+
+	if attr, ok := block.Attributes["result"]; ok {
+		fn.Result = attr.Expr
+		for _, traversal := range attr.Expr.Variables() {
+			if !slices.Contains(fn.Params, traversal.RootName()) {
+				errs.Append(errors.E(ErrTerramateSchema, traversal.SourceRange(),
+					"function %s: %q is not a parameter of the function",
+					name, traversal.RootName()))
+			}
+		}
+	}
+
+	if err := errs.AsError(); err != nil {
+		return Function{}, err
+	}
+	return fn, nil
+}
+
+func parseFunctionParams(name string, attr ast.Attribute) ([]string, error) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !(val.Type().IsTupleType() || val.Type().IsListType()) {
+		return nil, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"function %s: params must be a list of literal strings", name)
+	}
+
+	errs := errors.L()
+	var params []string
+	for _, param := range val.AsValueSlice() {
+		if param.Type() != cty.String || param.IsNull() {
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"function %s: params must be a list of literal strings", name))
+			continue
+		}
+		p := param.AsString()
+		if !hclsyntax.ValidIdentifier(p) {
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"function %s: param %q is not a valid identifier", name, p))
+			continue
+		}
+		if slices.Contains(params, p) {
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"function %s: param %q is duplicated", name, p))
+			continue
+		}
+		params = append(params, p)
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// checkFunctionsRecursion checks that no function calls itself, directly or
+// through other functions.
+func checkFunctionsRecursion(funcs []Function) error {
+	calls := map[string][]string{}
+	byName := map[string]Function{}
+	for _, fn := range funcs {
+		byName[fn.Name] = fn
+		calls[fn.Name] = functionCalls(fn.Result)
+	}
+
+	errs := errors.L()
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var visit func(name string, stack []string)
+	visit = func(name string, stack []string) {
+		switch state[name] {
+		case visited:
+			return
+		case visiting:
+			var cycle []string
+			for i, n := range stack {
+				if n == name {
+					cycle = append(cycle, stack[i:]...)
+					break
+				}
+			}
+			cycle = append(cycle, name)
+			errs.Append(errors.E(ErrTerramateSchema, byName[cycle[0]].Range,
+				"function %s is recursive: %s", cycle[0], strings.Join(cycle, " -> ")))
+			return
+		}
+		state[name] = visiting
+		for _, callee := range calls[name] {
+			if _, ok := byName[callee]; ok {
+				visit(callee, append(stack, name))
+			}
+		}
+		state[name] = visited
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(name, nil)
+	}
+	return errs.AsError()
+}
+
+// functionCalls returns the names of the functions called by the expression.
+func functionCalls(expr hcl.Expression) []string {
+	synexpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return nil
+	}
+	var names []string
+	_ = hclsyntax.VisitAll(synexpr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok {
+			names = append(names, call.Name)
+		}
+		return nil
+	})
+	return names
+}
+
+func findFunction(funcs []Function, name string) (Function, bool) {
+	for _, fn := range funcs {
+		if fn.Name == name {
+			return fn, true
+		}
+	}
+	return Function{}, false
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// GlobalsDataFileBlockType is the type of the globals sub-block which loads
+// globals from a data file.
+const GlobalsDataFileBlockType = "data_file"
+
+// Supported formats of globals data files.
+const (
+	DataFileJSON   = "json"
+	DataFileYAML   = "yaml"
+	DataFileTFVars = "tfvars"
+)
+
+// GlobalsDataFile represents a data_file block of a globals block, which
+// defines globals from the top-level keys of a JSON, YAML or tfvars file.
+type GlobalsDataFile struct {
+	// Range is the range of the data_file block.
+	Range info.Range
+
+	// Labels are the labels of the parent globals block.
+	Labels []string
+
+	// Path is the project path of the data file.
+	Path project.Path
+
+	// HostPath is the absolute host path of the data file.
+	HostPath string
+
+	// Format is the format of the data file, which is either explicitly
+	// set or inferred from the file extension.
+	Format string
+}
+
+// parseGlobalsDataFiles parses the data_file blocks of the given globals block.
+func parseGlobalsDataFiles(rootdir string, globals *ast.MergedBlock) ([]GlobalsDataFile, error) {
+	errs := errors.L()
+	var res []GlobalsDataFile
+	for _, raw := range globals.RawOrigins {
+		for _, block := range raw.Blocks {
+			if block.Type != GlobalsDataFileBlockType {
+				continue
+			}
+			datafile, err := parseGlobalsDataFile(rootdir, globals.Labels, block)
+			if err != nil {
+				errs.Append(err)
+				continue
+			}
+			res = append(res, datafile)
+		}
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseGlobalsDataFile(rootdir string, labels []string, block *ast.Block) (GlobalsDataFile, error) {
+	err := validateGlobalsDataFileBlock(block)
+	if err != nil {
+		return GlobalsDataFile{}, err
+	}
+
+	attr := block.Body.Attributes["path"]
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.Type() != cty.String || val.IsNull() {
+		return GlobalsDataFile{}, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"globals.data_file.path must be a literal string")
+	}
+
+	datapath := val.AsString()
+	if datapath == "" {
+		return GlobalsDataFile{}, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"globals.data_file.path can't be empty")
+	}
+
+	var hostpath string
+	if path.IsAbs(datapath) {
+		hostpath = filepath.Join(rootdir, filepath.FromSlash(datapath))
+	} else {
+		hostpath = filepath.Join(filepath.Dir(block.Range.HostPath()), filepath.FromSlash(datapath))
+	}
+
+	if hostpath != rootdir && !strings.HasPrefix(hostpath, rootdir+string(filepath.Separator)) {
+		return GlobalsDataFile{}, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"globals.data_file.path %q is outside the project", datapath)
+	}
+
+	var format string
+	if attr, ok := block.Body.Attributes["format"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.Type() != cty.String || val.IsNull() {
+			return GlobalsDataFile{}, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"globals.data_file.format must be a literal string")
+		}
+		format = val.AsString()
+	} else {
+		format = dataFileFormat(datapath)
+	}
+
+	switch format {
+	case DataFileJSON, DataFileYAML, DataFileTFVars:
+	case "":
+		return GlobalsDataFile{}, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"unable to infer the format of globals.data_file.path %q: "+
+				"use one of the .json, .yaml, .yml or .tfvars extensions or set the format attribute",
+			datapath)
+	default:
+		return GlobalsDataFile{}, errors.E(ErrTerramateSchema, block.Body.Attributes["format"].Expr.Range(),
+			"globals.data_file.format must be one of %q, %q or %q but got %q",
+			DataFileJSON, DataFileYAML, DataFileTFVars, format)
+	}
+
+	return GlobalsDataFile{
+		Range:    block.Range,
+		Labels:   labels,
+		Path:     project.PrjAbsPath(rootdir, hostpath),
+		HostPath: hostpath,
+		Format:   format,
+	}, nil
+}
+
+func validateGlobalsDataFileBlock(block *ast.Block) error {
+	errs := errors.L()
+	if len(block.Labels) != 0 {
+		errs.Append(errors.E(ErrTerramateSchema, block.LabelRanges(),
+			"globals.data_file block must have no labels"))
+	}
+
+	schema := mustSchemaBlock("globals", GlobalsDataFileBlockType).bodySchema()
+
+	_, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		errs.Append(errors.E(ErrTerramateSchema, diags))
+	}
+	return errs.AsError()
+}
+
+func dataFileFormat(datapath string) string {
+	switch {
+	case strings.HasSuffix(datapath, ".json"):
+		return DataFileJSON
+	case strings.HasSuffix(datapath, ".yaml"), strings.HasSuffix(datapath, ".yml"):
+		return DataFileYAML
+	case strings.HasSuffix(datapath, ".tfvars"):
+		return DataFileTFVars
+	default:
+		return ""
+	}
+}
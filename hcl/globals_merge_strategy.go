@@ -0,0 +1,99 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// GlobalsMergeStrategyBlockType is the type of the globals sub-block which
+// sets the merge strategy of the globals defined in the block.
+const GlobalsMergeStrategyBlockType = "merge_strategy"
+
+// MergeStrategy defines how a global is combined with the definitions of
+// the same global in parent directories.
+type MergeStrategy string
+
+// Available merge strategies.
+const (
+	// MergeStrategyOverride replaces the parent definition. It's the default.
+	MergeStrategyOverride MergeStrategy = "override"
+	// MergeStrategyDeepMerge deep merges the object with the parent object.
+	MergeStrategyDeepMerge MergeStrategy = "deep_merge"
+	// MergeStrategyAppend appends the list to the parent list.
+	MergeStrategyAppend MergeStrategy = "append"
+	// MergeStrategyFinal forbids child directories to override the global.
+	MergeStrategyFinal MergeStrategy = "final"
+)
+
+// GlobalsMergeStrategies returns the merge strategy of each global attribute
+// of the globals block which has an explicit merge strategy.
+// The block must be already validated.
+func GlobalsMergeStrategies(block *ast.MergedBlock) map[string]MergeStrategy {
+	res := map[string]MergeStrategy{}
+	strategyBlock, ok := block.Blocks[ast.NewEmptyLabelBlockType(GlobalsMergeStrategyBlockType)]
+	if !ok {
+		return res
+	}
+	for name, attr := range strategyBlock.Attributes {
+		val, _ := attr.Expr.Value(nil)
+		res[name] = MergeStrategy(val.AsString())
+	}
+	return res
+}
+
+func validateGlobalsMergeStrategy(globals *ast.MergedBlock, block *ast.Block) error {
+	errs := errors.L()
+	if len(block.Labels) != 0 {
+		errs.Append(errors.E(ErrTerramateSchema, block.LabelRanges(),
+			"globals.%s block must have no labels", GlobalsMergeStrategyBlockType))
+	}
+	if len(block.Blocks) != 0 {
+		errs.Append(errors.E(ErrTerramateSchema, block.Blocks[0].DefRange(),
+			"globals.%s block must only have attributes", GlobalsMergeStrategyBlockType))
+	}
+
+	for _, attr := range block.Attributes.SortedList() {
+		_, isAttr := globals.Attributes[attr.Name]
+		if !isAttr && !hasMapBlock(globals, attr.Name) {
+			errs.Append(errors.E(ErrTerramateSchema, attr.NameRange,
+				"globals.%s.%s doesn't refer to a global of the block",
+				GlobalsMergeStrategyBlockType, attr.Name))
+			continue
+		}
+
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || val.Type() != cty.String || val.IsNull() {
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"globals.%s.%s must be a literal string",
+				GlobalsMergeStrategyBlockType, attr.Name))
+			continue
+		}
+
+		switch MergeStrategy(val.AsString()) {
+		case MergeStrategyOverride, MergeStrategyDeepMerge,
+			MergeStrategyAppend, MergeStrategyFinal:
+		default:
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"globals.%s.%s must be one of %q, %q, %q or %q but got %q",
+				GlobalsMergeStrategyBlockType, attr.Name,
+				MergeStrategyOverride, MergeStrategyDeepMerge,
+				MergeStrategyAppend, MergeStrategyFinal, val.AsString()))
+		}
+	}
+	return errs.AsError()
+}
+
+func hasMapBlock(globals *ast.MergedBlock, name string) bool {
+	for _, raw := range globals.RawOrigins {
+		for _, block := range raw.Blocks {
+			if block.Type == "map" && len(block.Labels) > 0 && block.Labels[0] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// GlobalsSchemaBlockType is the block type used to declare the schema of a
+// global.
+const GlobalsSchemaBlockType = "globals_schema"
+
+// GlobalSchema represents a globals_schema block, which declares the type
+// constraint and documentation of a single global.
+type GlobalSchema struct {
+	// Range is the range of the entire block definition.
+	Range info.Range
+
+	// Path is the global path declared by the block labels.
+	// Eg.: globals_schema "aws" "region" {} declares global.aws.region.
+	Path []string
+
+	// Type is the type constraint of the global.
+	// It's cty.DynamicPseudoType if no type is declared.
+	Type cty.Type
+
+	// Description is the documentation of the global.
+	Description string
+
+	// Default is the attribute used when the global is not defined, if any.
+	Default *ast.Attribute
+
+	// Required tells if the global must be defined for every stack.
+	Required bool
+}
+
+// Name returns the global accessor of the declared global.
+// Eg.: global.aws.region
+func (s GlobalSchema) Name() string {
+	return "global." + strings.Join(s.Path, ".")
+}
+
+func parseGlobalsSchemaBlock(block *ast.Block) (GlobalSchema, error) {
+	err := validateGlobalsSchemaBlock(block)
+	if err != nil {
+		return GlobalSchema{}, err
+	}
+
+	schema := GlobalSchema{
+		Range: block.Range,
+		Path:  block.Labels,
+		Type:  cty.DynamicPseudoType,
+	}
+	if attr, ok := block.Attributes["default"]; ok {
+		schema.Default = &attr
+	}
+
+	errs := errors.L()
+	if attr, ok := block.Body.Attributes["type"]; ok {
+		typ, diags := typeexpr.TypeConstraint(attr.Expr)
+		if diags.HasErrors() {
+			errs.Append(errors.E(ErrTerramateSchema, diags,
+				"%s: invalid type constraint", schema.Name()))
+		} else {
+			schema.Type = typ
+		}
+	}
+
+	if attr, ok := block.Body.Attributes["description"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		switch {
+		case diags.HasErrors():
+			errs.Append(errors.E(ErrTerramateSchema, diags))
+		case val.Type() != cty.String || val.IsNull():
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"%s: description must be a string but has type %s",
+				schema.Name(), val.Type().FriendlyName()))
+		default:
+			schema.Description = val.AsString()
+		}
+	}
+
+	if attr, ok := block.Body.Attributes["required"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		switch {
+		case diags.HasErrors():
+			errs.Append(errors.E(ErrTerramateSchema, diags))
+		case val.Type() != cty.Bool || val.IsNull():
+			errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(),
+				"%s: required must be a bool but has type %s",
+				schema.Name(), val.Type().FriendlyName()))
+		default:
+			schema.Required = val.True()
+		}
+	}
+
+	if schema.Required && schema.Default != nil {
+		errs.Append(errors.E(ErrTerramateSchema, schema.Default.Range,
+			"%s: required globals cannot have a default", schema.Name()))
+	}
+
+	if err := errs.AsError(); err != nil {
+		return GlobalSchema{}, err
+	}
+	return schema, nil
+}
+
+func validateGlobalsSchemaBlock(block *ast.Block) error {
+	errs := errors.L()
+	if len(block.Labels) == 0 {
+		errs.Append(errors.E(ErrTerramateSchema, block.OpenBraceRange,
+			"%s must have at least one label with the global name",
+			GlobalsSchemaBlockType))
+	} else if len(block.Labels) > project.MaxGlobalLabels {
+		errs.Append(errors.E(ErrTerramateSchema, block.LabelRanges(),
+			"%s supports at most %d labels but got %d",
+			GlobalsSchemaBlockType, project.MaxGlobalLabels, len(block.Labels)))
+	} else if !hclsyntax.ValidIdentifier(block.Labels[0]) {
+		errs.Append(errors.E(ErrTerramateSchema, block.LabelRanges(),
+			"first %s label must be a valid identifier but got %s",
+			GlobalsSchemaBlockType, block.Labels[0]))
+	}
+
+	schema := mustSchemaBlock(GlobalsSchemaBlockType).bodySchema()
+
+	_, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		errs.Append(errors.E(ErrTerramateSchema, diags))
+	}
+	return errs.AsError()
+}
+
+func findGlobalSchema(schemas []GlobalSchema, path []string) (GlobalSchema, bool) {
+	for _, schema := range schemas {
+		if len(schema.Path) != len(path) {
+			continue
+		}
+		found := true
+		for i, p := range path {
+			if schema.Path[i] != p {
+				found = false
+				break
+			}
+		}
+		if found {
+			return schema, true
+		}
+	}
+	return GlobalSchema{}, false
+}
@@ -21,8 +21,11 @@ import (
 	"github.com/terramate-io/terramate/hcl/ast"
 	"github.com/terramate-io/terramate/hcl/eval"
 	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/modvendor"
+	"github.com/terramate-io/terramate/project"
 	"github.com/terramate-io/terramate/safeguard"
 	"github.com/terramate-io/terramate/stdlib"
+	"github.com/terramate-io/terramate/tf"
 	"github.com/zclconf/go-cty/cty"
 	"golang.org/x/exp/slices"
 )
@@ -38,6 +41,10 @@ const (
 const (
 	// StackBlockType name of the stack block type
 	StackBlockType = "stack"
+
+	// DefaultVendorDir is the project vendor directory used when none is
+	// configured with vendor.dir.
+	DefaultVendorDir = "/modules"
 )
 
 // OptionalCheck is a bool that can also have no configured value.
@@ -78,6 +85,20 @@ type Config struct {
 	Generate  GenerateConfig
 	Scripts   []*Script
 
+	// GlobalsSchemas are the globals_schema blocks of the configuration.
+	GlobalsSchemas []GlobalSchema
+
+	// GlobalsDataFiles are the data_file blocks of all globals blocks.
+	GlobalsDataFiles []GlobalsDataFile
+
+	// Functions are the user defined functions of the project, which are
+	// only allowed at the project root.
+	Functions []Function
+
+	// Profiles are the globals of the profile blocks of the configuration,
+	// which are only used when the profile is active.
+	Profiles Profiles
+
 	Imported RawConfig
 
 	// absdir is the absolute path to the configuration directory.
@@ -85,10 +106,11 @@ type Config struct {
 }
 
 // GenerateConfig includes code generation related configurations, like
-// generate_file and generate_hcl.
+// generate_file, generate_hcl and generate_symlink.
 type GenerateConfig struct {
-	Files []GenFileBlock
-	HCLs  []GenHCLBlock
+	Files    []GenFileBlock
+	HCLs     []GenHCLBlock
+	Symlinks []GenSymlinkBlock
 }
 
 // AssertConfig represents Terramate assert configuration block.
@@ -115,6 +137,21 @@ func MatchAnyGlob(globs []glob.Glob, s string) bool {
 	return false
 }
 
+// MatchStackFilters tells if the stack directory matches any of the
+// stack_filter blocks. It always matches if there are no filters.
+func MatchStackFilters(filters []StackFilterConfig, stackdir string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if (filter.ProjectPaths == nil || MatchAnyGlob(filter.ProjectPaths, stackdir)) &&
+			(filter.RepositoryPaths == nil || MatchAnyGlob(filter.RepositoryPaths, stackdir)) {
+			return true
+		}
+	}
+	return false
+}
+
 // RunConfig represents Terramate run configuration.
 type RunConfig struct {
 	// CheckGenCode enables generated code is up-to-date check on run.
@@ -162,14 +199,25 @@ type CloudConfig struct {
 	Organization string
 }
 
+// LintConfig represents the `terramate lint` configuration.
+type LintConfig struct {
+	// Rules is the list of enabled rules. All rules are enabled if empty.
+	Rules []string
+
+	// DisabledRules is the list of rules which are never reported.
+	DisabledRules []string
+}
+
 // RootConfig represents the root config block of a Terramate configuration.
 type RootConfig struct {
 	Git               *GitConfig
 	Generate          *GenerateRootConfig
 	Run               *RunConfig
 	Cloud             *CloudConfig
+	Lint              *LintConfig
 	Experiments       []string
 	DisableSafeguards safeguard.Keywords
+	Strict            bool
 }
 
 // ManifestDesc represents a parsed manifest description.
@@ -257,6 +305,8 @@ type GenHCLBlock struct {
 	Content *hclsyntax.Block
 	// Asserts represents all assert blocks
 	Asserts []AssertConfig
+	// AllowSensitive tells if sensitive values can be generated.
+	AllowSensitive bool
 }
 
 // GenFileBlock represents a parsed generate_file block
@@ -271,12 +321,46 @@ type GenFileBlock struct {
 	Condition *hclsyntax.Attribute
 	// Represents all stack_filter blocks
 	StackFilters []StackFilterConfig
-	// Content attribute of the block
+	// Content attribute of the block, if any.
 	Content *hclsyntax.Attribute
+	// ContentBase64 attribute of the block, if any.
+	ContentBase64 *hclsyntax.Attribute
+	// TemplateFile is the template used to render the content, if any.
+	TemplateFile *TemplateFile
+	// FileMode attribute of the block, if any.
+	FileMode *hclsyntax.Attribute
 	// Context of the generation (stack by default).
 	Context string
 	// Asserts represents all assert blocks
 	Asserts []AssertConfig
+	// AllowSensitive tells if sensitive values can be generated.
+	AllowSensitive bool
+}
+
+// GenSymlinkBlock represents a parsed generate_symlink block.
+type GenSymlinkBlock struct {
+	// Range is the range of the entire block definition.
+	Range info.Range
+	// Label of the block, which is the path of the symlink.
+	Label string
+	// Lets is a block of local variables.
+	Lets *ast.MergedBlock
+	// Condition attribute of the block, if any.
+	Condition *hclsyntax.Attribute
+	// Target attribute of the block.
+	Target *hclsyntax.Attribute
+}
+
+// TemplateFile represents the template_file of a generate_file block.
+type TemplateFile struct {
+	// Path is the project path of the template file.
+	Path project.Path
+	// HostPath is the absolute host path of the template file.
+	HostPath string
+	// Attr is the template_file attribute.
+	Attr *hclsyntax.Attribute
+	// Vars is the template_vars attribute, if any.
+	Vars *hclsyntax.Attribute
 }
 
 // Evaluator represents a Terramate evaluator
@@ -312,6 +396,12 @@ type TerramateParser struct {
 	hclparser *hclparse.Parser
 	evalctx   *eval.Context
 
+	// importCtx is the evaluation context of the import blocks, shared by
+	// the parsers of the imported files.
+	importCtx *eval.Context
+	// importChain is the list of files whose imports led to this parser.
+	importChain []string
+
 	// parsedFiles stores a map of all parsed files
 	parsedFiles map[string]parsedFile
 
@@ -383,7 +473,9 @@ func NewTerramateParser(rootdir string, dir string, experiments ...string) (*Ter
 }
 
 // NewStrictTerramateParser is like NewTerramateParser but will fail instead of
-// warn for harmless configuration mistakes.
+// warn for harmless configuration mistakes, like terramate blocks outside the
+// project root, and fails on unknown attributes of map blocks. The parser is
+// also strict if the parsed root configuration sets terramate.config.strict.
 func NewStrictTerramateParser(rootdir string, dir string, experiments ...string) (*TerramateParser, error) {
 	parser, err := NewTerramateParser(rootdir, dir, experiments...)
 	if err != nil {
@@ -559,6 +651,10 @@ func (p *TerramateParser) applyImports() error {
 		return err
 	}
 
+	if len(importBlocks) > 0 && p.importCtx == nil {
+		p.importCtx = p.newImportEvalContext()
+	}
+
 	errs := errors.L()
 	for _, importBlock := range importBlocks {
 		errs.Append(p.handleImport(importBlock))
@@ -567,18 +663,37 @@ func (p *TerramateParser) applyImports() error {
 }
 
 func (p *TerramateParser) handleImport(importBlock *ast.Block) error {
+	if condAttr, ok := importBlock.Attributes["condition"]; ok {
+		condVal, err := p.importCtx.Eval(condAttr.Expr)
+		if err != nil {
+			return errors.E(ErrTerramateSchema, condAttr.Expr.Range(), err,
+				"failed to evaluate import.condition")
+		}
+		if condVal.Type() != cty.Bool || condVal.IsNull() {
+			return attrErr(condAttr, "import.condition must be a bool but got %s",
+				condVal.Type().FriendlyName())
+		}
+		if condVal.False() {
+			return nil
+		}
+	}
+
 	srcAttr := importBlock.Attributes["source"]
-	srcVal, diags := srcAttr.Expr.Value(nil)
-	if diags.HasErrors() {
-		return errors.E(ErrTerramateSchema, srcAttr.Expr.Range(),
+	srcVal, err := p.importCtx.Eval(srcAttr.Expr)
+	if err != nil {
+		return errors.E(ErrTerramateSchema, srcAttr.Expr.Range(), err,
 			"failed to evaluate import.source")
 	}
 
-	if srcVal.Type() != cty.String {
+	if srcVal.Type() != cty.String || srcVal.IsNull() {
 		return attrErr(srcAttr, "import.source must be a string")
 	}
 
 	src := srcVal.AsString()
+	if modsrc, err := tf.ParseSource(src); err == nil {
+		return p.handleRemoteImport(srcAttr, modsrc)
+	}
+
 	srcBase := path.Base(src)
 	srcDir := path.Dir(src)
 	if path.IsAbs(srcDir) { // project-path
@@ -604,69 +719,199 @@ func (p *TerramateParser) handleImport(importBlock *ast.Block) error {
 			"failed to evaluate import.source")
 	}
 	if matches == nil {
+		if tf.IsRegistrySource(srcVal.AsString()) {
+			modsrc, err := tf.ParseRegistrySource(srcVal.AsString())
+			if err != nil {
+				return errors.E(ErrImport, srcAttr.Expr.Range(), err)
+			}
+			return p.handleRemoteImport(srcAttr, modsrc)
+		}
 		return errors.E(ErrImport, srcAttr.Expr.Range(),
 			"import path %q returned no matches", srcVal.AsString())
 	}
 	for _, file := range matches {
-		if _, ok := p.parsedFiles[file]; ok {
-			return errors.E(ErrImport, srcAttr.Expr.Range(),
-				"file %q already parsed", file)
+		if err := p.importFile(srcAttr, file); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		st, err := os.Lstat(file)
-		if err != nil {
-			return errors.E(
-				ErrImport,
-				srcAttr.Expr.Range(),
-				"failed to stat file %q",
-				file,
-			)
+// handleRemoteImport imports all Terramate files of the vendored copy of the
+// remote module source modsrc, which is a Git or a registry source. The source must be pinned to a reference and it
+// must be vendored beforehand, which makes the import reproducible and never
+// touches the network while parsing the configuration.
+func (p *TerramateParser) handleRemoteImport(srcAttr ast.Attribute, modsrc tf.Source) error {
+	if modsrc.Ref == "" {
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			"import source %q must be pinned to a reference with ?ref=", modsrc.Raw)
+	}
+
+	vendorDir, err := VendorDir(p.rootdir)
+	if err != nil {
+		return errors.E(ErrImport, srcAttr.Expr.Range(), err)
+	}
+
+	moddir := modvendor.AbsVendorDir(p.rootdir, vendorDir, modsrc)
+	srcDir := filepath.Join(moddir, filepath.FromSlash(modsrc.Subdir))
+	st, err := os.Stat(srcDir)
+	if err != nil || !st.IsDir() {
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			"import source %q is not vendored at %s: run `terramate experimental vendor download %s %s`",
+			modsrc.Raw, project.PrjAbsPath(p.rootdir, srcDir),
+			strings.Split(modsrc.Raw, "?")[0], modsrc.Ref)
+	}
+
+	files, err := fs.ListTerramateFiles(srcDir)
+	if err != nil {
+		return errors.E(ErrImport, srcAttr.Expr.Range(), err,
+			"listing files of vendored import source %q", modsrc.Raw)
+	}
+	if len(files) == 0 {
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			"vendored import source %q has no Terramate files", modsrc.Raw)
+	}
+	for _, fname := range files {
+		if err := p.importFile(srcAttr, filepath.Join(srcDir, fname)); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if st.IsDir() {
-			return errors.E(
-				ErrImport,
-				srcAttr.Expr.Range(),
-				"import directory is not allowed: %s",
-				file,
-			)
+func (p *TerramateParser) importFile(srcAttr ast.Attribute, file string) error {
+	chain := append(slices.Clone(p.importChain), srcAttr.Range.HostPath())
+	if slices.Contains(chain, file) {
+		cycle := make([]string, 0, len(chain)+1)
+		for _, f := range append(chain, file) {
+			cycle = append(cycle, project.PrjAbsPath(p.rootdir, f).String())
 		}
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			"import cycle: %s", strings.Join(cycle, " -> "))
+	}
 
-		fileDir := filepath.Dir(file)
-		importParser, err := NewTerramateParser(p.rootdir, fileDir)
-		if err != nil {
-			return errors.E(ErrImport, srcAttr.Expr.Range(),
-				err, "failed to create sub parser: %s", fileDir)
+	if _, ok := p.parsedFiles[file]; ok {
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			"file %q already parsed", file)
+	}
+
+	st, err := os.Lstat(file)
+	if err != nil {
+		return errors.E(
+			ErrImport,
+			srcAttr.Expr.Range(),
+			"failed to stat file %q",
+			file,
+		)
+	}
+
+	if st.IsDir() {
+		return errors.E(
+			ErrImport,
+			srcAttr.Expr.Range(),
+			"import directory is not allowed: %s",
+			file,
+		)
+	}
+
+	fileDir := filepath.Dir(file)
+	importParser, err := NewTerramateParser(p.rootdir, fileDir)
+	if err != nil {
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			err, "failed to create sub parser: %s", fileDir)
+	}
+
+	err = importParser.AddFile(file)
+	if err != nil {
+		return errors.E(ErrImport, srcAttr.Expr.Range(),
+			err)
+	}
+	importParser.addParsedFile(p.dir, external, p.internalParsedFiles()...)
+	importParser.importCtx = p.importCtx
+	importParser.importChain = chain
+	err = importParser.Parse()
+	if err != nil {
+		return err
+	}
+	errs := errors.L()
+	for _, block := range importParser.Config.UnmergedBlocks {
+		if block.Type == "stack" {
+			errs.Append(
+				errors.E(ErrImport, srcAttr.Expr.Range(),
+					"import of stack block is not permitted"))
 		}
+	}
 
-		err = importParser.AddFile(file)
-		if err != nil {
-			return errors.E(ErrImport, srcAttr.Expr.Range(),
-				err)
+	errs.Append(p.Imported.Merge(importParser.Imported))
+	errs.Append(p.Imported.Merge(importParser.Config))
+	if err := errs.AsError(); err != nil {
+		return errors.E(ErrImport, err, "failed to merge imported configuration")
+	}
+
+	p.addParsedFile(p.dir, external, file)
+	return nil
+}
+
+// VendorDir returns the project vendor directory, as configured by the vendor
+// block in the .terramate directory, which has precedence, or in the root
+// directory. Only the raw syntax is inspected, so imported vendor blocks are
+// not considered, as it is used while the imports are resolved. Use
+// ConfiguredVendorDir with the loaded configuration otherwise.
+// It returns DefaultVendorDir if no vendor.dir is configured.
+func VendorDir(rootdir string) (project.Path, error) {
+	for _, dir := range []string{
+		filepath.Join(rootdir, ".terramate"),
+		rootdir,
+	} {
+		st, err := os.Stat(dir)
+		if err != nil || !st.IsDir() {
+			continue
 		}
-		importParser.addParsedFile(p.dir, external, p.internalParsedFiles()...)
-		err = importParser.Parse()
+		parser, err := NewTerramateParser(rootdir, dir)
 		if err != nil {
-			return err
+			return project.Path{}, err
 		}
-		errs := errors.L()
-		for _, block := range importParser.Config.UnmergedBlocks {
-			if block.Type == "stack" {
-				errs.Append(
-					errors.E(ErrImport, srcAttr.Expr.Range(),
-						"import of stack block is not permitted"))
+		if err := parser.AddDir(dir); err != nil {
+			return project.Path{}, err
+		}
+		if err := parser.parseSyntax(); err != nil {
+			return project.Path{}, err
+		}
+		bodies := parser.ParsedBodies()
+		for _, filename := range parser.sortedParsedFilenames() {
+			for _, block := range bodies[filename].Blocks {
+				if block.Type != "vendor" {
+					continue
+				}
+				cfg := VendorConfig{}
+				if err := parseVendorConfig(&cfg, ast.NewBlock(rootdir, block)); err != nil {
+					return project.Path{}, err
+				}
+				if cfg.Dir == "" {
+					continue
+				}
+				return ConfiguredVendorDir(Config{Vendor: &cfg})
 			}
 		}
+	}
+	return project.NewPath(DefaultVendorDir), nil
+}
 
-		errs.Append(p.Imported.Merge(importParser.Imported))
-		errs.Append(p.Imported.Merge(importParser.Config))
-		if err := errs.AsError(); err != nil {
-			return errors.E(ErrImport, err, "failed to merge imported configuration")
+// ConfiguredVendorDir returns the vendor directory configured by the first of
+// the given configurations with a vendor.dir, or DefaultVendorDir if none of
+// them configures it.
+func ConfiguredVendorDir(cfgs ...Config) (project.Path, error) {
+	for _, cfg := range cfgs {
+		if cfg.Vendor == nil || cfg.Vendor.Dir == "" {
+			continue
 		}
-
-		p.addParsedFile(p.dir, external, file)
+		if !path.IsAbs(cfg.Vendor.Dir) {
+			return project.Path{}, errors.E(ErrTerramateSchema,
+				"vendor.dir %q is not an absolute path", cfg.Vendor.Dir)
+		}
+		return project.NewPath(cfg.Vendor.Dir), nil
 	}
-	return nil
+	return project.NewPath(DefaultVendorDir), nil
 }
 
 func (p *TerramateParser) sortedFilenames() []string {
@@ -712,6 +957,10 @@ func (p *TerramateParser) parseStack(stackblock *ast.Block) (*Stack, error) {
 	logger.Debug().Msg("Get stack attributes.")
 	attrs := ast.AsHCLAttributes(stackblock.Body.Attributes)
 	for _, attr := range ast.SortRawAttributes(attrs) {
+		if err := validateAttribute(attr.Name, attr.NameRange, StackBlockType); err != nil {
+			errs.Append(err)
+			continue
+		}
 		attrVal, err := p.evalctx.Eval(attr.Expr)
 		if err != nil {
 			errs.Append(
@@ -779,9 +1028,7 @@ func (p *TerramateParser) parseStack(stackblock *ast.Block) (*Stack, error) {
 			errs.Append(assignSet(attr, &stack.Watch, attrVal))
 
 		default:
-			errs.Append(errors.E(
-				attr.NameRange, "unrecognized attribute stack.%q", attr.Name,
-			))
+			errs.Append(unparsedAttribute(attr.Name, StackBlockType))
 		}
 	}
 
@@ -817,6 +1064,13 @@ func (c Config) HasRunEnv() bool {
 		c.Terramate.Config.Run.Env != nil
 }
 
+// Strict tells if the config enables the strict parsing of the configuration.
+func (c Config) Strict() bool {
+	return c.Terramate != nil &&
+		c.Terramate.Config != nil &&
+		c.Terramate.Config.Strict
+}
+
 // Experiments returns the config enabled experiments, if any.
 func (c Config) Experiments() []string {
 	if c.Terramate != nil &&
@@ -833,13 +1087,15 @@ func (c Config) AbsDir() string { return c.absdir }
 func (c Config) IsEmpty() bool {
 	return c.Stack == nil && c.Terramate == nil &&
 		c.Vendor == nil && len(c.Asserts) == 0 &&
-		len(c.Globals) == 0 &&
-		len(c.Generate.Files) == 0 && len(c.Generate.HCLs) == 0
+		len(c.Globals) == 0 && len(c.GlobalsSchemas) == 0 &&
+		len(c.Functions) == 0 && len(c.Generate.Files) == 0 && len(c.Generate.HCLs) == 0 &&
+		len(c.Generate.Symlinks) == 0 && len(c.Profiles) == 0
 }
 
-// HasGlobals tells if the configuration has any globals defined.
+// HasGlobals tells if the configuration has any globals or globals schema
+// defined.
 func (c Config) HasGlobals() bool {
-	return len(c.Globals) > 0
+	return len(c.Globals) > 0 || len(c.GlobalsSchemas) > 0
 }
 
 // Save the configuration file using filename inside config directory.
@@ -872,7 +1128,20 @@ func ParseDir(root string, dir string, experiments ...string) (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
-	err = p.AddDir(dir)
+	return parseDir(p, dir)
+}
+
+// ParseDirStrict is like ParseDir but parses in strict mode.
+func ParseDirStrict(root string, dir string, experiments ...string) (Config, error) {
+	p, err := NewStrictTerramateParser(root, dir, experiments...)
+	if err != nil {
+		return Config{}, err
+	}
+	return parseDir(p, dir)
+}
+
+func parseDir(p *TerramateParser, dir string) (Config, error) {
+	err := p.AddDir(dir)
 	if err != nil {
 		return Config{}, errors.E("adding files to parser", err)
 	}
@@ -922,6 +1191,9 @@ func parseGenerateHCLBlock(block *ast.Block) (GenHCLBlock, error) {
 	})
 
 	errs := errors.L()
+	allowSensitive, err := parseAllowSensitive(block)
+	errs.Append(err)
+
 	for _, subBlock := range block.Blocks {
 		switch subBlock.Type {
 		case "lets":
@@ -978,19 +1250,20 @@ func parseGenerateHCLBlock(block *ast.Block) (GenHCLBlock, error) {
 	}
 
 	return GenHCLBlock{
-		Range:        block.Range,
-		Label:        block.Labels[0],
-		Lets:         lets,
-		Asserts:      asserts,
-		Content:      content,
-		Condition:    block.Body.Attributes["condition"],
-		StackFilters: stackFilters,
+		Range:          block.Range,
+		Label:          block.Labels[0],
+		Lets:           lets,
+		Asserts:        asserts,
+		Content:        content,
+		Condition:      block.Body.Attributes["condition"],
+		StackFilters:   stackFilters,
+		AllowSensitive: allowSensitive,
 	}, nil
 }
 
 // parseGenerateFileBlock parses all Terramate files on the given dir, returning
 // parsed generate_file blocks.
-func parseGenerateFileBlock(block *ast.Block) (GenFileBlock, error) {
+func parseGenerateFileBlock(rootdir string, block *ast.Block) (GenFileBlock, error) {
 	err := validateGenerateFileBlock(block)
 	if err != nil {
 		return GenFileBlock{}, err
@@ -1015,6 +1288,16 @@ func parseGenerateFileBlock(block *ast.Block) (GenFileBlock, error) {
 		}
 	}
 
+	allowSensitive, err := parseAllowSensitive(block)
+	errs.Append(err)
+
+	var templateFile *TemplateFile
+	if templateAttr, ok := block.Body.Attributes["template_file"]; ok {
+		tmpl, err := parseTemplateFile(rootdir, block, templateAttr)
+		errs.Append(err)
+		templateFile = tmpl
+	}
+
 	for _, subBlock := range block.Blocks {
 		switch subBlock.Type {
 		case "lets":
@@ -1063,14 +1346,69 @@ func parseGenerateFileBlock(block *ast.Block) (GenFileBlock, error) {
 	}
 
 	return GenFileBlock{
-		Range:        block.Range,
-		Label:        block.Labels[0],
-		Lets:         lets,
-		Asserts:      asserts,
-		StackFilters: stackFilters,
-		Content:      block.Body.Attributes["content"],
-		Condition:    block.Body.Attributes["condition"],
-		Context:      context,
+		Range:          block.Range,
+		Label:          block.Labels[0],
+		Lets:           lets,
+		Asserts:        asserts,
+		StackFilters:   stackFilters,
+		Content:        block.Body.Attributes["content"],
+		ContentBase64:  block.Body.Attributes["content_base64"],
+		TemplateFile:   templateFile,
+		FileMode:       block.Body.Attributes["file_mode"],
+		Condition:      block.Body.Attributes["condition"],
+		Context:        context,
+		AllowSensitive: allowSensitive,
+	}, nil
+}
+
+// parseAllowSensitive parses the allow_sensitive attribute of generate blocks,
+// which must be a literal boolean.
+func parseAllowSensitive(block *ast.Block) (bool, error) {
+	attr, ok := block.Body.Attributes["allow_sensitive"]
+	if !ok {
+		return false, nil
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.Type() != cty.Bool || val.IsNull() {
+		return false, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"%s.allow_sensitive must be a literal boolean", block.Type)
+	}
+	return val.True(), nil
+}
+
+// parseTemplateFile parses the template_file attribute of a generate_file
+// block. The template path must be a literal string, which is either a project
+// path or a path relative to the directory of the file defining the block.
+func parseTemplateFile(rootdir string, block *ast.Block, attr *hclsyntax.Attribute) (*TemplateFile, error) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.Type() != cty.String || val.IsNull() {
+		return nil, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"generate_file.template_file must be a literal string")
+	}
+
+	tmplpath := val.AsString()
+	if tmplpath == "" {
+		return nil, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"generate_file.template_file can't be empty")
+	}
+
+	var hostpath string
+	if path.IsAbs(tmplpath) {
+		hostpath = filepath.Join(rootdir, filepath.FromSlash(tmplpath))
+	} else {
+		hostpath = filepath.Join(filepath.Dir(block.Range.HostPath()), filepath.FromSlash(tmplpath))
+	}
+
+	if hostpath != rootdir && !strings.HasPrefix(hostpath, rootdir+string(filepath.Separator)) {
+		return nil, errors.E(ErrTerramateSchema, attr.Expr.Range(),
+			"generate_file.template_file %q is outside the project", tmplpath)
+	}
+
+	return &TemplateFile{
+		Path:     project.PrjAbsPath(rootdir, hostpath),
+		HostPath: hostpath,
+		Attr:     attr,
+		Vars:     block.Body.Attributes["template_vars"],
 	}, nil
 }
 
@@ -1082,14 +1420,7 @@ func validateImportBlock(block *ast.Block) error {
 			block.Labels,
 		))
 	}
-	schema := &hcl.BodySchema{
-		Attributes: []hcl.AttributeSchema{
-			{
-				Name:     "source",
-				Required: true,
-			},
-		},
-	}
+	schema := mustSchemaBlock("import").bodySchema()
 
 	_, diags := block.Body.Content(schema)
 	if diags.HasErrors() {
@@ -1118,32 +1449,7 @@ func validateGenerateHCLBlock(block *ast.Block) error {
 			"generate_hcl must have at least one 'content' block"))
 	}
 
-	schema := &hcl.BodySchema{
-		Attributes: []hcl.AttributeSchema{
-			{
-				Name:     "condition",
-				Required: false,
-			},
-		},
-		Blocks: []hcl.BlockHeaderSchema{
-			{
-				Type:       "content",
-				LabelNames: []string{},
-			},
-			{
-				Type:       "lets",
-				LabelNames: []string{},
-			},
-			{
-				Type:       "assert",
-				LabelNames: []string{},
-			},
-			{
-				Type:       "stack_filter",
-				LabelNames: []string{},
-			},
-		},
-	}
+	schema := mustSchemaBlock("generate_hcl").bodySchema()
 
 	_, diags := block.Body.Content(schema)
 	if diags.HasErrors() {
@@ -1182,37 +1488,28 @@ func validateGenerateFileBlock(block *ast.Block) error {
 		errs.Append(errors.E(ErrTerramateSchema, block.OpenBraceRange,
 			"generate_file label can't be empty"))
 	}
-	schema := &hcl.BodySchema{
-		Attributes: []hcl.AttributeSchema{
-			{
-				Name:     "content",
-				Required: true,
-			},
-			{
-				Name:     "condition",
-				Required: false,
-			},
-			{
-				Name:     "context",
-				Required: false,
-			},
-		},
-		Blocks: []hcl.BlockHeaderSchema{
-			{
-				Type:       "lets",
-				LabelNames: []string{},
-			},
-			{
-				Type:       "assert",
-				LabelNames: []string{},
-			},
-			{
-				Type:       "stack_filter",
-				LabelNames: []string{},
-			},
-		},
+	var contentAttrs []*hclsyntax.Attribute
+	for _, name := range []string{"content", "content_base64", "template_file"} {
+		if attr, ok := block.Body.Attributes[name]; ok {
+			contentAttrs = append(contentAttrs, attr)
+		}
+	}
+	_, hasTemplate := block.Body.Attributes["template_file"]
+	switch {
+	case len(contentAttrs) > 1:
+		errs.Append(errors.E(ErrTerramateSchema, contentAttrs[1].NameRange,
+			"generate_file content, content_base64 and template_file are mutually exclusive"))
+	case len(contentAttrs) == 0:
+		errs.Append(errors.E(ErrTerramateSchema, block.OpenBraceRange,
+			"generate_file must have either a content, content_base64 or template_file attribute"))
+	}
+	if varsAttr, ok := block.Body.Attributes["template_vars"]; ok && !hasTemplate {
+		errs.Append(errors.E(ErrTerramateSchema, varsAttr.NameRange,
+			"generate_file.template_vars requires a template_file attribute"))
 	}
 
+	schema := mustSchemaBlock("generate_file").bodySchema()
+
 	_, diags := block.Body.Content(schema)
 	if diags.HasErrors() {
 		errs.Append(errors.E(ErrTerramateSchema, diags))
@@ -1224,6 +1521,64 @@ func validateGenerateFileBlock(block *ast.Block) error {
 	return errs.AsError()
 }
 
+// parseGenerateSymlinkBlock parses a generate_symlink block.
+func parseGenerateSymlinkBlock(block *ast.Block) (GenSymlinkBlock, error) {
+	err := validateGenerateSymlinkBlock(block)
+	if err != nil {
+		return GenSymlinkBlock{}, err
+	}
+
+	letsConfig := NewCustomRawConfig(map[string]mergeHandler{
+		"lets": (*RawConfig).mergeLabeledBlock,
+	})
+
+	errs := errors.L()
+	for _, subBlock := range block.Blocks {
+		// already validated, only lets blocks are allowed.
+		errs.AppendWrap(ErrTerramateSchema, letsConfig.mergeBlocks(ast.Blocks{subBlock}))
+	}
+
+	lets, ok := letsConfig.MergedLabelBlocks[ast.NewEmptyLabelBlockType("lets")]
+	if ok {
+		errs.AppendWrap(ErrTerramateSchema, validateLets(lets))
+	} else {
+		lets = ast.NewMergedBlock("lets", []string{})
+	}
+
+	if err := errs.AsError(); err != nil {
+		return GenSymlinkBlock{}, err
+	}
+
+	return GenSymlinkBlock{
+		Range:     block.Range,
+		Label:     block.Labels[0],
+		Lets:      lets,
+		Condition: block.Body.Attributes["condition"],
+		Target:    block.Body.Attributes["target"],
+	}, nil
+}
+
+func validateGenerateSymlinkBlock(block *ast.Block) error {
+	errs := errors.L()
+	if len(block.Labels) != 1 {
+		errs.Append(errors.E(ErrTerramateSchema, block.OpenBraceRange,
+			"generate_symlink must have single label instead got %v",
+			block.Labels,
+		))
+	} else if block.Labels[0] == "" {
+		errs.Append(errors.E(ErrTerramateSchema, block.OpenBraceRange,
+			"generate_symlink label can't be empty"))
+	}
+
+	schema := mustSchemaBlock("generate_symlink").bodySchema()
+
+	_, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		errs.Append(errors.E(ErrTerramateSchema, diags))
+	}
+	return errs.AsError()
+}
+
 func assignSet(attr *hcl.Attribute, target *[]string, val cty.Value) error {
 	if val.IsNull() {
 		return nil
@@ -1402,7 +1757,8 @@ func parseAssertConfig(assert *ast.Block) (AssertConfig, error) {
 			cfg.Warning = attr.Expr
 		default:
 			errs.Append(errors.E(ErrTerramateSchema, attr.NameRange,
-				"unrecognized attribute %s.%s", assert.Type, attr.Name,
+				"unrecognized attribute %s.%s%s", assert.Type, attr.Name,
+				suggestAttribute(attr.Name, "assert"),
 			))
 		}
 	}
@@ -1518,7 +1874,8 @@ func parseVendorConfig(cfg *VendorConfig, vendor *ast.Block) error {
 			cfg.Dir = attrVal.AsString()
 		default:
 			errs.Append(errors.E(ErrTerramateSchema, attr.NameRange,
-				"unrecognized attribute %s.%s", vendor.Type, attr.Name,
+				"unrecognized attribute %s.%s%s", vendor.Type, attr.Name,
+				suggestAttribute(attr.Name, "vendor"),
 			))
 		}
 	}
@@ -1585,11 +1942,13 @@ func (p *TerramateParser) parseRootConfig(cfg *RootConfig, block *ast.MergedBloc
 	errs := errors.L()
 
 	for _, attr := range block.Attributes.SortedList() {
+		if err := validateAttribute(attr.Name, attr.NameRange, "terramate", "config"); err != nil {
+			errs.Append(err)
+			continue
+		}
 		switch attr.Name {
 		default:
-			errs.Append(errors.E(attr.NameRange,
-				"unrecognized attribute terramate.config.%s", attr.Name,
-			))
+			errs.Append(unparsedAttribute(attr.Name, "terramate", "config"))
 			continue
 		case "experiments":
 			val, diags := attr.Expr.Value(nil)
@@ -1622,10 +1981,25 @@ func (p *TerramateParser) parseRootConfig(cfg *RootConfig, block *ast.MergedBloc
 			if err != nil {
 				errs.Append(errors.E(ErrTerramateSchema, attr.Expr.Range(), err))
 			}
+		case "strict":
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				errs.Append(errors.E(diags, attr.Expr.Range(),
+					"evaluating terramate.config.strict attribute"))
+				continue
+			}
+			if val.Type() != cty.Bool {
+				errs.Append(attrErr(attr,
+					"terramate.config.strict is not a bool but %q",
+					val.Type().FriendlyName(),
+				))
+				continue
+			}
+			cfg.Strict = val.True()
 		}
 	}
 
-	errs.AppendWrap(ErrTerramateSchema, block.ValidateSubBlocks("git", "generate", "run", "cloud"))
+	errs.AppendWrap(ErrTerramateSchema, block.ValidateSubBlocks(mustSchemaBlock("terramate", "config").BlockTypes()...))
 
 	gitBlock, ok := block.Blocks[ast.NewEmptyLabelBlockType("git")]
 	if ok {
@@ -1651,6 +2025,13 @@ func (p *TerramateParser) parseRootConfig(cfg *RootConfig, block *ast.MergedBloc
 		errs.Append(parseGenerateRootConfig(cfg.Generate, generateBlock))
 	}
 
+	lintBlock, ok := block.Blocks[ast.NewEmptyLabelBlockType("lint")]
+	if ok {
+		cfg.Lint = &LintConfig{}
+
+		errs.Append(parseLintConfig(cfg.Lint, lintBlock))
+	}
+
 	return errs.AsError()
 }
 
@@ -1661,6 +2042,10 @@ func parseRunConfig(cfg *RootConfig, runBlock *ast.MergedBlock) error {
 	runCfg := cfg.Run
 	errs := errors.L()
 	for _, attr := range runBlock.Attributes.SortedList() {
+		if err := validateAttribute(attr.Name, attr.NameRange, "terramate", "config", "run"); err != nil {
+			errs.Append(err)
+			continue
+		}
 		value, diags := attr.Expr.Value(nil)
 		if diags.HasErrors() {
 			errs.Append(errors.E(diags,
@@ -1688,12 +2073,11 @@ func parseRunConfig(cfg *RootConfig, runBlock *ast.MergedBlock) error {
 			}
 			runCfg.CheckGenCode = value.True()
 		default:
-			errs.Append(errors.E("unrecognized attribute terramate.config.run.env.%s",
-				attr.Name))
+			errs.Append(unparsedAttribute(attr.Name, "terramate", "config", "run"))
 		}
 	}
 
-	errs.AppendWrap(ErrTerramateSchema, runBlock.ValidateSubBlocks("env"))
+	errs.AppendWrap(ErrTerramateSchema, runBlock.ValidateSubBlocks(mustSchemaBlock("terramate", "config", "run").BlockTypes()...))
 
 	block, ok := runBlock.Blocks[ast.NewEmptyLabelBlockType("env")]
 	if ok {
@@ -1707,9 +2091,13 @@ func parseRunConfig(cfg *RootConfig, runBlock *ast.MergedBlock) error {
 func parseGenerateRootConfig(cfg *GenerateRootConfig, generateBlock *ast.MergedBlock) error {
 	errs := errors.L()
 
-	errs.AppendWrap(ErrTerramateSchema, generateBlock.ValidateSubBlocks())
+	errs.AppendWrap(ErrTerramateSchema, generateBlock.ValidateSubBlocks(mustSchemaBlock("terramate", "config", "generate").BlockTypes()...))
 
 	for _, attr := range generateBlock.Attributes.SortedList() {
+		if err := validateAttribute(attr.Name, attr.NameRange, "terramate", "config", "generate"); err != nil {
+			errs.Append(err)
+			continue
+		}
 		value, diags := attr.Expr.Value(nil)
 		if diags.HasErrors() {
 			errs.Append(errors.E(diags,
@@ -1741,11 +2129,7 @@ func parseGenerateRootConfig(cfg *GenerateRootConfig, generateBlock *ast.MergedB
 			cfg.HCLMagicHeaderCommentStyle = &str
 
 		default:
-			errs.Append(errors.E(
-				attr.NameRange,
-				"unrecognized attribute terramate.config.generate.%s",
-				attr.Name,
-			))
+			errs.Append(unparsedAttribute(attr.Name, "terramate", "config", "generate"))
 		}
 	}
 	return errs.AsError()
@@ -1757,19 +2141,23 @@ func parseRunEnv(runEnv *RunEnv, envBlock *ast.MergedBlock) error {
 	}
 
 	errs := errors.L()
-	errs.AppendWrap(ErrTerramateSchema, envBlock.ValidateSubBlocks())
+	errs.AppendWrap(ErrTerramateSchema, envBlock.ValidateSubBlocks(mustSchemaBlock("terramate", "config", "run", "env").BlockTypes()...))
 	return errs.AsError()
 }
 
 func parseGitConfig(cfg *RootConfig, gitBlock *ast.MergedBlock) error {
 	errs := errors.L()
 
-	errs.AppendWrap(ErrTerramateSchema, gitBlock.ValidateSubBlocks())
+	errs.AppendWrap(ErrTerramateSchema, gitBlock.ValidateSubBlocks(mustSchemaBlock("terramate", "config", "git").BlockTypes()...))
 
 	cfg.Git = NewGitConfig()
 	git := cfg.Git
 
 	for _, attr := range gitBlock.Attributes.SortedList() {
+		if err := validateAttribute(attr.Name, attr.NameRange, "terramate", "config", "git"); err != nil {
+			errs.Append(err)
+			continue
+		}
 		value, diags := attr.Expr.Value(nil)
 		if diags.HasErrors() {
 			errs.Append(errors.E(diags,
@@ -1855,11 +2243,7 @@ func parseGitConfig(cfg *RootConfig, gitBlock *ast.MergedBlock) error {
 			git.CheckRemote = ToOptionalCheck(value.True())
 
 		default:
-			errs.Append(errors.E(
-				attr.NameRange,
-				"unrecognized attribute terramate.config.git.%s",
-				attr.Name,
-			))
+			errs.Append(unparsedAttribute(attr.Name, "terramate", "config", "git"))
 		}
 	}
 	return errs.AsError()
@@ -1875,12 +2259,46 @@ func checkSafeguardConfigConflict(cfg *RootConfig, attr ast.Attribute) error {
 	return nil
 }
 
+func parseLintConfig(cfg *LintConfig, lintBlock *ast.MergedBlock) error {
+	errs := errors.L()
+
+	errs.AppendWrap(ErrTerramateSchema, lintBlock.ValidateSubBlocks(mustSchemaBlock("terramate", "config", "lint").BlockTypes()...))
+
+	for _, attr := range lintBlock.Attributes.SortedList() {
+		if err := validateAttribute(attr.Name, attr.NameRange, "terramate", "config", "lint"); err != nil {
+			errs.Append(err)
+			continue
+		}
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			errs.Append(errors.E(diags,
+				"failed to evaluate terramate.config.lint.%s attribute", attr.Name,
+			))
+			continue
+		}
+
+		switch attr.Name {
+		case "rules":
+			errs.Append(assignSet(attr.Attribute, &cfg.Rules, value))
+		case "disabled_rules":
+			errs.Append(assignSet(attr.Attribute, &cfg.DisabledRules, value))
+		default:
+			errs.Append(unparsedAttribute(attr.Name, "terramate", "config", "lint"))
+		}
+	}
+	return errs.AsError()
+}
+
 func parseCloudConfig(cloud *CloudConfig, cloudBlock *ast.MergedBlock) error {
 	errs := errors.L()
 
-	errs.AppendWrap(ErrTerramateSchema, cloudBlock.ValidateSubBlocks())
+	errs.AppendWrap(ErrTerramateSchema, cloudBlock.ValidateSubBlocks(mustSchemaBlock("terramate", "config", "cloud").BlockTypes()...))
 
 	for _, attr := range cloudBlock.Attributes.SortedList() {
+		if err := validateAttribute(attr.Name, attr.NameRange, "terramate", "config", "cloud"); err != nil {
+			errs.Append(err)
+			continue
+		}
 		value, diags := attr.Expr.Value(nil)
 		if diags.HasErrors() {
 			errs.Append(errors.E(diags,
@@ -1903,11 +2321,7 @@ func parseCloudConfig(cloud *CloudConfig, cloudBlock *ast.MergedBlock) error {
 			cloud.Organization = value.AsString()
 
 		default:
-			errs.Append(errors.E(
-				attr.NameRange,
-				"unrecognized attribute terramate.config.cloud.%s",
-				attr.Name,
-			))
+			errs.Append(unparsedAttribute(attr.Name, "terramate", "config", "cloud"))
 		}
 	}
 	return errs.AsError()
@@ -1990,12 +2404,53 @@ func (p *TerramateParser) parseTerramateSchema() (Config, error) {
 			}
 
 		case "generate_file":
-			genfile, err := parseGenerateFileBlock(block)
+			genfile, err := parseGenerateFileBlock(p.rootdir, block)
 			errs.Append(err)
 			if err == nil {
 				config.Generate.Files = append(config.Generate.Files, genfile)
 			}
 
+		case "generate_symlink":
+			gensymlink, err := parseGenerateSymlinkBlock(block)
+			errs.Append(err)
+			if err == nil {
+				config.Generate.Symlinks = append(config.Generate.Symlinks, gensymlink)
+			}
+
+		case GlobalsSchemaBlockType:
+			schema, err := parseGlobalsSchemaBlock(block)
+			if err != nil {
+				errs.Append(err)
+				continue
+			}
+			if other, found := findGlobalSchema(config.GlobalsSchemas, schema.Path); found {
+				errs.Append(errors.E(ErrTerramateSchema, block.DefRange(),
+					"%s redeclared: previously declared at %s",
+					schema.Name(), other.Range.String()))
+				continue
+			}
+			config.GlobalsSchemas = append(config.GlobalsSchemas, schema)
+
+		case FunctionBlockType:
+			fn, err := p.parseFunctionBlock(block)
+			if err != nil {
+				errs.Append(err)
+				continue
+			}
+			if other, found := findFunction(config.Functions, fn.Name); found {
+				errs.Append(errors.E(ErrTerramateSchema, block.DefRange(),
+					"function %s redeclared: previously declared at %s",
+					fn.Name, other.Range.String()))
+				continue
+			}
+			config.Functions = append(config.Functions, fn)
+
+		case ProfileBlockType:
+			if config.Profiles == nil {
+				config.Profiles = Profiles{}
+			}
+			errs.Append(parseProfileBlock(config.Profiles, block))
+
 		case "script":
 			if !p.hasExperimentalFeature("scripts") {
 				errs.Append(
@@ -2041,11 +2496,24 @@ func (p *TerramateParser) parseTerramateSchema() (Config, error) {
 		if labelType.Type == "globals" {
 			globals[labelType] = mergedBlock
 
-			errs.AppendWrap(ErrTerramateSchema, validateGlobals(mergedBlock))
+			err := validateGlobals(mergedBlock)
+			if err != nil {
+				errs.AppendWrap(ErrTerramateSchema, err)
+				continue
+			}
+
+			datafiles, err := parseGlobalsDataFiles(p.rootdir, mergedBlock)
+			errs.Append(err)
+			config.GlobalsDataFiles = append(config.GlobalsDataFiles, datafiles...)
 		}
 	}
 
+	errs.Append(checkFunctionsRecursion(config.Functions))
+
 	config.Globals = globals
+	sort.Slice(config.GlobalsDataFiles, func(i, j int) bool {
+		return config.GlobalsDataFiles[i].Range.String() < config.GlobalsDataFiles[j].Range.String()
+	})
 
 	if foundstack {
 		logger.Debug().Msg("Parsing stack cfg.")
@@ -2070,7 +2538,7 @@ func (p *TerramateParser) parseTerramateSchema() (Config, error) {
 	return config, nil
 }
 
-func (p *TerramateParser) checkConfigSanity(_ Config) error {
+func (p *TerramateParser) checkConfigSanity(cfg Config) error {
 	logger := log.With().
 		Str("action", "TerramateParser.checkConfigSanity()").
 		Logger()
@@ -2094,7 +2562,8 @@ func (p *TerramateParser) checkConfigSanity(_ Config) error {
 			}
 		}
 	}
-	if p.strict {
+	if p.strict || cfg.Strict() {
+		errs.Append(checkStrictMaps(rawconfig))
 		return errs.AsError()
 	}
 	for _, err := range errs.Errors() {
@@ -2103,16 +2572,86 @@ func (p *TerramateParser) checkConfigSanity(_ Config) error {
 	return nil
 }
 
+// checkStrictMaps checks that the map blocks of the globals and lets blocks
+// only have the attributes of the schema.
+func checkStrictMaps(rawconfig RawConfig) error {
+	var maps ast.Blocks
+	for _, block := range rawconfig.MergedLabelBlocks.AsBlocks() {
+		if block.Type == "globals" {
+			maps = append(maps, block.Blocks...)
+		}
+	}
+	for _, block := range rawconfig.UnmergedBlocks {
+		if block.Type == ProfileBlockType {
+			for _, subBlock := range block.Blocks {
+				if subBlock.Type == "globals" {
+					maps = append(maps, subBlock.Blocks...)
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(block.Type, "generate_") {
+			continue
+		}
+		for _, subBlock := range block.Blocks {
+			if subBlock.Type == "lets" {
+				maps = append(maps, subBlock.Blocks...)
+			}
+		}
+	}
+
+	sort.Slice(maps, func(i, j int) bool {
+		if maps[i].Range.Path() != maps[j].Range.Path() {
+			return maps[i].Range.Path().String() < maps[j].Range.Path().String()
+		}
+		return maps[i].Range.Start().Byte() < maps[j].Range.Start().Byte()
+	})
+
+	errs := errors.L()
+	for _, block := range maps {
+		if block.Type == "map" {
+			errs.Append(checkStrictMap(block))
+		}
+	}
+	return errs.AsError()
+}
+
+func checkStrictMap(block *ast.Block) error {
+	errs := errors.L()
+	spec := mustSchemaBlock("globals", "map")
+	for _, attr := range block.Attributes.SortedList() {
+		if _, ok := spec.Attribute(attr.Name); !ok {
+			errs.Append(errors.E(ErrTerramateSchema, attr.NameRange,
+				"unrecognized attribute map.%s%s", attr.Name,
+				ast.DidYouMean(attr.Name, spec.AttributeNames())))
+		}
+	}
+	for _, value := range block.Blocks {
+		for _, subBlock := range value.Blocks {
+			if subBlock.Type == "map" {
+				errs.Append(checkStrictMap(subBlock))
+			}
+		}
+	}
+	return errs.AsError()
+}
+
 func validateGlobals(block *ast.MergedBlock) error {
 	errs := errors.L()
 	if block.Type != "globals" {
 		return errors.E(ErrTerramateSchema,
 			block.RawOrigins[0].TypeRange, "unexpected block type %q", block.Type)
 	}
-	errs.Append(block.ValidateSubBlocks("map"))
+	errs.Append(block.ValidateSubBlocks(mustSchemaBlock("globals").BlockTypes()...))
 	for _, raw := range block.RawOrigins {
 		for _, subBlock := range raw.Blocks {
-			errs.Append(validateMap(subBlock))
+			switch subBlock.Type {
+			case GlobalsDataFileBlockType:
+			case GlobalsMergeStrategyBlockType:
+				errs.Append(validateGlobalsMergeStrategy(block, subBlock))
+			default:
+				errs.Append(validateMap(subBlock))
+			}
 		}
 	}
 	return errs.AsError()
@@ -2213,11 +2752,12 @@ func (p *TerramateParser) parseTerramateBlock(block *ast.MergedBlock) (Terramate
 
 		default:
 			errs.Append(errors.E(errKind, attr.NameRange,
-				"unsupported attribute %q", attr.Name))
+				"unsupported attribute %q%s", attr.Name,
+				suggestAttribute(attr.Name, "terramate")))
 		}
 	}
 
-	errs.AppendWrap(ErrTerramateSchema, block.ValidateSubBlocks("config"))
+	errs.AppendWrap(ErrTerramateSchema, block.ValidateSubBlocks(mustSchemaBlock("terramate").BlockTypes()...))
 
 	configBlock, ok := block.Blocks[ast.NewEmptyLabelBlockType("config")]
 	if ok {
@@ -2263,11 +2803,19 @@ func (r *RootConfig) HasSafeguardDisabled(keyword safeguard.Keyword) bool {
 		return !git.CheckRemote.ValueOr(true) || r.DisableSafeguards.Has(keyword, safeguard.Git)
 	case safeguard.Outdated:
 		return !run.CheckGenCode || r.DisableSafeguards.Has(keyword)
+	case safeguard.Assertions:
+		return r.DisableSafeguards.Has(keyword)
 	default:
 		panic(errors.E(errors.ErrInternal, "keyword not supported"))
 	}
 }
 
+// suggestAttribute returns a suggestion of the attribute of the block at the
+// schema path which is the most similar to the given unknown attribute.
+func suggestAttribute(name string, path ...string) string {
+	return ast.DidYouMean(name, mustSchemaBlock(path...).AttributeNames())
+}
+
 func hclAttrErr(attr *hcl.Attribute, msg string, args ...interface{}) error {
 	return errors.E(ErrTerramateSchema, attr.Expr.Range(), fmt.Sprintf(msg, args...))
 }
@@ -0,0 +1,309 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/test"
+	. "github.com/terramate-io/terramate/test/hclutils"
+)
+
+func TestHCLParserFunction(t *testing.T) {
+	expr := test.NewExpr
+	tcases := []testcase{
+		{
+			name: "function without params",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_region" {
+						  result = "us-east-1"
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Functions: []hcl.Function{
+						{
+							Name:   "tm_region",
+							Result: expr(t, `"us-east-1"`),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "functions calling functions",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_name" {
+						  params = ["env", "service"]
+						  result = tm_lower("${env}-${service}")
+						}
+						function "tm_tags" {
+						  params = ["team"]
+						  result = { team = team, name = tm_name("prd", team) }
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Functions: []hcl.Function{
+						{
+							Name:   "tm_name",
+							Params: []string{"env", "service"},
+							Result: expr(t, `tm_lower("${env}-${service}")`),
+						},
+						{
+							Name:   "tm_tags",
+							Params: []string{"team"},
+							Result: expr(t, `{ team = team, name = tm_name("prd", team) }`),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "function iterators are not params",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_upper_all" {
+						  params = ["list"]
+						  result = [for v in list : tm_upper(v)]
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Functions: []hcl.Function{
+						{
+							Name:   "tm_upper_all",
+							Params: []string{"list"},
+							Result: expr(t, `[for v in list : tm_upper(v)]`),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "function name without tm_ prefix fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "name" {
+						  result = "a"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("functions.tm", Start(2, 16, 16), End(2, 22, 22))),
+				},
+			},
+		},
+		{
+			name: "function redefining Terramate function fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_upper" {
+						  params = ["s"]
+						  result = s
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("functions.tm", Start(2, 16, 16), End(2, 26, 26))),
+				},
+			},
+		},
+		{
+			name: "function redefining project function fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_stacks" {
+						  params = ["filter"]
+						  result = filter
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("functions.tm", Start(2, 16, 16), End(2, 27, 27))),
+				},
+			},
+		},
+		{
+			name: "redeclared function fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_a" {
+						  result = 1
+						}
+						function "tm_a" {
+						  result = 2
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "function without result fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_a" {
+						  params = ["a"]
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "function with invalid params fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_a" {
+						  params = ["a", "a", "b-c", 1]
+						  result = a
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+					errors.E(hcl.ErrTerramateSchema),
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "function referencing variables other than params fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_a" {
+						  params = ["a"]
+						  result = "${a}-${global.b}"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("functions.tm", Start(4, 26, 73), End(4, 34, 81))),
+				},
+			},
+		},
+		{
+			name: "recursive function fails",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_a" {
+						  params = ["n"]
+						  result = n == 0 ? 0 : tm_a(n - 1)
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "mutually recursive functions fail",
+			input: []cfgfile{
+				{
+					filename: "functions.tm",
+					body: `
+						function "tm_a" {
+						  params = ["n"]
+						  result = tm_b(n)
+						}
+						function "tm_b" {
+						  params = ["n"]
+						  result = tm_c(n)
+						}
+						function "tm_c" {
+						  params = ["n"]
+						  result = tm_a(n)
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name:     "function outside the root fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/functions.tm",
+					body: `
+						function "tm_a" {
+						  result = 1
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		testParser(t, tc)
+	}
+}
@@ -4,10 +4,15 @@
 package hcl_test
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/madlambda/spells/assert"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/test"
+	errtest "github.com/terramate-io/terramate/test/errors"
 	. "github.com/terramate-io/terramate/test/hclutils"
 )
 
@@ -368,7 +373,419 @@ func TestHCLImport(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "import vendored remote source",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "git::https://github.com/org/shared.git?ref=v1.2.0"
+					}`,
+				},
+				{
+					filename: "modules/github.com/org/shared/v1.2.0/globals.tm",
+					body: `globals {
+						A = 1
+					}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:     "import vendored remote source subdir from custom vendor dir",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: ".terramate/config.tm",
+					body: `vendor {
+						dir = "/vendor"
+					}`,
+				},
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "github.com/org/shared//globals?ref=v1.2.0"
+					}`,
+				},
+				{
+					filename: "vendor/github.com/org/shared/v1.2.0/globals/globals.tm",
+					body: `globals {
+						A = 1
+					}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:     "import remote source without ref - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "git::https://github.com/org/shared.git"
+					}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrImport,
+						Mkrange("stack/cfg.tm", Start(2, 16, 24), End(2, 56, 64))),
+				},
+			},
+		},
+		{
+			name:     "import remote source not vendored - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "git::https://github.com/org/shared.git?ref=v1.2.0"
+					}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrImport,
+						Mkrange("stack/cfg.tm", Start(2, 16, 24), End(2, 67, 75))),
+				},
+			},
+		},
+		{
+			name:     "import vendored registry source",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "org/shared/aws?ref=1.2.0"
+					}`,
+				},
+				{
+					filename: "modules/registry.terraform.io/org/shared/aws/1.2.0/globals.tm",
+					body: `globals {
+						A = 1
+					}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:     "import vendored registry source subdir with hostname",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "app.terraform.io/org/shared/aws//globals?ref=1.2.0"
+					}`,
+				},
+				{
+					filename: "modules/app.terraform.io/org/shared/aws/1.2.0/globals/globals.tm",
+					body: `globals {
+						A = 1
+					}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:     "import registry source without ref - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "app.terraform.io/org/shared/aws"
+					}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrImport,
+						Mkrange("stack/cfg.tm", Start(2, 16, 24), End(2, 49, 57))),
+				},
+			},
+		},
+		{
+			name:     "import registry source not vendored - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "org/shared/aws?ref=1.2.0"
+					}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrImport,
+						Mkrange("stack/cfg.tm", Start(2, 16, 24), End(2, 42, 50))),
+				},
+			},
+		},
+		{
+			name:     "import vendored remote source from vendor dir of root config",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "vendor.tm",
+					body: `vendor {
+						dir = "/vendor"
+					}`,
+				},
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "git::https://github.com/org/shared.git?ref=v1.2.0"
+					}`,
+				},
+				{
+					filename: "vendor/github.com/org/shared/v1.2.0/globals.tm",
+					body: `globals {
+						A = 1
+					}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:     "import vendored remote source with stack - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "git::https://github.com/org/shared.git?ref=v1.2.0"
+					}`,
+				},
+				{
+					filename: "modules/github.com/org/shared/v1.2.0/stack.tm",
+					body:     `stack {}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrImport,
+						Mkrange("stack/cfg.tm", Start(2, 16, 24), End(2, 67, 75))),
+				},
+			},
+		},
+		{
+			name:     "import with false condition is skipped",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						condition = false
+						source    = "/other/non-existent-file"
+					}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:     "import source with stack metadata",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `stack {
+						tags = ["prod"]
+					}
+					import {
+						source = "/config/${terramate.stack.tags[0]}.tm"
+					}`,
+				},
+				{
+					filename: "config/prod.tm",
+					body:     `profile "prod" {}`,
+				},
+				{
+					filename: "config/dev.tm",
+					body:     `profile "dev" {}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Stack:    &hcl.Stack{Tags: []string{"prod"}},
+					Profiles: hcl.Profiles{"prod": nil},
+				},
+			},
+		},
+		{
+			name:     "import condition with stack metadata",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `stack {
+						name = "app"
+						tags = ["prod"]
+					}
+					import {
+						condition = tm_contains(terramate.stack.tags, "dev")
+						source    = "/config/dev.tm"
+					}
+					import {
+						condition = terramate.stack.name == "app" && terramate.stack.path.basename == "stack"
+						source    = "/config/prod.tm"
+					}`,
+				},
+				{
+					filename: "config/prod.tm",
+					body:     `profile "prod" {}`,
+				},
+				{
+					filename: "config/dev.tm",
+					body:     `profile "dev" {}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Stack:    &hcl.Stack{Name: "app", Tags: []string{"prod"}},
+					Profiles: hcl.Profiles{"prod": nil},
+				},
+			},
+		},
+		{
+			name:     "imported files import with the stack metadata of the importer",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `stack {}
+					import {
+						source = "/other/cfg.tm"
+					}`,
+				},
+				{
+					filename: "other/cfg.tm",
+					body: `import {
+						source = "/config/${terramate.stack.name}.tm"
+					}`,
+				},
+				{
+					filename: "config/stack.tm",
+					body:     `profile "stack" {}`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Stack:    &hcl.Stack{},
+					Profiles: hcl.Profiles{"stack": nil},
+				},
+			},
+		},
+		{
+			name:     "import source with stack metadata outside stack - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						source = "/config/${terramate.stack.name}.tm"
+					}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("stack/cfg.tm", Start(2, 16, 24), End(2, 52, 60))),
+				},
+			},
+		},
+		{
+			name:     "import condition not bool - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `import {
+						condition = "true"
+						source    = "/other/cfg.tm"
+					}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("stack/cfg.tm", Start(2, 19, 27), End(2, 25, 33))),
+				},
+			},
+		},
+		{
+			name:     "conditional imports conflicting - fails",
+			parsedir: "stack",
+			input: []cfgfile{
+				{
+					filename: "stack/cfg.tm",
+					body: `stack {
+						tags = ["prod"]
+					}
+					import {
+						condition = tm_contains(terramate.stack.tags, "prod")
+						source    = "/config/a.tm"
+					}
+					import {
+						condition = tm_contains(terramate.stack.tags, "prod")
+						source    = "/config/b.tm"
+					}`,
+				},
+				{
+					filename: "config/a.tm",
+					body:     `vendor {}`,
+				},
+				{
+					filename: "config/b.tm",
+					body:     `vendor {}`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("config/b.tm", Start(1, 1, 0), End(1, 7, 6))),
+				},
+			},
+		},
 	} {
 		testParser(t, tc)
 	}
 }
+
+func TestHCLImportCycleChain(t *testing.T) {
+	t.Parallel()
+
+	rootdir := test.TempDir(t)
+	test.WriteFile(t, filepath.Join(rootdir, "stack"), "cfg.tm", `import {
+  source = "/a/cfg.tm"
+}`)
+	test.WriteFile(t, filepath.Join(rootdir, "a"), "cfg.tm", `import {
+  source = "/b/cfg.tm"
+}`)
+	test.WriteFile(t, filepath.Join(rootdir, "b"), "cfg.tm", `import {
+  source = "/a/cfg.tm"
+}`)
+
+	_, err := hcl.ParseDir(rootdir, filepath.Join(rootdir, "stack"))
+	errtest.Assert(t, err, errors.E(hcl.ErrImport))
+	assert.IsTrue(t,
+		strings.Contains(err.Error(), "import cycle: /stack/cfg.tm -> /a/cfg.tm -> /b/cfg.tm -> /a/cfg.tm"),
+		err.Error())
+}
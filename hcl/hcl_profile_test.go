@@ -0,0 +1,228 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl_test
+
+import (
+	"testing"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	. "github.com/terramate-io/terramate/test/hclutils"
+)
+
+func TestHCLParserProfile(t *testing.T) {
+	for _, tc := range []testcase{
+		{
+			name: "empty profile",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile "prod" {}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Profiles: hcl.Profiles{"prod": nil},
+				},
+			},
+		},
+		{
+			name: "profiles with globals",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile "prod" {
+						  globals {
+						    env = "prod"
+						  }
+						  globals "aws" {
+						    region = "eu-west-1"
+						  }
+						  globals {
+						    map "instances" {
+						      for_each = ["a", "b"]
+						      key      = element.new
+						      value    = "t3.large"
+						    }
+						  }
+						}
+						profile "dev" {
+						  globals {
+						    env = "dev"
+						  }
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Profiles: hcl.Profiles{"prod": nil, "dev": nil},
+				},
+			},
+		},
+		{
+			name: "profile blocks are merged across files",
+			input: []cfgfile{
+				{
+					filename: "a.tm",
+					body: `
+						profile "prod" {
+						  globals {
+						    a = 1
+						  }
+						}
+					`,
+				},
+				{
+					filename: "b.tm",
+					body: `
+						profile "prod" {
+						  globals {
+						    b = 1
+						  }
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Profiles: hcl.Profiles{"prod": nil},
+				},
+			},
+		},
+		{
+			name: "redeclared global in profile fails",
+			input: []cfgfile{
+				{
+					filename: "a.tm",
+					body: `
+						profile "prod" {
+						  globals {
+						    a = 1
+						  }
+						}
+					`,
+				},
+				{
+					filename: "b.tm",
+					body: `
+						profile "prod" {
+						  globals {
+						    a = 2
+						  }
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("b.tm", Start(4, 11, 52), End(4, 12, 53))),
+				},
+			},
+		},
+		{
+			name: "profile without label fails",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile {}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("profiles.tm", Start(2, 7, 7), End(2, 14, 14))),
+				},
+			},
+		},
+		{
+			name: "profile with invalid name fails",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile "prod env" {}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("profiles.tm", Start(2, 7, 7), End(2, 25, 25))),
+				},
+			},
+		},
+		{
+			name: "profile with attributes fails",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile "prod" {
+						  env = "prod"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("profiles.tm", Start(3, 9, 32), End(3, 12, 35))),
+				},
+			},
+		},
+		{
+			name: "profile with unknown block fails",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile "prod" {
+						  global {
+						    env = "prod"
+						  }
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("profiles.tm", Start(3, 9, 32), End(3, 15, 38))),
+				},
+			},
+		},
+		{
+			name: "profile with data_file fails",
+			input: []cfgfile{
+				{
+					filename: "profiles.tm",
+					body: `
+						profile "prod" {
+						  globals {
+						    data_file {
+						      path = "prod.json"
+						    }
+						  }
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("profiles.tm", Start(4, 11, 52), End(4, 20, 61))),
+				},
+			},
+		},
+	} {
+		testParser(t, tc)
+	}
+}
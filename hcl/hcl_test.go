@@ -6,6 +6,7 @@ package hcl_test
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/madlambda/spells/assert"
@@ -908,6 +909,59 @@ func TestHCLParserRootConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "terramate.config.lint block",
+			input: []cfgfile{
+				{
+					filename: "cfg.tm",
+					body: `
+						terramate {
+							config {
+								lint {
+									rules          = ["unused-global", "stack-missing-id"]
+									disabled_rules = ["stack-missing-id"]
+								}
+							}
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Terramate: &hcl.Terramate{
+						Config: &hcl.RootConfig{
+							Lint: &hcl.LintConfig{
+								Rules:         []string{"unused-global", "stack-missing-id"},
+								DisabledRules: []string{"stack-missing-id"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "terramate.config.lint.disabled_rules is not a list - fails",
+			input: []cfgfile{
+				{
+					filename: "cfg.tm",
+					body: `
+						terramate {
+							config {
+								lint {
+									disabled_rules = "unused-global"
+								}
+							}
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("cfg.tm", Start(5, 27, 76), End(5, 42, 91))),
+				},
+			},
+		},
 		{
 			name: "terramate.config.generate.hcl_magic_header_comment_style = //",
 			input: []cfgfile{
@@ -936,6 +990,122 @@ func TestHCLParserRootConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "terramate.config.strict",
+			input: []cfgfile{
+				{
+					filename: "cfg.tm",
+					body: `
+						terramate {
+							config {
+								strict = true
+							}
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{
+					Terramate: &hcl.Terramate{
+						Config: &hcl.RootConfig{
+							Strict: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "terramate.config.strict with wrong type",
+			input: []cfgfile{
+				{
+					filename: "cfg.tm",
+					body: `
+						terramate {
+							config {
+								strict = "yes"
+							}
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("cfg.tm", Start(4, 18, 52), End(4, 23, 57))),
+				},
+			},
+		},
+		{
+			name:      "non-strict parser ignores unknown attributes of map blocks",
+			nonStrict: true,
+			input: []cfgfile{
+				{
+					filename: "cfg.tm",
+					body: `
+						globals {
+							map "m" {
+								for_each = []
+								key      = element.new
+								value    = element.new
+								values   = 1
+							}
+						}
+					`,
+				},
+			},
+			want: want{
+				config: hcl.Config{},
+			},
+		},
+		{
+			name:      "terramate.config.strict fails on unknown attributes of map blocks",
+			nonStrict: true,
+			input: []cfgfile{
+				{
+					filename: "cfg.tm",
+					body: `
+						terramate {
+							config {
+								strict = true
+							}
+						}
+						globals {
+							map "m" {
+								for_each = []
+								key      = element.new
+								value {
+									map "nested" {
+										for_each = []
+										key      = element.new
+										value    = element.new
+										keys     = 1
+									}
+								}
+							}
+						}
+						generate_hcl "file.hcl" {
+							lets {
+								map "m" {
+									for_each = []
+									key      = element.new
+									value    = element.new
+									vaule    = 1
+								}
+							}
+							content {}
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("cfg.tm", Start(16, 11, 300), End(16, 15, 304))),
+					errors.E(hcl.ErrTerramateSchema,
+						Mkrange("cfg.tm", Start(27, 10, 511), End(27, 15, 516))),
+				},
+			},
+		},
 		{
 			name: "terramate.config.generate.hcl_magic_header_comment_style = #",
 			input: []cfgfile{
@@ -969,6 +1139,69 @@ func TestHCLParserRootConfig(t *testing.T) {
 	}
 }
 
+func TestHCLParserSuggestions(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{
+			body: "terramat {}",
+			want: `unrecognized block "terramat" (did you mean "terramate"?)`,
+		},
+		{
+			body: "terramate {\n  required_versions = \"1\"\n}",
+			want: `unsupported attribute "required_versions" (did you mean "required_version"?)`,
+		},
+		{
+			body: "terramate {\n  config {\n    gitt {}\n  }\n}",
+			want: `unrecognized block "gitt" (did you mean "git"?)`,
+		},
+		{
+			body: "terramate {\n  config {\n    experiment = []\n  }\n}",
+			want: `unrecognized attribute terramate.config.experiment (did you mean "experiments"?)`,
+		},
+		{
+			body: "terramate {\n  config {\n    run {\n      check_gencode = true\n    }\n  }\n}",
+			want: `unrecognized attribute terramate.config.run.check_gencode (did you mean "check_gen_code"?)`,
+		},
+		{
+			body: "terramate {\n  config {\n    cloud {\n      organisation = \"org\"\n    }\n  }\n}",
+			want: `unrecognized attribute terramate.config.cloud.organisation (did you mean "organization"?)`,
+		},
+		{
+			body: "stack {\n  tag = []\n}",
+			want: `unrecognized attribute stack.tag (did you mean "tags"?)`,
+		},
+		{
+			body: "stack {\n  unknown = []\n}",
+			want: `unrecognized attribute stack.unknown`,
+		},
+		{
+			body: "profile \"prod\" {\n  global {}\n}",
+			want: `unrecognized block profile.global (did you mean "globals"?)`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.body, func(t *testing.T) {
+			t.Parallel()
+
+			err := parseContent(t, tc.body)
+			errtest.Assert(t, err, errors.E(hcl.ErrTerramateSchema))
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error %q doesn't contain %q", err.Error(), tc.want)
+			}
+			if strings.Contains(tc.want, "did you mean") {
+				return
+			}
+			if strings.Contains(err.Error(), "did you mean") {
+				t.Fatalf("unexpected suggestion in error %q", err.Error())
+			}
+		})
+	}
+}
+
 func TestHCLParserMultipleErrors(t *testing.T) {
 	for _, tc := range []testcase{
 		{
@@ -1291,6 +1524,226 @@ func TestHCLParserGenerateStackFilters(t *testing.T) {
 	}
 }
 
+func TestHCLParserGenerateFileTemplate(t *testing.T) {
+	for _, tc := range []testcase{
+		{
+			name: "content and template_file are mutually exclusive",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.txt" {
+							content = "foo"
+							template_file = "file.tpl"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "missing content and template_file",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.txt" {
+							condition = true
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "template_vars without template_file",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.txt" {
+							content = "foo"
+							template_vars = {}
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "template_file must be a literal string",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.txt" {
+							template_file = global.file
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "template_file must be a string",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.txt" {
+							template_file = 1
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "template_file outside the project",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.txt" {
+							template_file = "../file.tpl"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+	} {
+		testParser(t, tc)
+	}
+}
+
+func TestHCLParserGenerateSymlink(t *testing.T) {
+	for _, tc := range []testcase{
+		{
+			name: "content and content_base64 are mutually exclusive",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_file "test.bin" {
+							content = "foo"
+							content_base64 = "Zm9v"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "generate_symlink without target",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_symlink "link" {
+							condition = true
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "generate_symlink with multiple labels",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_symlink "a" "b" {
+							target = "file"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "generate_symlink with unknown attribute",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_symlink "link" {
+							target = "file"
+							content = "data"
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+		{
+			name: "generate_symlink with unknown block",
+			input: []cfgfile{
+				{
+					filename: "gen.tm",
+					body: `
+						generate_symlink "link" {
+							target = "file"
+							assert {
+								assertion = true
+								message = "msg"
+							}
+						}
+					`,
+				},
+			},
+			want: want{
+				errs: []error{
+					errors.E(hcl.ErrTerramateSchema),
+				},
+			},
+		},
+	} {
+		testParser(t, tc)
+	}
+}
+
 func TestHCLParserTerramateBlocksMerging(t *testing.T) {
 	tcases := []testcase{
 		{
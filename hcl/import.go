@@ -0,0 +1,63 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/terramate-io/terramate/hcl/ast"
+	"github.com/terramate-io/terramate/hcl/eval"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// newImportEvalContext creates the evaluation context of the import.source and
+// import.condition attributes. The imports are applied while parsing, before
+// the configuration tree is loaded, so only the env namespace and the metadata
+// known from the parsed directory are available: the terramate.root metadata
+// and, if the directory has a stack block, the terramate.stack metadata.
+func (p *TerramateParser) newImportEvalContext() *eval.Context {
+	ctx := eval.NewContext(stdlib.Functions(p.dir))
+	ctx.SetEnv(os.Environ())
+
+	runtime := map[string]cty.Value{
+		"root": cty.ObjectVal(map[string]cty.Value{
+			"path": cty.ObjectVal(map[string]cty.Value{
+				"fs": cty.ObjectVal(map[string]cty.Value{
+					"absolute": cty.StringVal(p.rootdir),
+					"basename": cty.StringVal(filepath.Base(p.rootdir)),
+				}),
+			}),
+		}),
+	}
+	if stack, ok := p.importStackMetadata(); ok {
+		runtime["stack"] = stack
+	}
+	ctx.SetNamespace("terramate", runtime)
+	return ctx
+}
+
+// importStackMetadata returns the terramate.stack metadata of the parsed
+// directory, if it has a valid stack block. Invalid stack blocks are reported
+// later by the schema validation.
+func (p *TerramateParser) importStackMetadata() (cty.Value, bool) {
+	bodies := p.ParsedBodies()
+	for _, filename := range p.sortedParsedFilenames() {
+		for _, rawBlock := range bodies[filename].Blocks {
+			if rawBlock.Type != StackBlockType {
+				continue
+			}
+			stack, err := p.parseStack(ast.NewBlock(p.rootdir, rawBlock))
+			if err != nil {
+				return cty.NilVal, false
+			}
+
+			dir := project.PrjAbsPath(p.rootdir, p.dir)
+			return StackMetadata(p.rootdir, dir, stack), true
+		}
+	}
+	return cty.NilVal, false
+}
@@ -4,6 +4,7 @@
 package info
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
@@ -133,3 +134,30 @@ func (r Range) ToHCLRange() hcl.Range {
 		},
 	}
 }
+
+// MarshalJSON implements the json.Marshaler interface.
+// The host path is not part of the JSON representation, only the project path.
+func (r Range) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Path  project.Path `json:"path"`
+		Start Pos          `json:"start"`
+		End   Pos          `json:"end"`
+	}{
+		Path:  r.path,
+		Start: r.start,
+		End:   r.end,
+	})
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (p Pos) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+		Byte   int `json:"byte"`
+	}{
+		Line:   p.line,
+		Column: p.column,
+		Byte:   p.byteOffset,
+	})
+}
@@ -4,6 +4,7 @@
 package info_test
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -56,6 +57,21 @@ func TestRangeStrRepr(t *testing.T) {
 	assert.EqualStrings(t, "/assert.tm:1,1-2", tmrange.String())
 }
 
+func TestRangeJSONRepr(t *testing.T) {
+	t.Parallel()
+	rootdir := test.TempDir(t)
+	tmrange := info.NewRange(rootdir, Mkrange(
+		filepath.Join(rootdir, "dir", "assert.tm"),
+		Start(1, 1, 0),
+		End(3, 2, 37),
+	))
+	data, err := json.Marshal(tmrange)
+	assert.NoError(t, err)
+	assert.EqualStrings(t,
+		`{"path":"/dir/assert.tm","start":{"line":1,"column":1,"byte":0},"end":{"line":3,"column":2,"byte":37}}`,
+		string(data))
+}
+
 func TestRangeWithFileOnRootdir(t *testing.T) {
 	t.Parallel()
 	rootdir := test.TempDir(t)
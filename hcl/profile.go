@@ -0,0 +1,149 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+)
+
+// ProfileBlockType is the block type used to declare configuration profiles.
+const ProfileBlockType = "profile"
+
+// Profiles maps the profile names to the globals declared by the profile
+// blocks of a directory.
+type Profiles map[string]ast.MergedLabelBlocks
+
+// Names returns the sorted names of the profiles.
+func (p Profiles) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseProfileBlock(profiles Profiles, block *ast.Block) error {
+	errs := errors.L()
+	if len(block.Labels) != 1 || !hclsyntax.ValidIdentifier(block.Labels[0]) {
+		return errors.E(ErrTerramateSchema, block.DefRange(),
+			"%s block must have a single label with a valid profile name", ProfileBlockType)
+	}
+
+	for _, attr := range block.Attributes.SortedList() {
+		errs.Append(errors.E(ErrTerramateSchema, attr.NameRange,
+			"unrecognized attribute %s.%s", ProfileBlockType, attr.Name))
+	}
+
+	name := block.Labels[0]
+	globals, ok := profiles[name]
+	if !ok {
+		globals = ast.MergedLabelBlocks{}
+	}
+
+	for _, subBlock := range block.Blocks {
+		if subBlock.Type != "globals" {
+			errs.Append(errors.E(ErrTerramateSchema, subBlock.DefRange(),
+				"unrecognized block %s.%s%s", ProfileBlockType, subBlock.Type,
+				ast.DidYouMean(subBlock.Type, mustSchemaBlock(ProfileBlockType).BlockTypes())))
+			continue
+		}
+		for _, globalsSubBlock := range subBlock.Blocks {
+			if globalsSubBlock.Type == GlobalsDataFileBlockType {
+				errs.Append(errors.E(ErrTerramateSchema, globalsSubBlock.DefRange(),
+					"globals.%s blocks are not supported inside %s blocks",
+					GlobalsDataFileBlockType, ProfileBlockType))
+			}
+		}
+
+		labelType, err := ast.NewLabelBlockType(subBlock.Type, subBlock.Labels)
+		if err != nil {
+			errs.Append(errors.E(ErrTerramateSchema, subBlock.DefRange(), err))
+			continue
+		}
+		merged, ok := globals[labelType]
+		if !ok {
+			merged = ast.NewMergedBlock(subBlock.Type, subBlock.Labels)
+		}
+		if err := merged.MergeBlock(subBlock, true); err != nil {
+			errs.AppendWrap(ErrTerramateSchema, err)
+			continue
+		}
+		if err := validateGlobals(merged); err != nil {
+			errs.AppendWrap(ErrTerramateSchema, err)
+			continue
+		}
+		globals[labelType] = merged
+	}
+
+	if err := errs.AsError(); err != nil {
+		return err
+	}
+	profiles[name] = globals
+	return nil
+}
+
+// ApplyProfile overlays the globals of the given profile on the globals of the
+// configuration. The globals of the profile replace the ones with the same name
+// declared in the directory, while the others are kept untouched.
+// The globals are updated in place, so copies of the configuration are also
+// affected.
+func (c *Config) ApplyProfile(name string) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return
+	}
+	if c.Globals == nil {
+		c.Globals = ast.MergedLabelBlocks{}
+	}
+	for labelType, block := range profile {
+		base, ok := c.Globals[labelType]
+		if !ok {
+			c.Globals[labelType] = block
+			continue
+		}
+		c.Globals[labelType] = overlayGlobals(base, block)
+	}
+}
+
+func overlayGlobals(base, overlay *ast.MergedBlock) *ast.MergedBlock {
+	merged := ast.NewMergedBlock(string(base.Type), base.Labels)
+	for name, attr := range base.Attributes {
+		merged.Attributes[name] = attr
+	}
+	for labelType, block := range base.Blocks {
+		merged.Blocks[labelType] = block
+	}
+	for name, attr := range overlay.Attributes {
+		merged.Attributes[name] = attr
+		mapType, _ := ast.NewLabelBlockType("map", []string{name})
+		delete(merged.Blocks, mapType)
+	}
+	for labelType, block := range overlay.Blocks {
+		switch labelType.Type {
+		case "map":
+			delete(merged.Attributes, labelType.Labels[0])
+			merged.Blocks[labelType] = block
+		case GlobalsMergeStrategyBlockType:
+			if old, ok := merged.Blocks[labelType]; ok {
+				block = overlayGlobals(old, block)
+			}
+			merged.Blocks[labelType] = block
+		default:
+			merged.Blocks[labelType] = block
+		}
+	}
+	merged.RawOrigins = append(append(ast.Blocks{}, base.RawOrigins...), overlay.RawOrigins...)
+	for typ, blocks := range base.RawBlocks {
+		merged.RawBlocks[typ] = append(ast.Blocks{}, blocks...)
+	}
+	for typ, blocks := range overlay.RawBlocks {
+		merged.RawBlocks[typ] = append(merged.RawBlocks[typ], blocks...)
+	}
+	return merged
+}
@@ -5,6 +5,7 @@ package hcl
 
 import (
 	"path/filepath"
+	"sort"
 
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/hcl/ast"
@@ -38,15 +39,19 @@ type mergeHandler func(r *RawConfig, block *ast.Block) error
 // Terramate top-level attributes and blocks.
 func NewTopLevelRawConfig() RawConfig {
 	return NewCustomRawConfig(map[string]mergeHandler{
-		"terramate":     (*RawConfig).mergeBlock,
-		"globals":       (*RawConfig).mergeLabeledBlock,
-		"script":        (*RawConfig).addBlock,
-		"stack":         (*RawConfig).addBlock,
-		"vendor":        (*RawConfig).addBlock,
-		"generate_file": (*RawConfig).addBlock,
-		"generate_hcl":  (*RawConfig).addBlock,
-		"assert":        (*RawConfig).addBlock,
-		"import":        func(r *RawConfig, b *ast.Block) error { return nil },
+		"terramate":        (*RawConfig).mergeBlock,
+		"globals":          (*RawConfig).mergeLabeledBlock,
+		"globals_schema":   (*RawConfig).addBlock,
+		"function":         (*RawConfig).addBlock,
+		"profile":          (*RawConfig).addBlock,
+		"script":           (*RawConfig).addBlock,
+		"stack":            (*RawConfig).addBlock,
+		"vendor":           (*RawConfig).addBlock,
+		"generate_file":    (*RawConfig).addBlock,
+		"generate_hcl":     (*RawConfig).addBlock,
+		"generate_symlink": (*RawConfig).addBlock,
+		"assert":           (*RawConfig).addBlock,
+		"import":           func(r *RawConfig, b *ast.Block) error { return nil },
 	})
 }
 
@@ -84,9 +89,14 @@ func (cfg *RawConfig) mergeBlocks(blocks ast.Blocks) error {
 	for _, block := range blocks {
 		handler, ok := handlers[block.Type]
 		if !ok {
+			known := make([]string, 0, len(handlers))
+			for blockType := range handlers {
+				known = append(known, blockType)
+			}
+			sort.Strings(known)
 			errs.Append(
 				errors.E(ErrTerramateSchema, block.DefRange(),
-					"unrecognized block %q", block.Type),
+					"unrecognized block %q%s", block.Type, ast.DidYouMean(block.Type, known)),
 			)
 
 			continue
@@ -0,0 +1,684 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/ast"
+)
+
+// BlockSpec is the declarative description of a block of the Terramate
+// configuration language. The parsers use it to validate the accepted
+// attributes and sub-blocks, and it's exported as JSON Schema and markdown by
+// [BlockSpec.JSONSchema] and [BlockSpec.Markdown].
+type BlockSpec struct {
+	// Type is the block type. It's empty for the root spec, which describes
+	// the top-level of a Terramate file.
+	Type string
+
+	// Labels are the names of the labels of the block.
+	Labels []string
+
+	// AnyLabels tells if the block accepts any number of labels, which are
+	// described by Labels.
+	AnyLabels bool
+
+	// Description is a short description of the block.
+	Description string
+
+	// Experiment is the name of the experiment required to use the block, if any.
+	Experiment string
+
+	// Deprecated is the deprecation message, if the block is deprecated.
+	Deprecated string
+
+	// Attributes are the attributes accepted by the block.
+	Attributes []AttributeSpec
+
+	// AnyAttributes tells if the block accepts arbitrary attributes.
+	AnyAttributes bool
+
+	// Blocks are the sub-blocks accepted by the block.
+	Blocks []BlockSpec
+
+	// AnyBlocks tells if the block accepts arbitrary sub-blocks.
+	AnyBlocks bool
+}
+
+// AttributeSpec is the declarative description of an attribute of the
+// Terramate configuration language.
+type AttributeSpec struct {
+	// Name is the attribute name.
+	Name string
+
+	// Type is the type of the attribute value, using the type constraint
+	// syntax (eg.: string, bool, set(string) or any).
+	Type string
+
+	// Description is a short description of the attribute.
+	Description string
+
+	// Required tells if the attribute must be set.
+	Required bool
+
+	// Values are the accepted values, if the attribute only accepts a fixed
+	// set of values.
+	Values []string
+
+	// Deprecated is the deprecation message, if the attribute is deprecated.
+	Deprecated string
+}
+
+var mapBlockSpec = BlockSpec{
+	Type:        "map",
+	Labels:      []string{"name"},
+	Description: "Defines a map built by iterating over a collection.",
+	Attributes: []AttributeSpec{
+		{Name: "for_each", Type: "any", Required: true, Description: "Collection to iterate over."},
+		{Name: "key", Type: "string", Required: true, Description: "Key of each element of the map."},
+		{Name: "value", Type: "any", Description: "Value of each element of the map. Conflicts with the value block."},
+	},
+	Blocks: []BlockSpec{
+		{
+			Type:          "value",
+			Description:   "Value of each element of the map, as an object. Conflicts with the value attribute.",
+			AnyAttributes: true,
+			AnyBlocks:     true,
+		},
+	},
+}
+
+var letsBlockSpec = BlockSpec{
+	Type:          "lets",
+	Description:   "Defines variables local to the generate block, available in the let namespace.",
+	AnyAttributes: true,
+	Blocks:        []BlockSpec{mapBlockSpec},
+}
+
+var globalsBlockSpec = BlockSpec{
+	Type:          "globals",
+	Labels:        []string{"object path"},
+	AnyLabels:     true,
+	Description:   "Defines globals, inherited by all stacks of the directory hierarchy.",
+	AnyAttributes: true,
+	Blocks: []BlockSpec{
+		mapBlockSpec,
+		{
+			Type:        GlobalsDataFileBlockType,
+			Description: "Loads globals from a JSON, YAML or tfvars file.",
+			Attributes: []AttributeSpec{
+				{Name: "path", Type: "string", Required: true, Description: "Path of the data file."},
+				{Name: "format", Type: "string", Description: "Format of the data file. Detected from the file extension if not set.", Values: []string{"json", "yaml", "tfvars"}},
+			},
+		},
+		globalsMergeStrategyBlockSpec,
+	},
+}
+
+var globalsMergeStrategyBlockSpec = BlockSpec{
+	Type:          GlobalsMergeStrategyBlockType,
+	Description:   "Sets how each global is combined with the same global of parent directories.",
+	AnyAttributes: true,
+}
+
+var profileGlobalsBlockSpec = BlockSpec{
+	Type:          "globals",
+	Labels:        []string{"object path"},
+	AnyLabels:     true,
+	Description:   "Defines globals overriding the globals of the directory with the same name.",
+	AnyAttributes: true,
+	Blocks:        []BlockSpec{mapBlockSpec, globalsMergeStrategyBlockSpec},
+}
+
+var assertBlockSpec = BlockSpec{
+	Type:        "assert",
+	Description: "Defines an assertion which fails the generation or the stack evaluation when false.",
+	Attributes: []AttributeSpec{
+		{Name: "assertion", Type: "bool", Required: true, Description: "Condition which must be true."},
+		{Name: "message", Type: "string", Required: true, Description: "Message reported when the assertion is false."},
+		{Name: "warning", Type: "bool", Description: "Reports a warning instead of an error when the assertion is false."},
+	},
+}
+
+var stackFilterBlockSpec = BlockSpec{
+	Type:        "stack_filter",
+	Description: "Restricts the stacks where the code is generated.",
+	Attributes: []AttributeSpec{
+		{Name: "project_paths", Type: "set(string)", Description: "Glob patterns matched against the project path of the stack."},
+		{Name: "repository_paths", Type: "set(string)", Description: "Glob patterns matched against the repository path of the stack."},
+	},
+}
+
+var configSpec = BlockSpec{
+	Description: "Top-level of a Terramate configuration file.",
+	Blocks: []BlockSpec{
+		{
+			Type:        "terramate",
+			Description: "Configures Terramate itself.",
+			Attributes: []AttributeSpec{
+				{Name: "required_version", Type: "string", Description: "Version constraint of the Terramate binary."},
+				{Name: "required_version_allow_prereleases", Type: "bool", Description: "Allows prerelease versions to match the required_version."},
+			},
+			Blocks: []BlockSpec{
+				{
+					Type:        "config",
+					Description: "Configures the project. Only allowed in the project root.",
+					Attributes: []AttributeSpec{
+						{Name: "experiments", Type: "set(string)", Description: "Experimental features to enable."},
+						{
+							Name:        "disable_safeguards",
+							Type:        "set(string)",
+							Description: "Safeguards to disable.",
+							Values:      []string{"all", "none", "git", "git-untracked", "git-uncommitted", "git-out-of-sync", "outdated-code", "assertions"},
+						},
+						{Name: "strict", Type: "bool", Description: "Enables the strict parsing of the configuration, failing on unknown attributes of map blocks and on terramate blocks outside the project root."},
+					},
+					Blocks: []BlockSpec{
+						{
+							Type:        "git",
+							Description: "Configures the Git integration.",
+							Attributes: []AttributeSpec{
+								{Name: "default_branch", Type: "string", Description: "Default branch of the repository."},
+								{Name: "default_remote", Type: "string", Description: "Default remote of the repository."},
+								{Name: "default_branch_base_ref", Type: "string", Description: "Git reference used as the base for detecting changes in the default branch."},
+								{Name: "check_untracked", Type: "bool", Description: "Checks for untracked files before running.", Deprecated: `use terramate.config.disable_safeguards = ["git-untracked"]`},
+								{Name: "check_uncommitted", Type: "bool", Description: "Checks for uncommitted files before running.", Deprecated: `use terramate.config.disable_safeguards = ["git-uncommitted"]`},
+								{Name: "check_remote", Type: "bool", Description: "Checks if the local branch is in sync with the remote before running.", Deprecated: `use terramate.config.disable_safeguards = ["git-out-of-sync"]`},
+							},
+						},
+						{
+							Type:        "run",
+							Description: "Configures the run command.",
+							Attributes: []AttributeSpec{
+								{Name: "check_gen_code", Type: "bool", Description: "Checks for outdated generated code before running.", Deprecated: `use terramate.config.disable_safeguards = ["outdated-code"]`},
+							},
+							Blocks: []BlockSpec{
+								{
+									Type:          "env",
+									Description:   "Environment variables exported to the commands run in the stacks.",
+									AnyAttributes: true,
+								},
+							},
+						},
+						{
+							Type:        "generate",
+							Description: "Configures the code generation.",
+							Attributes: []AttributeSpec{
+								{Name: "hcl_magic_header_comment_style", Type: "string", Description: "Comment style of the header of generated HCL files.", Values: []string{"//", "#"}},
+							},
+						},
+						{
+							Type:        "cloud",
+							Description: "Configures the Terramate Cloud integration.",
+							Attributes: []AttributeSpec{
+								{Name: "organization", Type: "string", Description: "Terramate Cloud organization of the project."},
+							},
+						},
+						{
+							Type:        "lint",
+							Description: "Configures the lint command.",
+							Attributes: []AttributeSpec{
+								{Name: "rules", Type: "set(string)", Description: "Rules checked by default. All rules are checked if not set."},
+								{Name: "disabled_rules", Type: "set(string)", Description: "Rules never checked by default."},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Type:        StackBlockType,
+			Description: "Defines the directory as a stack.",
+			Attributes: []AttributeSpec{
+				{Name: "id", Type: "string", Description: "Unique identifier of the stack in the project."},
+				{Name: "name", Type: "string", Description: "Name of the stack. Defaults to the directory name."},
+				{Name: "description", Type: "string", Description: "Description of the stack."},
+				{Name: "tags", Type: "set(string)", Description: "Tags of the stack, used for filtering."},
+				{Name: "after", Type: "set(string)", Description: "Stacks which must run before this stack."},
+				{Name: "before", Type: "set(string)", Description: "Stacks which must run after this stack."},
+				{Name: "wants", Type: "set(string)", Description: "Stacks which are selected when this stack is selected."},
+				{Name: "wanted_by", Type: "set(string)", Description: "Stacks which select this stack when selected."},
+				{Name: "watch", Type: "set(string)", Description: "Files which mark the stack as changed when changed."},
+			},
+		},
+		globalsBlockSpec,
+		{
+			Type:        ProfileBlockType,
+			Labels:      []string{"name"},
+			Description: "Defines globals which overlay the globals of the directory when the profile is active.",
+			Blocks:      []BlockSpec{profileGlobalsBlockSpec},
+		},
+		{
+			Type:        GlobalsSchemaBlockType,
+			Labels:      []string{"global path"},
+			AnyLabels:   true,
+			Description: "Declares the type, description, default and requirement of a global.",
+			Attributes: []AttributeSpec{
+				{Name: "type", Type: "any", Description: "Type constraint of the global."},
+				{Name: "description", Type: "string", Description: "Description of the global."},
+				{Name: "default", Type: "any", Description: "Default value of the global."},
+				{Name: "required", Type: "bool", Description: "Requires the global to be defined in every stack."},
+			},
+		},
+		{
+			Type:        FunctionBlockType,
+			Labels:      []string{"name"},
+			Description: "Defines a function usable in any Terramate expression. Only allowed in the project root.",
+			Attributes: []AttributeSpec{
+				{Name: "params", Type: "list(string)", Description: "Names of the function parameters."},
+				{Name: "result", Type: "any", Required: true, Description: "Expression computing the function result."},
+			},
+		},
+		{
+			Type:        "generate_hcl",
+			Labels:      []string{"path"},
+			Description: "Generates an HCL file in the stacks.",
+			Attributes: []AttributeSpec{
+				{Name: "condition", Type: "bool", Description: "Generates the file only if true."},
+				{Name: "allow_sensitive", Type: "bool", Description: "Allows sensitive values in the generated content."},
+			},
+			Blocks: []BlockSpec{
+				{
+					Type:          "content",
+					Description:   "Content of the generated file.",
+					AnyAttributes: true,
+					AnyBlocks:     true,
+				},
+				letsBlockSpec,
+				assertBlockSpec,
+				stackFilterBlockSpec,
+			},
+		},
+		{
+			Type:        "generate_file",
+			Labels:      []string{"path"},
+			Description: "Generates a file in the stacks or in the project root.",
+			Attributes: []AttributeSpec{
+				{Name: "content", Type: "string", Description: "Content of the generated file."},
+				{Name: "content_base64", Type: "string", Description: "Base64 encoded content of the generated file, for binary files."},
+				{Name: "file_mode", Type: "string", Description: "Octal file mode of the generated file."},
+				{Name: "template_file", Type: "string", Description: "Template file used to render the content."},
+				{Name: "template_vars", Type: "any", Description: "Variables available in the template file."},
+				{Name: "condition", Type: "bool", Description: "Generates the file only if true."},
+				{Name: "context", Type: "string", Description: "Context of the generation.", Values: []string{"stack", "root"}},
+				{Name: "allow_sensitive", Type: "bool", Description: "Allows sensitive values in the generated content."},
+			},
+			Blocks: []BlockSpec{
+				letsBlockSpec,
+				assertBlockSpec,
+				stackFilterBlockSpec,
+			},
+		},
+		{
+			Type:        "generate_symlink",
+			Labels:      []string{"path"},
+			Description: "Generates a symbolic link in the stacks.",
+			Attributes: []AttributeSpec{
+				{Name: "target", Type: "string", Required: true, Description: "Target of the symbolic link."},
+				{Name: "condition", Type: "bool", Description: "Generates the symbolic link only if true."},
+			},
+			Blocks: []BlockSpec{letsBlockSpec},
+		},
+		assertBlockSpec,
+		{
+			Type:        "import",
+			Description: "Imports the configuration of other files.",
+			Attributes: []AttributeSpec{
+				{Name: "source", Type: "string", Required: true, Description: "Glob pattern of the imported files or a Git source pinned to a reference. Can reference env and the terramate.stack metadata."},
+				{Name: "condition", Type: "bool", Description: "Imports the files only if true. Can reference env and the terramate.stack metadata."},
+			},
+		},
+		{
+			Type:        "vendor",
+			Description: "Configures the vendoring of Terraform modules.",
+			Attributes: []AttributeSpec{
+				{Name: "dir", Type: "string", Description: "Project directory where the modules are vendored."},
+			},
+			Blocks: []BlockSpec{
+				{
+					Type:        "manifest",
+					Description: "Configures the files vendored from each module.",
+					Blocks: []BlockSpec{
+						{
+							Type:        "default",
+							Description: "Default configuration of the vendored files.",
+							Attributes: []AttributeSpec{
+								{Name: "files", Type: "list(string)", Description: "Patterns of the vendored files."},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Type:        "script",
+			Labels:      []string{"name"},
+			AnyLabels:   true,
+			Description: "Defines a sequence of commands run by terramate script run.",
+			Experiment:  "scripts",
+			Attributes: []AttributeSpec{
+				{Name: "description", Type: "string", Required: true, Description: "Description of the script."},
+			},
+			Blocks: []BlockSpec{
+				{
+					Type:        "job",
+					Description: "Defines the commands of a job of the script.",
+					Attributes: []AttributeSpec{
+						{Name: "command", Type: "list(string)", Description: "Command to run. Conflicts with commands."},
+						{Name: "commands", Type: "list(list(string))", Description: "Commands to run. Conflicts with command."},
+					},
+				},
+			},
+		},
+	},
+}
+
+// Schema returns the declarative description of the Terramate configuration
+// language, rooted at the top-level of a file.
+func Schema() BlockSpec {
+	return configSpec
+}
+
+// Block returns the spec of the sub-block at the given path of block types.
+func (b BlockSpec) Block(path ...string) (BlockSpec, bool) {
+	if len(path) == 0 {
+		return b, true
+	}
+	for _, sub := range b.Blocks {
+		if sub.Type == path[0] {
+			return sub.Block(path[1:]...)
+		}
+	}
+	return BlockSpec{}, false
+}
+
+// Attribute returns the spec of the attribute with the given name.
+func (b BlockSpec) Attribute(name string) (AttributeSpec, bool) {
+	for _, attr := range b.Attributes {
+		if attr.Name == name {
+			return attr, true
+		}
+	}
+	return AttributeSpec{}, false
+}
+
+// BlockTypes returns the types of the sub-blocks accepted by the block.
+func (b BlockSpec) BlockTypes() []string {
+	types := make([]string, len(b.Blocks))
+	for i, sub := range b.Blocks {
+		types[i] = sub.Type
+	}
+	return types
+}
+
+// AttributeNames returns the names of the attributes accepted by the block.
+func (b BlockSpec) AttributeNames() []string {
+	names := make([]string, len(b.Attributes))
+	for i, attr := range b.Attributes {
+		names[i] = attr.Name
+	}
+	return names
+}
+
+// mustSchemaBlock returns the spec of the block at path. It panics if the
+// path doesn't exist, as the paths used by the parsers are static.
+func mustSchemaBlock(path ...string) BlockSpec {
+	spec, ok := configSpec.Block(path...)
+	if !ok {
+		panic(fmt.Sprintf("hcl: no schema for block %s", strings.Join(path, ".")))
+	}
+	return spec
+}
+
+// validateAttribute returns an error if the attribute isn't declared by the
+// spec of the block at path. The parsers of blocks with a fixed set of
+// attributes validate each attribute before parsing it, so the accepted
+// attributes are always the ones of the schema.
+func validateAttribute(name string, nameRange hcl.Range, path ...string) error {
+	spec := mustSchemaBlock(path...)
+	if spec.AnyAttributes {
+		return nil
+	}
+	if _, ok := spec.Attribute(name); ok {
+		return nil
+	}
+	return errors.E(nameRange, "unrecognized attribute %s.%s%s",
+		strings.Join(path, "."), name, ast.DidYouMean(name, spec.AttributeNames()))
+}
+
+// unparsedAttribute returns the error for an attribute declared by the schema
+// of the block at path which isn't handled by its parser.
+func unparsedAttribute(name string, path ...string) error {
+	return errors.E(errors.ErrInternal, "attribute %s.%s is declared in the schema but not parsed",
+		strings.Join(path, "."), name)
+}
+
+// bodySchema returns the block spec as a [hcl.BodySchema].
+// Blocks accepting arbitrary attributes or blocks can't be described by it.
+func (b BlockSpec) bodySchema() *hcl.BodySchema {
+	bodySchema := &hcl.BodySchema{}
+	for _, attr := range b.Attributes {
+		bodySchema.Attributes = append(bodySchema.Attributes, hcl.AttributeSchema{
+			Name:     attr.Name,
+			Required: attr.Required,
+		})
+	}
+	for _, sub := range b.Blocks {
+		labels := sub.Labels
+		if sub.AnyLabels || labels == nil {
+			labels = []string{}
+		}
+		bodySchema.Blocks = append(bodySchema.Blocks, hcl.BlockHeaderSchema{
+			Type:       sub.Type,
+			LabelNames: labels,
+		})
+	}
+	return bodySchema
+}
+
+// JSONSchema returns the spec as a JSON Schema (draft 2020-12) document.
+// Blocks are described as objects keyed by their type and labeled blocks are
+// nested in one object per label, like in the JSON syntax of HCL. Repeated
+// blocks are described as arrays of objects.
+//
+// Some constraints can't be expressed by the document:
+//   - Blocks accepting any number of labels, like globals, are described
+//     without labels, as labels can't be told apart from attributes in the
+//     JSON syntax.
+//   - The number of times a block can be declared isn't checked, so any block
+//     is accepted as an array.
+//   - Attribute types are checked against literal values only, as expressions
+//     are written as template strings in the JSON syntax.
+func (b BlockSpec) JSONSchema() ([]byte, error) {
+	doc := b.jsonSchema()
+	doc["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	doc["title"] = "Terramate configuration"
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (b BlockSpec) jsonSchema() map[string]any {
+	props := map[string]any{}
+	var required []string
+	for _, attr := range b.Attributes {
+		props[attr.Name] = attr.jsonSchema()
+		if attr.Required {
+			required = append(required, attr.Name)
+		}
+	}
+	for _, sub := range b.Blocks {
+		props[sub.Type] = sub.labeledJSONSchema()
+	}
+
+	obj := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+	if b.Description != "" {
+		obj["description"] = b.description()
+	}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	if b.Deprecated != "" {
+		obj["deprecated"] = true
+	}
+	if !b.AnyAttributes && !b.AnyBlocks {
+		obj["additionalProperties"] = false
+	}
+	return obj
+}
+
+func (b BlockSpec) labeledJSONSchema() map[string]any {
+	body := b.jsonSchema()
+	obj := map[string]any{
+		"anyOf": []any{
+			body,
+			map[string]any{
+				"type":        "array",
+				"description": fmt.Sprintf("Repeated %s blocks.", b.Type),
+				"items":       body,
+			},
+		},
+	}
+	if b.AnyLabels {
+		return obj
+	}
+	for i := len(b.Labels) - 1; i >= 0; i-- {
+		obj = map[string]any{
+			"type":                 "object",
+			"description":          fmt.Sprintf("%s blocks keyed by %s.", b.Type, b.Labels[i]),
+			"additionalProperties": obj,
+		}
+	}
+	return obj
+}
+
+func (b BlockSpec) description() string {
+	desc := b.Description
+	if b.Experiment != "" {
+		desc += fmt.Sprintf(" Requires the %q experiment.", b.Experiment)
+	}
+	if b.Deprecated != "" {
+		desc += " Deprecated: " + b.Deprecated + "."
+	}
+	return desc
+}
+
+func (a AttributeSpec) jsonSchema() map[string]any {
+	obj := typeJSONSchema(a.Type)
+	desc := a.Description
+	if a.Deprecated != "" {
+		desc += " Deprecated: " + a.Deprecated + "."
+		obj["deprecated"] = true
+	}
+	obj["description"] = desc
+	if len(a.Values) > 0 {
+		if items, ok := obj["items"].(map[string]any); ok {
+			items["enum"] = a.Values
+		} else {
+			obj["enum"] = a.Values
+		}
+	}
+	return obj
+}
+
+func typeJSONSchema(typ string) map[string]any {
+	switch {
+	case typ == "string":
+		return map[string]any{"type": "string"}
+	case typ == "bool":
+		return map[string]any{"type": "boolean"}
+	case typ == "number":
+		return map[string]any{"type": "number"}
+	case strings.HasPrefix(typ, "set(") && strings.HasSuffix(typ, ")"):
+		return map[string]any{
+			"type":        "array",
+			"uniqueItems": true,
+			"items":       typeJSONSchema(typ[len("set(") : len(typ)-1]),
+		}
+	case strings.HasPrefix(typ, "list(") && strings.HasSuffix(typ, ")"):
+		return map[string]any{
+			"type":  "array",
+			"items": typeJSONSchema(typ[len("list(") : len(typ)-1]),
+		}
+	default:
+		return map[string]any{}
+	}
+}
+
+// Markdown returns the spec as a markdown document, with one section per
+// block describing its labels, attributes and sub-blocks.
+func (b BlockSpec) Markdown() string {
+	var out strings.Builder
+	out.WriteString("# Terramate Configuration Schema\n")
+	for _, sub := range b.Blocks {
+		sub.markdown(&out, nil)
+	}
+	return out.String()
+}
+
+func (b BlockSpec) markdown(out *strings.Builder, parents []string) {
+	path := append(append([]string{}, parents...), b.Type)
+
+	fmt.Fprintf(out, "\n## `%s`\n\n%s\n", strings.Join(path, "."), b.description())
+
+	if len(b.Labels) > 0 {
+		labels := make([]string, len(b.Labels))
+		for i, label := range b.Labels {
+			labels[i] = fmt.Sprintf("`%s`", label)
+		}
+		suffix := ""
+		if b.AnyLabels {
+			suffix = " (any number of labels)"
+		}
+		fmt.Fprintf(out, "\nLabels: %s%s\n", strings.Join(labels, ", "), suffix)
+	}
+
+	if len(b.Attributes) > 0 {
+		out.WriteString("\n| attribute | type | required | description |\n")
+		out.WriteString("|-----------|------|----------|-------------|\n")
+		for _, attr := range b.Attributes {
+			desc := attr.Description
+			if len(attr.Values) > 0 {
+				values := make([]string, len(attr.Values))
+				for i, val := range attr.Values {
+					values[i] = fmt.Sprintf("`%s`", val)
+				}
+				desc += " One of " + strings.Join(values, ", ") + "."
+			}
+			if attr.Deprecated != "" {
+				desc += " **Deprecated**: " + attr.Deprecated + "."
+			}
+			required := "no"
+			if attr.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(out, "| `%s` | `%s` | %s | %s |\n", attr.Name, attr.Type, required, desc)
+		}
+	}
+
+	if b.AnyAttributes {
+		out.WriteString("\nAccepts arbitrary attributes.\n")
+	}
+	if b.AnyBlocks {
+		out.WriteString("\nAccepts arbitrary blocks.\n")
+	}
+
+	if len(b.Blocks) > 0 {
+		blocks := make([]string, len(b.Blocks))
+		for i, sub := range b.Blocks {
+			blocks[i] = fmt.Sprintf("`%s`", sub.Type)
+		}
+		fmt.Fprintf(out, "\nBlocks: %s\n", strings.Join(blocks, ", "))
+	}
+
+	for _, sub := range b.Blocks {
+		sub.markdown(out, path)
+	}
+}
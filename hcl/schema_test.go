@@ -0,0 +1,174 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/test"
+	errtest "github.com/terramate-io/terramate/test/errors"
+)
+
+// TestSchemaInSyncWithParser checks that the parser accepts all the attributes
+// and blocks of the schema and rejects anything else.
+func TestSchemaInSyncWithParser(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range [][]string{
+		{"terramate"},
+		{"terramate", "config"},
+		{"terramate", "config", "git"},
+		{"terramate", "config", "run"},
+		{"terramate", "config", "generate"},
+		{"terramate", "config", "cloud"},
+		{"terramate", "config", "lint"},
+		{"stack"},
+	} {
+		spec, ok := hcl.Schema().Block(path...)
+		assert.IsTrue(t, ok, "no schema for %v", path)
+
+		for _, attr := range spec.Attributes {
+			body := fmt.Sprintf("%s = %s", attr.Name, sampleValue(attr))
+			cfg := nestBlocks(path, body)
+			t.Run(cfg, func(t *testing.T) {
+				t.Parallel()
+				assert.NoError(t, parseContent(t, cfg))
+			})
+		}
+
+		for _, sub := range spec.Blocks {
+			cfg := nestBlocks(append(path, sub.Type), "")
+			t.Run(cfg, func(t *testing.T) {
+				t.Parallel()
+				assert.NoError(t, parseContent(t, cfg))
+			})
+		}
+
+		for body, want := range map[string]string{
+			"unknown_attribute = 1": "unknown_attribute",
+			"unknown_block {}":      `unrecognized block "unknown_block"`,
+		} {
+			cfg := nestBlocks(path, body)
+			want := want
+			t.Run(cfg, func(t *testing.T) {
+				t.Parallel()
+				err := parseContent(t, cfg)
+				errtest.Assert(t, err, errors.E(hcl.ErrTerramateSchema))
+				assert.IsTrue(t, strings.Contains(err.Error(), want),
+					"error %q doesn't contain %q", err, want)
+			})
+		}
+	}
+}
+
+func TestSchemaJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := hcl.Schema().JSONSchema()
+	assert.NoError(t, err)
+
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.EqualStrings(t, "https://json-schema.org/draft/2020-12/schema", doc["$schema"].(string))
+
+	get := func(obj map[string]any, path ...string) map[string]any {
+		t.Helper()
+		for _, key := range path {
+			next, ok := obj[key].(map[string]any)
+			if !ok {
+				t.Fatalf("key %q not found in path %v", key, path)
+			}
+			obj = next
+		}
+		return obj
+	}
+
+	// blocks are either an object or an array of objects.
+	block := func(obj map[string]any) (map[string]any, map[string]any) {
+		t.Helper()
+		anyOf, ok := obj["anyOf"].([]any)
+		if !ok || len(anyOf) != 2 {
+			t.Fatalf("block schema is not an object or an array: %v", obj)
+		}
+		return anyOf[0].(map[string]any), anyOf[1].(map[string]any)
+	}
+
+	terramate, _ := block(get(doc, "properties", "terramate"))
+	config, _ := block(get(terramate, "properties", "config"))
+	git, _ := block(get(config, "properties", "git"))
+	checkRemote := get(git, "properties", "check_remote")
+	assert.EqualStrings(t, "boolean", checkRemote["type"].(string))
+	assert.IsTrue(t, checkRemote["deprecated"].(bool))
+
+	genhcl, _ := block(get(doc, "properties", "generate_hcl", "additionalProperties"))
+	condition := get(genhcl, "properties", "condition")
+	assert.EqualStrings(t, "boolean", condition["type"].(string))
+
+	asserts, assertsList := block(get(genhcl, "properties", "assert"))
+	assert.EqualStrings(t, "array", assertsList["type"].(string))
+	test.AssertDiff(t, assertsList["items"], asserts)
+
+	safeguards := get(config, "properties", "disable_safeguards", "items")
+	assert.EqualInts(t, 8, len(safeguards["enum"].([]any)))
+
+	stack, _ := block(get(doc, "properties", "stack"))
+	assert.IsTrue(t, !stack["additionalProperties"].(bool))
+
+	// labeled globals are described without labels.
+	globals, _ := block(get(doc, "properties", "globals"))
+	get(globals, "properties", "map")
+}
+
+func TestSchemaMarkdown(t *testing.T) {
+	t.Parallel()
+
+	md := hcl.Schema().Markdown()
+
+	want := "\n## `terramate.config.generate`\n\n" +
+		"Configures the code generation.\n\n" +
+		"| attribute | type | required | description |\n" +
+		"|-----------|------|----------|-------------|\n" +
+		"| `hcl_magic_header_comment_style` | `string` | no | Comment style of the header of generated HCL files. One of `//`, `#`. |\n"
+
+	if !strings.Contains(md, want) {
+		t.Fatalf("markdown doesn't contain:\n%s\ngot:\n%s", want, md)
+	}
+}
+
+func sampleValue(attr hcl.AttributeSpec) string {
+	switch {
+	case len(attr.Values) > 0 && strings.HasPrefix(attr.Type, "set("):
+		return fmt.Sprintf("[%q]", attr.Values[0])
+	case len(attr.Values) > 0:
+		return fmt.Sprintf("%q", attr.Values[0])
+	case attr.Type == "string":
+		return `"value"`
+	case attr.Type == "bool":
+		return "true"
+	default:
+		return "[]"
+	}
+}
+
+func nestBlocks(path []string, body string) string {
+	cfg := body
+	for i := len(path) - 1; i >= 0; i-- {
+		cfg = fmt.Sprintf("%s {\n%s\n}", path[i], cfg)
+	}
+	return cfg
+}
+
+func parseContent(t *testing.T, content string) error {
+	dir := test.TempDir(t)
+	test.WriteFile(t, dir, "cfg.tm", content)
+	_, err := hcl.ParseDir(dir, dir)
+	return err
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package hcl
+
+import (
+	"path/filepath"
+
+	"github.com/terramate-io/terramate/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// StackMetadata returns the terramate.stack metadata object of the given
+// stack defined at dir. If the stack has no name, the basename of dir is used.
+func StackMetadata(rootdir string, dir project.Path, stack *Stack) cty.Value {
+	name := stack.Name
+	if name == "" {
+		name = filepath.Base(dir.String())
+	}
+	// should never fail as dir is inside rootdir.
+	toRoot, _ := filepath.Rel(project.AbsPath(rootdir, dir.String()), rootdir)
+	tags := cty.ListValEmpty(cty.String)
+	if len(stack.Tags) > 0 {
+		vals := make([]cty.Value, len(stack.Tags))
+		for i, tag := range stack.Tags {
+			vals[i] = cty.StringVal(tag)
+		}
+		tags = cty.ListVal(vals)
+	}
+	metadata := map[string]cty.Value{
+		"name":        cty.StringVal(name),
+		"description": cty.StringVal(stack.Description),
+		"tags":        tags,
+		"path": cty.ObjectVal(map[string]cty.Value{
+			"absolute": cty.StringVal(dir.String()),
+			"relative": cty.StringVal(dir.String()[1:]),
+			"basename": cty.StringVal(filepath.Base(dir.String())),
+			"to_root":  cty.StringVal(filepath.ToSlash(toRoot)),
+		}),
+	}
+	if stack.ID != "" {
+		metadata["id"] = cty.StringVal(stack.ID)
+	}
+	return cty.ObjectVal(metadata)
+}
@@ -0,0 +1,6 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+// Package lint implements the static analysis of the Terramate configuration
+// of a project, reporting issues found by a set of configurable rules.
+package lint
@@ -0,0 +1,107 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl/info"
+	"golang.org/x/exp/slices"
+)
+
+// IgnoreDirective is the comment directive which suppresses the issues of a
+// specific location. A comment in a line of its own suppresses the issues
+// starting in the next line and a comment at the end of a line suppresses the
+// issues starting in that line. The directive may be followed by the names of
+// the rules to suppress, separated by spaces or commas, and suppresses the
+// issues of all rules otherwise:
+//
+//	# terramate-lint-ignore unused-global
+//	a = 1
+const IgnoreDirective = "terramate-lint-ignore"
+
+// ignoredLines maps the host path of each file to its lines with suppressed
+// issues and the rules suppressed in each line. A nil list of rules suppresses
+// all rules.
+type ignoredLines map[string]map[int][]string
+
+func loadIgnoredLines(p *projectFiles) (ignoredLines, error) {
+	ignored := ignoredLines{}
+	errs := errors.L()
+	for _, f := range p.files {
+		src, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, errors.E(err, "reading file %s", f.path)
+		}
+		tokens, diags := hclsyntax.LexConfig(src, f.path, hhcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, errors.E(diags)
+		}
+		for _, tok := range tokens {
+			if tok.Type != hclsyntax.TokenComment {
+				continue
+			}
+			rules, ok := parseIgnoreDirective(string(tok.Bytes))
+			if !ok {
+				continue
+			}
+			for _, name := range rules {
+				if _, ok := ruleByName(name); !ok {
+					errs.Append(errors.E(ErrUnknownRule, tok.Range, "%q", name))
+				}
+			}
+			line := tok.Range.Start.Line
+			lineStart := bytes.LastIndexByte(src[:tok.Range.Start.Byte], '\n') + 1
+			if len(bytes.TrimSpace(src[lineStart:tok.Range.Start.Byte])) == 0 {
+				line++
+			}
+			if ignored[f.path] == nil {
+				ignored[f.path] = map[int][]string{}
+			}
+			if prev, ok := ignored[f.path][line]; ok && (prev == nil || rules == nil) {
+				ignored[f.path][line] = nil
+				continue
+			}
+			ignored[f.path][line] = append(ignored[f.path][line], rules...)
+		}
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return ignored, nil
+}
+
+// parseIgnoreDirective returns the rules suppressed by the comment, if it is
+// an ignore directive.
+func parseIgnoreDirective(comment string) ([]string, bool) {
+	text := strings.TrimSpace(comment)
+	switch {
+	case strings.HasPrefix(text, "#"):
+		text = strings.TrimPrefix(text, "#")
+	case strings.HasPrefix(text, "//"):
+		text = strings.TrimPrefix(text, "//")
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+	}
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	if len(fields) == 0 || fields[0] != IgnoreDirective {
+		return nil, false
+	}
+	if len(fields) == 1 {
+		return nil, true
+	}
+	return fields[1:], true
+}
+
+func (ignored ignoredLines) suppresses(rng info.Range, rule string) bool {
+	rules, ok := ignored[rng.HostPath()][rng.Start().Line()]
+	return ok && (len(rules) == 0 || slices.Contains(rules, rule))
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/rs/zerolog/log"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"golang.org/x/exp/slices"
+)
+
+// ErrUnknownRule indicates that an unknown rule was selected or disabled.
+const ErrUnknownRule errors.Kind = "unknown lint rule"
+
+// Severity is the severity of an issue.
+type Severity string
+
+// Available severities.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule is a lint rule.
+type Rule struct {
+	// Name is the unique name of the rule, used for selecting and disabling it.
+	Name string
+
+	// Description is a short description of what the rule reports.
+	Description string
+
+	// Severity is the severity of the issues reported by the rule.
+	Severity Severity
+
+	check func(p *projectFiles) []finding
+}
+
+// Issue is a problem found by a rule.
+type Issue struct {
+	Rule     string     `json:"rule"`
+	Severity Severity   `json:"severity"`
+	Message  string     `json:"message"`
+	Range    info.Range `json:"range"`
+}
+
+// Issues is a list of issues.
+type Issues []Issue
+
+// Options for linting a project.
+type Options struct {
+	// Rules is the list of rules to run. If empty, the rules configured in
+	// terramate.config.lint.rules are used and, if none, all rules.
+	Rules []string
+
+	// DisabledRules is the list of rules to disable, in addition to the ones
+	// configured in terramate.config.lint.disabled_rules.
+	DisabledRules []string
+}
+
+// finding is an issue found by a rule check, before the rule information
+// is attached to it.
+type finding struct {
+	msg string
+	rng info.Range
+}
+
+// projectFiles is the parsed syntax of all the Terramate files of a project.
+type projectFiles struct {
+	root  *config.Root
+	files []*file
+}
+
+type file struct {
+	tree *config.Tree
+	path string
+	body *hclsyntax.Body
+}
+
+// Rules returns all the available rules.
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// Lint runs the enabled rules on the Terramate configuration of the project
+// and returns the issues found in the files inside dir, sorted by their
+// location. The whole project is always analyzed, as rules like the unused
+// globals depend on the files outside dir.
+func Lint(root *config.Root, dir project.Path, opts Options) (Issues, error) {
+	logger := log.With().
+		Str("action", "lint.Lint()").
+		Stringer("dir", dir).
+		Logger()
+
+	enabled, err := enabledRules(root, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().Msg("parsing project files")
+
+	p, err := loadProjectFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	ignored, err := loadIgnoredLines(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues Issues
+	for _, rule := range enabled {
+		logger.Trace().Str("rule", rule.Name).Msg("running rule")
+
+		for _, f := range rule.check(p) {
+			if !f.rng.Path().HasDirPrefix(dir.String()) {
+				continue
+			}
+			if ignored.suppresses(f.rng, rule.Name) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Message:  f.msg,
+				Range:    f.rng,
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Range, issues[j].Range
+		if a.Path() != b.Path() {
+			return a.Path().String() < b.Path().String()
+		}
+		return a.Start().Byte() < b.Start().Byte()
+	})
+	return issues, nil
+}
+
+// HasErrors tells if any of the issues has the error severity.
+func (issues Issues) HasErrors() bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the issue in the format "path:line:column: severity: message (rule)".
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)",
+		i.Range.Path(), i.Range.Start().Line(), i.Range.Start().Column(),
+		i.Severity, i.Message, i.Rule)
+}
+
+func enabledRules(root *config.Root, opts Options) ([]Rule, error) {
+	selected := opts.Rules
+	disabled := opts.DisabledRules
+
+	rootcfg := root.Tree().Node
+	if rootcfg.Terramate != nil && rootcfg.Terramate.Config != nil &&
+		rootcfg.Terramate.Config.Lint != nil {
+		lintcfg := rootcfg.Terramate.Config.Lint
+		if len(selected) == 0 {
+			selected = lintcfg.Rules
+		}
+		disabled = append(disabled, lintcfg.DisabledRules...)
+	}
+
+	errs := errors.L()
+	for _, name := range append(append([]string{}, selected...), disabled...) {
+		if _, ok := ruleByName(name); !ok {
+			errs.Append(errors.E(ErrUnknownRule, "%q", name))
+		}
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+
+	var enabled []Rule
+	for _, rule := range rules {
+		if len(selected) > 0 && !slices.Contains(selected, rule.Name) {
+			continue
+		}
+		if slices.Contains(disabled, rule.Name) {
+			continue
+		}
+		enabled = append(enabled, rule)
+	}
+	return enabled, nil
+}
+
+func ruleByName(name string) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func loadProjectFiles(root *config.Root) (*projectFiles, error) {
+	trees := root.Tree().AsList()
+	sort.Sort(trees)
+
+	p := &projectFiles{root: root}
+	for _, tree := range trees {
+		if _, err := os.Stat(filepath.Join(tree.HostDir(), config.SkipFilename)); err == nil {
+			continue
+		}
+
+		parser, err := hcl.NewTerramateParser(root.HostDir(), tree.HostDir())
+		if err != nil {
+			return nil, err
+		}
+		if err := parser.AddDir(tree.HostDir()); err != nil {
+			return nil, err
+		}
+		if err := parser.Parse(); err != nil {
+			return nil, err
+		}
+
+		bodies := parser.ParsedBodies()
+		filenames := make([]string, 0, len(bodies))
+		for filename := range bodies {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+
+		for _, filename := range filenames {
+			p.files = append(p.files, &file{
+				tree: tree,
+				path: filename,
+				body: bodies[filename],
+			})
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,501 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package lint_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/lint"
+	"github.com/terramate-io/terramate/project"
+	errtest "github.com/terramate-io/terramate/test/errors"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+type testcase struct {
+	name    string
+	layout  []string
+	dir     string
+	opts    lint.Options
+	want    []string
+	wantErr error
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []testcase{
+		{
+			name: "no issues",
+			layout: []string{
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				globals {
+					a = 1
+				}
+				generate_hcl "file.tf" {
+					lets {
+						b = global.a
+					}
+					content {
+						b = let.b
+					}
+				}`,
+			},
+		},
+		{
+			name: "unused globals",
+			layout: []string{
+				`f:globals.tm:globals {
+					a = 1
+					b = global.a
+				}
+				globals "obj" "key" {
+					c = 1
+				}
+				globals {
+					map "m" {
+						for_each = []
+						key      = element.new
+						value    = element.new
+					}
+				}`,
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				globals {
+					a = 2
+				}`,
+			},
+			want: []string{
+				`/globals.tm:3:6: warning: global "b" is never referenced (unused-global)`,
+				`/globals.tm:5:13: warning: global "obj" is never referenced (unused-global)`,
+				`/globals.tm:9:10: warning: global "m" is never referenced (unused-global)`,
+			},
+		},
+		{
+			name: "globals referenced by index and in other namespaces are used",
+			layout: []string{
+				`f:terramate.tm:terramate {
+					config {
+						experiments = ["scripts"]
+					}
+				}`,
+				`f:globals.tm:globals {
+					a = 1
+					b = 2
+					c = 3
+				}`,
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				generate_file "file.txt" {
+					condition = global["a"] == 1
+					content   = "${global.b.x}"
+				}
+				script "deploy" {
+					description = "deploy"
+					job {
+						command = ["echo", global.c]
+					}
+				}`,
+			},
+		},
+		{
+			name: "globals referenced as a whole are all used",
+			layout: []string{
+				`f:globals.tm:globals {
+					a = 1
+					b = 2
+				}`,
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				generate_hcl "file.tf" {
+					content {
+						all = global
+					}
+				}`,
+			},
+		},
+		{
+			name: "globals of all stacks queried are all used",
+			layout: []string{
+				`f:globals.tm:globals {
+					a = 1
+					b = tm_stack_globals("/stack")
+				}`,
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}`,
+			},
+		},
+		{
+			name: "unused lets",
+			layout: []string{
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				generate_hcl "file.tf" {
+					lets {
+						a = 1
+						b = let.a
+						c = 3
+					}
+					content {
+						b = let.b
+					}
+				}
+				generate_file "file.txt" {
+					lets {
+						d = 1
+					}
+					content = "none"
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:8:7: warning: let "c" is never referenced in generate_hcl "file.tf" (unused-let)`,
+				`/stack/stack.tm:16:7: warning: let "d" is never referenced in generate_file "file.txt" (unused-let)`,
+			},
+		},
+		{
+			name: "stacks without id",
+			layout: []string{
+				`f:stack1/stack.tm:stack {
+					id = "stack1"
+				}`,
+				`f:stack2/stack.tm:stack {
+					name = "stack2"
+				}`,
+			},
+			want: []string{
+				`/stack2/stack.tm:1:1: warning: stack has no id (stack-missing-id)`,
+			},
+		},
+		{
+			name: "stack order paths without stacks",
+			layout: []string{
+				`f:stack1/stack.tm:stack {
+					id     = "stack1"
+					after  = ["/stack2", "../stack2", "tag:prod", "/missing"]
+					before = ["../dir"]
+				}`,
+				`f:stack2/stack.tm:stack {
+					id = "stack2"
+				}`,
+				`f:dir/globals.tm:globals {}`,
+			},
+			want: []string{
+				`/stack1/stack.tm:3:52: error: stack.after path "/missing" does not contain any stack (stack-order-missing-path)`,
+				`/stack1/stack.tm:4:16: error: stack.before path "../dir" does not contain any stack (stack-order-missing-path)`,
+			},
+		},
+		{
+			name: "duplicated generate labels in the hierarchy",
+			layout: []string{
+				`f:generate.tm:generate_hcl "file.tf" {
+					content {
+						a = 1
+					}
+				}
+				generate_hcl "conditional.tf" {
+					condition = false
+					content {}
+				}
+				generate_file "root.txt" {
+					context = root
+					content = "root"
+				}`,
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				generate_file "file.tf" {
+					content = "a = 1"
+				}
+				generate_hcl "conditional.tf" {
+					content {}
+				}
+				generate_file "root.txt" {
+					context = root
+					content = "root"
+				}`,
+				`f:other/stack.tm:stack {
+					id = "other"
+				}
+				generate_hcl "other.tf" {
+					content {}
+				}`,
+				`f:sibling/stack.tm:stack {
+					id = "sibling"
+				}
+				generate_hcl "other.tf" {
+					content {}
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:4:19: error: generate_file label "file.tf" is also defined at /generate.tm:1,14-23 (duplicated-generate-label)`,
+			},
+		},
+		{
+			name: "empty imports",
+			layout: []string{
+				`f:imports/empty.tm:# nothing here`,
+				`f:imports/globals.tm:globals {}`,
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				import {
+					source = "/imports/*.tm"
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:5:15: warning: imported file /imports/empty.tm defines nothing (empty-import)`,
+			},
+		},
+		{
+			name: "deprecated metadata",
+			layout: []string{
+				`f:stack/stack.tm:stack {
+					id = "stack"
+				}
+				generate_hcl "file.tf" {
+					content {
+						path = terramate.path
+						name = terramate.name
+						desc = terramate.description
+						new  = terramate.stack.path.absolute
+					}
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:6:14: warning: terramate.path is deprecated, use terramate.stack.path.absolute instead (deprecated-metadata)`,
+				`/stack/stack.tm:7:14: warning: terramate.name is deprecated, use terramate.stack.name instead (deprecated-metadata)`,
+				`/stack/stack.tm:8:14: warning: terramate.description is deprecated, use terramate.stack.description instead (deprecated-metadata)`,
+			},
+		},
+		{
+			name: "only issues inside dir are reported",
+			dir:  "/stack2",
+			layout: []string{
+				`f:stack1/stack.tm:stack {}`,
+				`f:stack2/stack.tm:stack {}`,
+			},
+			want: []string{
+				`/stack2/stack.tm:1:1: warning: stack has no id (stack-missing-id)`,
+			},
+		},
+		{
+			name: "selected rules",
+			opts: lint.Options{
+				Rules: []string{"unused-global"},
+			},
+			layout: []string{
+				`f:stack/stack.tm:stack {}
+				globals {
+					a = 1
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:3:6: warning: global "a" is never referenced (unused-global)`,
+			},
+		},
+		{
+			name: "rules selected and disabled by config",
+			layout: []string{
+				`f:terramate.tm:terramate {
+					config {
+						lint {
+							rules          = ["unused-global", "stack-missing-id"]
+							disabled_rules = ["unused-global"]
+						}
+					}
+				}`,
+				`f:stack/stack.tm:stack {}
+				globals {
+					a = 1
+				}
+				generate_hcl "file.tf" {
+					content {
+						a = terramate.path
+					}
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:1:1: warning: stack has no id (stack-missing-id)`,
+			},
+		},
+		{
+			name: "rules selected by options override the config",
+			opts: lint.Options{
+				Rules:         []string{"unused-global", "stack-missing-id"},
+				DisabledRules: []string{"stack-missing-id"},
+			},
+			layout: []string{
+				`f:terramate.tm:terramate {
+					config {
+						lint {
+							rules = ["stack-missing-id"]
+						}
+					}
+				}`,
+				`f:stack/stack.tm:stack {}
+				globals {
+					a = 1
+				}`,
+			},
+			want: []string{
+				`/stack/stack.tm:3:6: warning: global "a" is never referenced (unused-global)`,
+			},
+		},
+		{
+			name: "issues suppressed at specific locations",
+			layout: []string{
+				`f:globals.tm:globals {
+					# terramate-lint-ignore unused-global
+					a = 1
+					b = 2 // terramate-lint-ignore
+					c = 3 # terramate-lint-ignore stack-missing-id
+					/* terramate-lint-ignore stack-missing-id, unused-global */
+					d = 4
+					e = 5
+				}`,
+				`f:stack/stack.tm:# terramate-lint-ignore
+				stack {}`,
+			},
+			want: []string{
+				`/globals.tm:5:6: warning: global "c" is never referenced (unused-global)`,
+				`/globals.tm:8:6: warning: global "e" is never referenced (unused-global)`,
+			},
+		},
+		{
+			name: "unknown rules in ignore directives fail",
+			layout: []string{
+				`f:globals.tm:globals {
+					a = 1 # terramate-lint-ignore unknown
+				}`,
+			},
+			wantErr: errors.E(lint.ErrUnknownRule),
+		},
+		{
+			name: "unknown rules fail",
+			opts: lint.Options{
+				Rules:         []string{"unknown"},
+				DisabledRules: []string{"other"},
+			},
+			layout: []string{
+				`f:stack/stack.tm:stack {}`,
+			},
+			wantErr: errors.L(
+				errors.E(lint.ErrUnknownRule),
+				errors.E(lint.ErrUnknownRule),
+			),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := sandbox.NoGit(t, true)
+			s.BuildTree(tc.layout)
+
+			root, err := config.LoadRoot(s.RootDir())
+			assert.NoError(t, err)
+
+			dir := tc.dir
+			if dir == "" {
+				dir = "/"
+			}
+			issues, err := lint.Lint(root, project.NewPath(dir), tc.opts)
+			errtest.Assert(t, err, tc.wantErr)
+			if tc.wantErr != nil {
+				return
+			}
+
+			var got []string
+			for _, issue := range issues {
+				got = append(got, issue.String())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("-(want) +(got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintSARIF(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		`f:stack/stack.tm:stack {}`,
+	})
+
+	root, err := config.LoadRoot(s.RootDir())
+	assert.NoError(t, err)
+
+	issues, err := lint.Lint(root, project.NewPath("/"), lint.Options{})
+	assert.NoError(t, err)
+
+	data, err := issues.SARIF("1.0.0")
+	assert.NoError(t, err)
+
+	var got struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+					Rules   []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &got))
+
+	assert.EqualStrings(t, "2.1.0", got.Version)
+	assert.EqualInts(t, 1, len(got.Runs))
+
+	run := got.Runs[0]
+	assert.EqualStrings(t, "terramate", run.Tool.Driver.Name)
+	assert.EqualStrings(t, "1.0.0", run.Tool.Driver.Version)
+	assert.EqualInts(t, len(lint.Rules()), len(run.Tool.Driver.Rules))
+	assert.EqualInts(t, 1, len(run.Results))
+
+	result := run.Results[0]
+	assert.EqualStrings(t, "stack-missing-id", result.RuleID)
+	assert.EqualStrings(t, "stack-missing-id", run.Tool.Driver.Rules[result.RuleIndex].ID)
+	assert.EqualStrings(t, "warning", result.Level)
+	assert.EqualStrings(t, "stack has no id", result.Message.Text)
+	assert.EqualInts(t, 1, len(result.Locations))
+	assert.EqualStrings(t, "stack/stack.tm", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.EqualInts(t, 1, result.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.EqualInts(t, 1, result.Locations[0].PhysicalLocation.Region.StartColumn)
+}
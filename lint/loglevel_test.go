@@ -0,0 +1,10 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package lint_test
+
+import "github.com/rs/zerolog"
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+}
@@ -0,0 +1,436 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terramate-io/terramate/hcl/info"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/tf"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var rules = []Rule{
+	{
+		Name:        "unused-global",
+		Description: "Global defined but never referenced in the project",
+		Severity:    SeverityWarning,
+		check:       checkUnusedGlobals,
+	},
+	{
+		Name:        "unused-let",
+		Description: "Let variable defined but never referenced in its generate block",
+		Severity:    SeverityWarning,
+		check:       checkUnusedLets,
+	},
+	{
+		Name:        "stack-missing-id",
+		Description: "Stack without an id",
+		Severity:    SeverityWarning,
+		check:       checkStacksMissingID,
+	},
+	{
+		Name:        "stack-order-missing-path",
+		Description: "Path in stack.after or stack.before which does not contain any stack",
+		Severity:    SeverityError,
+		check:       checkStackOrderPaths,
+	},
+	{
+		Name:        "duplicated-generate-label",
+		Description: "Unconditional generate blocks with the same label in the same directory hierarchy",
+		Severity:    SeverityError,
+		check:       checkDuplicatedGenerateLabels,
+	},
+	{
+		Name:        "empty-import",
+		Description: "Import of a file which defines nothing",
+		Severity:    SeverityWarning,
+		check:       checkEmptyImports,
+	},
+	{
+		Name:        "deprecated-metadata",
+		Description: "Usage of deprecated terramate.path, terramate.name and terramate.description metadata",
+		Severity:    SeverityWarning,
+		check:       checkDeprecatedMetadata,
+	},
+}
+
+// definition is a named definition found in the configuration.
+type definition struct {
+	name string
+	rng  hhcl.Range
+}
+
+func checkUnusedGlobals(p *projectFiles) []finding {
+	var defs []definition
+	used := map[string]bool{}
+	allUsed := false
+
+	for _, f := range p.files {
+		for _, block := range f.body.Blocks {
+			if block.Type != "globals" {
+				continue
+			}
+			if len(block.Labels) > 0 {
+				defs = append(defs, definition{block.Labels[0], block.LabelRanges[0]})
+				continue
+			}
+			defs = append(defs, attrDefinitions(block.Body)...)
+		}
+
+		refs, all := references(f.body, "global")
+		allUsed = allUsed || all || callsFunction(f.body, "tm_stack_globals")
+		for _, ref := range refs {
+			used[ref] = true
+		}
+	}
+
+	if allUsed {
+		return nil
+	}
+
+	var findings []finding
+	for _, def := range defs {
+		if !used[def.name] {
+			findings = append(findings, p.finding(def.rng, "global %q is never referenced", def.name))
+		}
+	}
+	return findings
+}
+
+func checkUnusedLets(p *projectFiles) []finding {
+	var findings []finding
+	for _, f := range p.files {
+		for _, block := range f.body.Blocks {
+			if block.Type != "generate_hcl" && block.Type != "generate_file" {
+				continue
+			}
+
+			var defs []definition
+			for _, sub := range block.Body.Blocks {
+				if sub.Type == "lets" {
+					defs = append(defs, attrDefinitions(sub.Body)...)
+				}
+			}
+			if len(defs) == 0 {
+				continue
+			}
+
+			refs, all := references(block.Body, "let")
+			if all {
+				continue
+			}
+			used := map[string]bool{}
+			for _, ref := range refs {
+				used[ref] = true
+			}
+			for _, def := range defs {
+				if !used[def.name] {
+					findings = append(findings, p.finding(def.rng,
+						"let %q is never referenced in %s %q", def.name, block.Type, label(block)))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkStacksMissingID(p *projectFiles) []finding {
+	var findings []finding
+	for _, f := range p.files {
+		for _, block := range f.body.Blocks {
+			if block.Type != "stack" {
+				continue
+			}
+			if _, ok := block.Body.Attributes["id"]; !ok {
+				findings = append(findings, p.finding(block.DefRange(), "stack has no id"))
+			}
+		}
+	}
+	return findings
+}
+
+func checkStackOrderPaths(p *projectFiles) []finding {
+	var findings []finding
+	for _, f := range p.files {
+		for _, block := range f.body.Blocks {
+			if block.Type != "stack" {
+				continue
+			}
+			for _, attrName := range []string{"after", "before"} {
+				attr, ok := block.Body.Attributes[attrName]
+				if !ok {
+					continue
+				}
+				elems := []hclsyntax.Expression{attr.Expr}
+				if tuple, ok := attr.Expr.(*hclsyntax.TupleConsExpr); ok {
+					elems = tuple.Exprs
+				}
+				for _, elem := range elems {
+					for _, pathstr := range stringValues(elem) {
+						if strings.HasPrefix(pathstr, "tag:") {
+							continue
+						}
+						var target project.Path
+						if path.IsAbs(pathstr) {
+							target = project.NewPath(pathstr)
+						} else {
+							target = f.tree.Dir().Join(pathstr)
+						}
+						node, ok := p.root.Lookup(target)
+						if ok && len(node.Stacks()) > 0 {
+							continue
+						}
+						findings = append(findings, p.finding(elem.Range(),
+							"stack.%s path %q does not contain any stack", attrName, pathstr))
+					}
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkDuplicatedGenerateLabels(p *projectFiles) []finding {
+	type generateBlock struct {
+		dir project.Path
+		typ string
+		rng hhcl.Range
+	}
+
+	byLabel := map[string][]generateBlock{}
+	var labels []string
+	for _, f := range p.files {
+		for _, block := range f.body.Blocks {
+			if block.Type != "generate_hcl" && block.Type != "generate_file" {
+				continue
+			}
+			if len(block.Labels) != 1 || skipLabelCheck(block) {
+				continue
+			}
+			if _, ok := byLabel[block.Labels[0]]; !ok {
+				labels = append(labels, block.Labels[0])
+			}
+			byLabel[block.Labels[0]] = append(byLabel[block.Labels[0]], generateBlock{
+				dir: f.tree.Dir(),
+				typ: block.Type,
+				rng: block.LabelRanges[0],
+			})
+		}
+	}
+
+	var findings []finding
+	for _, name := range labels {
+		blocks := byLabel[name]
+		for i, block := range blocks {
+			for _, other := range blocks[:i] {
+				if !block.dir.HasDirPrefix(other.dir.String()) {
+					continue
+				}
+				findings = append(findings, p.finding(block.rng,
+					"%s label %q is also defined at %s", block.typ, name,
+					info.NewRange(p.root.HostDir(), other.rng)))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func checkEmptyImports(p *projectFiles) []finding {
+	var findings []finding
+	for _, f := range p.files {
+		for _, block := range f.body.Blocks {
+			if block.Type != "import" {
+				continue
+			}
+			srcAttr, ok := block.Body.Attributes["source"]
+			if !ok {
+				continue
+			}
+			srcs := stringValues(srcAttr.Expr)
+			if len(srcs) != 1 {
+				continue
+			}
+			src := srcs[0]
+			if _, err := tf.ParseSource(src); err == nil {
+				// importing a vendored copy without Terramate files already fails.
+				continue
+			}
+
+			var pattern string
+			if path.IsAbs(src) {
+				pattern = filepath.Join(p.root.HostDir(), filepath.FromSlash(src))
+			} else {
+				pattern = filepath.Join(filepath.Dir(f.path), filepath.FromSlash(src))
+			}
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				continue
+			}
+			sort.Strings(matches)
+
+			for _, match := range matches {
+				hclfile, diags := hclparse.NewParser().ParseHCLFile(match)
+				if diags.HasErrors() {
+					continue
+				}
+				body := hclfile.Body.(*hclsyntax.Body)
+				if len(body.Attributes) == 0 && len(body.Blocks) == 0 {
+					findings = append(findings, p.finding(srcAttr.Expr.Range(),
+						"imported file %s defines nothing",
+						project.PrjAbsPath(p.root.HostDir(), match)))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func checkDeprecatedMetadata(p *projectFiles) []finding {
+	replacements := map[string]string{
+		"path":        "terramate.stack.path.absolute",
+		"name":        "terramate.stack.name",
+		"description": "terramate.stack.description",
+	}
+
+	var findings []finding
+	for _, f := range p.files {
+		_ = hclsyntax.VisitAll(f.body, func(node hclsyntax.Node) hhcl.Diagnostics {
+			expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+			if !ok || expr.Traversal.RootName() != "terramate" || len(expr.Traversal) < 2 {
+				return nil
+			}
+			attr, ok := expr.Traversal[1].(hhcl.TraverseAttr)
+			if !ok {
+				return nil
+			}
+			if replacement, ok := replacements[attr.Name]; ok {
+				findings = append(findings, p.finding(expr.Range(),
+					"terramate.%s is deprecated, use %s instead", attr.Name, replacement))
+			}
+			return nil
+		})
+	}
+	return findings
+}
+
+func (p *projectFiles) finding(rng hhcl.Range, format string, args ...any) finding {
+	return finding{
+		msg: fmt.Sprintf(format, args...),
+		rng: info.NewRange(p.root.HostDir(), rng),
+	}
+}
+
+// attrDefinitions returns the attributes and map blocks defined in the body.
+func attrDefinitions(body *hclsyntax.Body) []definition {
+	var defs []definition
+	for _, attr := range body.Attributes {
+		defs = append(defs, definition{attr.Name, attr.NameRange})
+	}
+	for _, block := range body.Blocks {
+		if block.Type == "map" && len(block.Labels) == 1 {
+			defs = append(defs, definition{block.Labels[0], block.LabelRanges[0]})
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].rng.Start.Byte < defs[j].rng.Start.Byte
+	})
+	return defs
+}
+
+// references returns the names referenced in the namespace inside body,
+// e.g. the "a" in global.a.b, and tells if the namespace is referenced as a
+// whole, in which case every name must be considered referenced.
+func references(body *hclsyntax.Body, namespace string) (names []string, all bool) {
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hhcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+		if !ok || expr.Traversal.RootName() != namespace {
+			return nil
+		}
+		if len(expr.Traversal) < 2 {
+			all = true
+			return nil
+		}
+		switch step := expr.Traversal[1].(type) {
+		case hhcl.TraverseAttr:
+			names = append(names, step.Name)
+		case hhcl.TraverseIndex:
+			if step.Key.Type() == cty.String && step.Key.IsKnown() {
+				names = append(names, step.Key.AsString())
+			} else {
+				all = true
+			}
+		}
+		return nil
+	})
+
+	return names, all
+}
+
+func callsFunction(body *hclsyntax.Body, name string) bool {
+	found := false
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hhcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && call.Name == name {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// stringValues returns the strings of a literal string or list of strings
+// expression. Expressions which cannot be evaluated without a context are ignored.
+func stringValues(expr hclsyntax.Expression) []string {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return nil
+	}
+	if val.Type() == cty.String {
+		return []string{val.AsString()}
+	}
+	if !val.CanIterateElements() {
+		return nil
+	}
+	var strs []string
+	for it := val.ElementIterator(); it.Next(); {
+		_, elem := it.Element()
+		if elem.Type() == cty.String && !elem.IsNull() {
+			strs = append(strs, elem.AsString())
+		}
+	}
+	return strs
+}
+
+// skipLabelCheck tells if the generate block is conditional or generated in
+// the root context, so its label alone doesn't tell if it conflicts.
+func skipLabelCheck(block *hclsyntax.Block) bool {
+	if _, ok := block.Body.Attributes["condition"]; ok {
+		return true
+	}
+	if attr, ok := block.Body.Attributes["context"]; ok && hhcl.ExprAsKeyword(attr.Expr) == "root" {
+		return true
+	}
+	for _, sub := range block.Body.Blocks {
+		if sub.Type == "stack_filter" {
+			return true
+		}
+	}
+	return false
+}
+
+func label(block *hclsyntax.Block) string {
+	if len(block.Labels) == 0 {
+		return ""
+	}
+	return block.Labels[0]
+}
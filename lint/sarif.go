@@ -0,0 +1,137 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package lint
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+
+	sarifConfiguration struct {
+		Level Severity `json:"level"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     Severity        `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+)
+
+// SARIF returns the issues as a SARIF 2.1.0 log, suitable for code scanning
+// tools. The artifact locations are relative to the project root and the
+// version is the Terramate version reported as the tool version.
+func (issues Issues) SARIF(version string) ([]byte, error) {
+	driver := sarifDriver{
+		Name:           "terramate",
+		Version:        version,
+		InformationURI: "https://terramate.io/docs/cli/cmdline/lint",
+		Rules:          []sarifRule{},
+	}
+	ruleIndex := map[string]int{}
+	for i, rule := range rules {
+		ruleIndex[rule.Name] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Severity},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, issue := range issues {
+		results = append(results, sarifResult{
+			RuleID:    issue.Rule,
+			RuleIndex: ruleIndex[issue.Rule],
+			Level:     issue.Severity,
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{
+							URI: strings.TrimPrefix(issue.Range.Path().String(), "/"),
+						},
+						Region: sarifRegion{
+							StartLine:   issue.Range.Start().Line(),
+							StartColumn: issue.Range.Start().Column(),
+							EndLine:     issue.Range.End().Line(),
+							EndColumn:   issue.Range.End().Column(),
+						},
+					},
+				},
+			},
+		})
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool:    sarifTool{Driver: driver},
+				Results: results,
+			},
+		},
+	}, "", "  ")
+}
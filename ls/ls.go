@@ -12,15 +12,22 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
 	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/project"
 	"go.lsp.dev/jsonrpc2"
 	lsp "go.lsp.dev/protocol"
 	"go.lsp.dev/uri"
+	"golang.org/x/exp/slices"
 )
 
 // MethodExecuteCommand is the LSP method name for invoking server commands.
@@ -32,6 +39,16 @@ type Server struct {
 	workspace string
 	handlers  handlers
 
+	// mu guards root and documents, as the handlers can be called concurrently.
+	mu sync.Mutex
+
+	// root is the project configuration used for completions. It's loaded on
+	// the first completion and reset whenever a document is saved.
+	root *config.Root
+
+	// documents has the latest content of the documents opened in the editor.
+	documents map[string]string
+
 	log zerolog.Logger
 }
 
@@ -53,8 +70,9 @@ func NewServer(conn jsonrpc2.Conn) *Server {
 // ServerWithLogger creates a new language server with a custom logger.
 func ServerWithLogger(conn jsonrpc2.Conn, l zerolog.Logger) *Server {
 	s := &Server{
-		conn: conn,
-		log:  l,
+		conn:      conn,
+		log:       l,
+		documents: map[string]string{},
 	}
 	s.buildHandlers()
 	return s
@@ -180,6 +198,7 @@ func (s *Server) handleDocumentOpen(
 
 	fname := params.TextDocument.URI.Filename()
 	content := params.TextDocument.Text
+	s.setDocument(fname, content)
 
 	return s.checkAndReply(ctx, reply, fname, content)
 }
@@ -204,6 +223,7 @@ func (s *Server) handleDocumentChange(
 
 	content := params.ContentChanges[0].Text
 	fname := params.TextDocument.URI.Filename()
+	s.setDocument(fname, content)
 
 	return s.checkAndReply(ctx, reply, fname, content)
 }
@@ -220,6 +240,12 @@ func (s *Server) handleDocumentSaved(
 		return jsonrpc2.ErrParse
 	}
 
+	// the configuration on disk changed, so it's loaded again by the next
+	// completion.
+	s.mu.Lock()
+	s.root = nil
+	s.mu.Unlock()
+
 	fname := params.TextDocument.URI.Filename()
 	content, err := os.ReadFile(fname)
 	if err != nil {
@@ -227,6 +253,8 @@ func (s *Server) handleDocumentSaved(
 		return nil
 	}
 
+	s.setDocument(fname, string(content))
+
 	return s.checkAndReply(ctx, reply, fname, string(content))
 }
 
@@ -297,7 +325,208 @@ func (s *Server) handleCompletion(
 		return jsonrpc2.ErrParse
 	}
 	log.Debug().Str("params", string(r.Params()))
-	return reply(ctx, nil, nil)
+
+	fname := params.TextDocument.URI.Filename()
+	items := []lsp.CompletionItem{}
+	if content, ok := s.document(fname); ok {
+		items = append(items, schemaCompletion(content, params.Position)...)
+	}
+	globalItems, err := s.globalsCompletion(filepath.Dir(fname))
+	if err != nil {
+		log.Debug().Err(err).Msg("globals completion unavailable")
+	}
+	items = append(items, globalItems...)
+	if len(items) == 0 {
+		return reply(ctx, nil, nil)
+	}
+	return reply(ctx, lsp.CompletionList{Items: items}, nil)
+}
+
+// setDocument sets the latest content of the document.
+func (s *Server) setDocument(fname, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[fname] = content
+}
+
+// document returns the latest content of the document, as sent by the editor,
+// or its content on disk if the document was not opened.
+func (s *Server) document(fname string) (string, bool) {
+	s.mu.Lock()
+	content, ok := s.documents[fname]
+	s.mu.Unlock()
+	if ok {
+		return content, true
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// schemaCompletion returns the completion items for the attributes and blocks
+// accepted by the block enclosing the position, as described by [hcl.Schema].
+func schemaCompletion(content string, pos lsp.Position) []lsp.CompletionItem {
+	path, ok := enclosingBlocks(content, pos)
+	if !ok {
+		return nil
+	}
+	spec, ok := hcl.Schema().Block(path...)
+	if !ok {
+		return nil
+	}
+
+	items := []lsp.CompletionItem{}
+	for _, attr := range spec.Attributes {
+		item := lsp.CompletionItem{
+			Label:  attr.Name,
+			Kind:   lsp.CompletionItemKindProperty,
+			Detail: attr.Type,
+		}
+		if attr.Description != "" {
+			item.Documentation = lsp.MarkupContent{
+				Kind:  lsp.PlainText,
+				Value: attr.Description,
+			}
+		}
+		items = append(items, item)
+	}
+	for _, block := range spec.Blocks {
+		item := lsp.CompletionItem{
+			Label: block.Type,
+			Kind:  lsp.CompletionItemKindStruct,
+		}
+		if block.Description != "" {
+			item.Documentation = lsp.MarkupContent{
+				Kind:  lsp.PlainText,
+				Value: block.Description,
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// enclosingBlocks returns the types of the blocks enclosing the position, from
+// the outermost to the innermost. It returns false if the position is inside an
+// expression, like an object or a template.
+func enclosingBlocks(content string, pos lsp.Position) ([]string, bool) {
+	offset := 0
+	lines := strings.SplitAfter(content, "\n")
+	for i := 0; i < int(pos.Line) && i < len(lines); i++ {
+		offset += len(lines[i])
+	}
+	if int(pos.Line) < len(lines) {
+		offset += min(int(pos.Character), len(strings.TrimSuffix(lines[pos.Line], "\n")))
+	}
+
+	tokens, _ := hclsyntax.LexConfig([]byte(content), "", hhcl.InitialPos)
+
+	// the stack has the block type of each open brace, or an empty string
+	// for the braces which are not blocks, like object constructors.
+	var stack []string
+	var stmt hclsyntax.Tokens
+	for _, token := range tokens {
+		if token.Range.Start.Byte >= offset {
+			break
+		}
+		switch token.Type {
+		case hclsyntax.TokenOBrace:
+			blockType := ""
+			if len(stmt) > 0 && stmt[0].Type == hclsyntax.TokenIdent && !hasEqual(stmt) {
+				blockType = string(stmt[0].Bytes)
+			}
+			stack = append(stack, blockType)
+			stmt = nil
+		case hclsyntax.TokenCBrace:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			stmt = nil
+		case hclsyntax.TokenNewline:
+			stmt = nil
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			stack = append(stack, "")
+		case hclsyntax.TokenTemplateSeqEnd:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			stmt = append(stmt, token)
+		}
+	}
+
+	if hasEqual(stmt) || slices.Contains(stack, "") {
+		return nil, false
+	}
+	return stack, true
+}
+
+func hasEqual(tokens hclsyntax.Tokens) bool {
+	for _, token := range tokens {
+		if token.Type == hclsyntax.TokenEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// globalsCompletion returns the completion items for the globals declared by
+// the globals_schema blocks visible from dir.
+func (s *Server) globalsCompletion(dir string) ([]lsp.CompletionItem, error) {
+	root, err := s.loadRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, ok := root.Lookup(project.PrjAbsPath(root.HostDir(), dir))
+	if !ok {
+		return nil, errors.E("directory %s not found in the configuration", dir)
+	}
+
+	exprs, err := globals.LoadExprs(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []lsp.CompletionItem{}
+	for _, schema := range exprs.Schemas() {
+		item := lsp.CompletionItem{
+			Label:  schema.Name(),
+			Kind:   lsp.CompletionItemKindVariable,
+			Detail: typeexpr.TypeString(schema.Type),
+		}
+		if schema.Description != "" {
+			item.Documentation = lsp.MarkupContent{
+				Kind:  lsp.PlainText,
+				Value: schema.Description,
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// loadRoot returns the configuration of the project containing dir. The loaded
+// configuration is reused while dir is inside the same project.
+func (s *Server) loadRoot(dir string) (*config.Root, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.root != nil && (dir == s.root.HostDir() ||
+		strings.HasPrefix(dir, s.root.HostDir()+string(filepath.Separator))) {
+		return s.root, nil
+	}
+	root, _, found, err := config.TryLoadConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.E("no Terramate project found at %s", dir)
+	}
+	s.root = root
+	return root, nil
 }
 
 func (s *Server) sendDiagnostics(ctx context.Context, uri lsp.URI, diags []lsp.Diagnostic) {
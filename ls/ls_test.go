@@ -72,6 +72,142 @@ func TestDocumentRegressionErrorLoadingRootConfig(t *testing.T) {
 	assert.EqualStrings(t, file.Path(), params.URI.Filename())
 }
 
+func TestCompletionGlobalsSchema(t *testing.T) {
+	t.Parallel()
+	f := lstest.Setup(t)
+
+	f.Sandbox.RootEntry().CreateFile("schema.tm", `
+globals_schema "env" {
+  type        = string
+  description = "The deployment environment"
+}
+
+globals_schema "aws" "region" {
+  default = "us-east-1"
+}
+`)
+	stack := f.Sandbox.CreateStack("stack")
+	stack.CreateFile("globals.tm", "globals {\n  a = 1\n}\n")
+	f.Editor.CheckInitialize(f.Sandbox.RootDir())
+
+	got := f.Editor.Completion("stack/globals.tm", 1, 6)
+	want := lsp.CompletionList{
+		Items: []lsp.CompletionItem{
+			{
+				Label:  "global.aws.region",
+				Kind:   lsp.CompletionItemKindVariable,
+				Detail: "any",
+			},
+			{
+				Label:  "global.env",
+				Kind:   lsp.CompletionItemKindVariable,
+				Detail: "string",
+				Documentation: map[string]interface{}{
+					"kind":  "plaintext",
+					"value": "The deployment environment",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected completion items: want(-) got(+):\n%s", diff)
+	}
+}
+
+func TestCompletionSchema(t *testing.T) {
+	t.Parallel()
+
+	labels := func(list lsp.CompletionList, kind lsp.CompletionItemKind) []string {
+		res := []string{}
+		for _, item := range list.Items {
+			if item.Kind == kind {
+				res = append(res, item.Label)
+			}
+		}
+		return res
+	}
+
+	for _, tc := range []struct {
+		name       string
+		body       string
+		line, char uint32
+		attrs      []string
+		blocks     []string
+	}{
+		{
+			name:   "stack attributes",
+			body:   "stack {\n  \n}\n",
+			line:   1,
+			char:   2,
+			attrs:  []string{"id", "name", "description", "tags", "after", "before", "wants", "wanted_by", "watch"},
+			blocks: []string{},
+		},
+		{
+			name:   "nested block",
+			body:   "terramate {\n  config {\n    run {\n      \n    }\n  }\n}\n",
+			line:   3,
+			char:   6,
+			attrs:  []string{"check_gen_code"},
+			blocks: []string{"env"},
+		},
+		{
+			name:   "inside object expression",
+			body:   "stack {\n  tags = {\n    \n  }\n}\n",
+			line:   2,
+			char:   4,
+			attrs:  []string{},
+			blocks: []string{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			f := lstest.Setup(t)
+
+			f.Sandbox.RootEntry().CreateFile("cfg.tm", tc.body)
+			f.Editor.CheckInitialize(f.Sandbox.RootDir())
+
+			got := f.Editor.Completion("cfg.tm", tc.line, tc.char)
+			if diff := cmp.Diff(tc.attrs, labels(got, lsp.CompletionItemKindProperty)); diff != "" {
+				t.Fatalf("unexpected attributes: want(-) got(+):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.blocks, labels(got, lsp.CompletionItemKindStruct)); diff != "" {
+				t.Fatalf("unexpected blocks: want(-) got(+):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompletionReloadsConfigOnSave(t *testing.T) {
+	t.Parallel()
+	f := lstest.Setup(t)
+
+	f.Sandbox.RootEntry().CreateFile("schema.tm", `globals_schema "env" {}`)
+	stack := f.Sandbox.CreateStack("stack")
+	stack.CreateFile("globals.tm", "globals {\n  a = 1\n}\n")
+	f.Editor.CheckInitialize(f.Sandbox.RootDir())
+
+	got := f.Editor.Completion("stack/globals.tm", 1, 6)
+	assert.EqualInts(t, 1, len(got.Items))
+
+	// the loaded configuration is reused until a document is saved.
+	stack.CreateFile("schema.tm", `globals_schema "region" {}`)
+	got = f.Editor.Completion("stack/globals.tm", 1, 6)
+	assert.EqualInts(t, 1, len(got.Items))
+
+	f.Editor.Save("stack/schema.tm")
+	// diagnostics of the stack files: globals.tm, schema.tm and stack.tm.
+	for i := 0; i < 3; i++ {
+		r := <-f.Editor.Requests
+		assert.EqualStrings(t, "textDocument/publishDiagnostics", r.Method(),
+			"unexpected notification request")
+	}
+
+	got = f.Editor.Completion("stack/globals.tm", 1, 6)
+	assert.EqualInts(t, 2, len(got.Items))
+	assert.EqualStrings(t, "global.region", got.Items[1].Label)
+}
+
 func TestDocumentChange(t *testing.T) {
 	t.Skip("not ready")
 
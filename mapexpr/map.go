@@ -120,6 +120,9 @@ func (m *MapExpr) Value(ctx *hhcl.EvalContext) (cty.Value, hhcl.Diagnostics) {
 		return foreach, diags
 	}
 
+	// the map of a sensitive for_each is also sensitive.
+	foreach, foreachMarks := foreach.Unmark()
+
 	if !foreach.CanIterateElements() {
 		return cty.NilVal, hhcl.Diagnostics{
 			&hhcl.Diagnostic{
@@ -152,6 +155,8 @@ func (m *MapExpr) Value(ctx *hhcl.EvalContext) (cty.Value, hhcl.Diagnostics) {
 			return true
 		}
 
+		keyVal, _ = keyVal.Unmark()
+
 		oldElement, ok := objmap[keyVal.AsString()]
 		if ok {
 			iteratorMap["old"] = oldElement
@@ -215,7 +220,7 @@ func (m *MapExpr) Value(ctx *hhcl.EvalContext) (cty.Value, hhcl.Diagnostics) {
 	}
 
 	evaluator.DeleteNamespace(m.Attrs.Iterator)
-	return cty.ObjectVal(objmap), nil
+	return cty.ObjectVal(objmap).WithMarks(foreachMarks), nil
 }
 
 // Variables returns the outer variables referenced by the map block.
@@ -0,0 +1,6 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+// Package migrate rewrites Terramate configuration files from deprecated
+// syntax to the current syntax, preserving comments and formatting.
+package migrate
@@ -0,0 +1,368 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	hhcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/rs/zerolog/log"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/fs"
+	"github.com/terramate-io/terramate/hcl/ast"
+	hclfmt "github.com/terramate-io/terramate/hcl/fmt"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/safeguard"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/slices"
+)
+
+// ErrMigrate indicates that a configuration file could not be migrated.
+const ErrMigrate errors.Kind = "migrating configuration"
+
+// File is a configuration file changed by the migration.
+type File struct {
+	// Path is the path of the file relative to the project root.
+	Path project.Path
+
+	// Original is the content of the file before the migration.
+	Original []byte
+
+	// Migrated is the content of the file after the migration.
+	Migrated []byte
+
+	// Changes describes each kind of change done in the file.
+	Changes []string
+
+	hostpath string
+}
+
+// metadataReplacements maps the deprecated terramate metadata to its
+// replacement in the terramate.stack namespace.
+var metadataReplacements = []struct {
+	deprecated  string
+	replacement string
+}{
+	{"name", "stack.name"},
+	{"path", "stack.path.absolute"},
+	{"description", "stack.description"},
+}
+
+// safeguardReplacements maps the deprecated safeguard configurations to the
+// safeguard keyword disabled when they are set to false.
+var safeguardReplacements = []struct {
+	block   string
+	attr    string
+	keyword safeguard.Keyword
+}{
+	{"git", "check_untracked", safeguard.GitUntracked},
+	{"git", "check_uncommitted", safeguard.GitUncommitted},
+	{"git", "check_remote", safeguard.GitOutOfSync},
+	{"run", "check_gen_code", safeguard.Outdated},
+}
+
+// flagReplacements maps the deprecated safeguard flags to their replacement.
+var flagReplacements = map[string]string{
+	"--disable-check-gen-code":        "--disable-safeguards=outdated-code",
+	"--disable-check-git-remote":      "--disable-safeguards=git-out-of-sync",
+	"--disable-check-git-untracked":   "--disable-safeguards=git-untracked",
+	"--disable-check-git-uncommitted": "--disable-safeguards=git-uncommitted",
+}
+
+// Dir computes the migration of all Terramate files inside dir, recursively.
+// It returns the files which need changes, sorted by path, but doesn't write
+// them. See [File.Write].
+func Dir(root *config.Root, dir project.Path) ([]File, error) {
+	logger := log.With().
+		Str("action", "migrate.Dir()").
+		Stringer("dir", dir).
+		Logger()
+
+	tree, ok := root.Lookup(dir)
+	if !ok {
+		return nil, errors.E(ErrMigrate, "directory %s is not part of the project", dir)
+	}
+
+	trees := tree.AsList()
+	sort.Sort(trees)
+
+	var files []File
+	errs := errors.L()
+	for _, tree := range trees {
+		if _, err := os.Stat(filepath.Join(tree.HostDir(), config.SkipFilename)); err == nil {
+			continue
+		}
+
+		logger.Trace().Stringer("configdir", tree.Dir()).Msg("migrating directory")
+
+		migrated, err := migrateDir(root.HostDir(), tree.HostDir())
+		if err != nil {
+			errs.Append(err)
+			continue
+		}
+		files = append(files, migrated...)
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Diff returns the changes of the file as an unified diff.
+func (f File) Diff() string {
+	name := strings.TrimPrefix(f.Path.String(), "/")
+	return hclfmt.Diff(name, string(f.Original), string(f.Migrated))
+}
+
+// Write writes the migrated content to the file, keeping its file mode.
+func (f File) Write() error {
+	st, err := os.Stat(f.hostpath)
+	if err != nil {
+		return errors.E(ErrMigrate, err, "stat file %s", f.Path)
+	}
+	if err := os.WriteFile(f.hostpath, f.Migrated, st.Mode()); err != nil {
+		return errors.E(ErrMigrate, err, "writing file %s", f.Path)
+	}
+	return nil
+}
+
+// fileMigration is a file of a directory being migrated.
+type fileMigration struct {
+	File
+	parsed  *hclwrite.File
+	changes map[string]struct{}
+}
+
+func (f *fileMigration) change(format string, args ...any) {
+	f.changes[fmt.Sprintf(format, args...)] = struct{}{}
+}
+
+// migrateDir migrates the files of a single directory. They are migrated
+// together because the terramate.config block can be defined across files.
+func migrateDir(rootdir, dir string) ([]File, error) {
+	filenames, err := fs.ListTerramateFiles(dir)
+	if err != nil {
+		return nil, errors.E(ErrMigrate, err)
+	}
+
+	errs := errors.L()
+	var files []*fileMigration
+	for _, filename := range filenames {
+		hostpath := filepath.Join(dir, filename)
+		original, err := os.ReadFile(hostpath)
+		if err != nil {
+			errs.Append(errors.E(ErrMigrate, err, "reading file"))
+			continue
+		}
+		parsed, diags := hclwrite.ParseConfig(original, hostpath, hhcl.InitialPos)
+		if diags.HasErrors() {
+			errs.Append(errors.E(ErrMigrate, diags))
+			continue
+		}
+		files = append(files, &fileMigration{
+			File: File{
+				Path:     project.PrjAbsPath(rootdir, hostpath),
+				Original: original,
+				hostpath: hostpath,
+			},
+			parsed:  parsed,
+			changes: map[string]struct{}{},
+		})
+	}
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		migrateBody(f, f.parsed.Body())
+	}
+	if err := migrateSafeguards(files); err != nil {
+		return nil, err
+	}
+
+	var migrated []File
+	for _, f := range files {
+		if len(f.changes) == 0 {
+			continue
+		}
+		f.Migrated = hclfmt.KeepFormatting(f.Original, f.parsed.Bytes())
+		if bytes.Equal(f.Original, f.Migrated) {
+			continue
+		}
+		for change := range f.changes {
+			f.Changes = append(f.Changes, change)
+		}
+		sort.Strings(f.Changes)
+		migrated = append(migrated, f.File)
+	}
+	return migrated, nil
+}
+
+// migrateBody migrates the deprecated metadata in all expressions of the body
+// and the deprecated flags in script commands, recursively.
+func migrateBody(f *fileMigration, body *hclwrite.Body) {
+	for _, attr := range body.Attributes() {
+		expr := attr.Expr()
+		for _, r := range metadataReplacements {
+			before := string(expr.BuildTokens(nil).Bytes())
+			// The replacement has a single step with the dots inside, as
+			// hclwrite only renames traversals with the same number of steps.
+			expr.RenameVariablePrefix(
+				[]string{"terramate", r.deprecated},
+				[]string{"terramate", r.replacement},
+			)
+			if before != string(expr.BuildTokens(nil).Bytes()) {
+				f.change("replaced terramate.%s with terramate.%s", r.deprecated, r.replacement)
+			}
+		}
+	}
+	for _, block := range body.Blocks() {
+		if block.Type() == "script" {
+			migrateScriptFlags(f, block.Body())
+		}
+		migrateBody(f, block.Body())
+	}
+}
+
+func migrateScriptFlags(f *fileMigration, body *hclwrite.Body) {
+	for _, job := range body.Blocks() {
+		if job.Type() != "job" {
+			continue
+		}
+		for _, name := range []string{"command", "commands"} {
+			attr := job.Body().GetAttribute(name)
+			if attr == nil {
+				continue
+			}
+			// the tokens are shared with the file, so they are changed in place.
+			for _, tok := range attr.Expr().BuildTokens(nil) {
+				if tok.Type != hclsyntax.TokenQuotedLit {
+					continue
+				}
+				flag := string(tok.Bytes)
+				if replacement, ok := flagReplacements[flag]; ok {
+					tok.Bytes = []byte(replacement)
+					f.change("replaced %s with %s", flag, replacement)
+				}
+			}
+		}
+	}
+}
+
+// migrateSafeguards replaces the deprecated terramate.config.git.check_* and
+// terramate.config.run.check_gen_code attributes with the safeguards keywords
+// in terramate.config.disable_safeguards. The configuration loading already
+// forbids mixing both, so disable_safeguards is never defined at this point.
+func migrateSafeguards(files []*fileMigration) error {
+	var (
+		keywords        []string
+		comments        hclwrite.Tokens
+		firstConfig     *hclwrite.Body
+		firstConfigFile *fileMigration
+	)
+
+	for _, f := range files {
+		for _, cfg := range configBlocks(f.parsed.Body()) {
+			for _, r := range safeguardReplacements {
+				for _, block := range cfg.Body().Blocks() {
+					if block.Type() != r.block || len(block.Labels()) != 0 {
+						continue
+					}
+					attr := block.Body().GetAttribute(r.attr)
+					if attr == nil {
+						continue
+					}
+					enabled, ok := boolValue(attr.Expr())
+					if !ok {
+						continue
+					}
+					if !enabled && !slices.Contains(keywords, string(r.keyword)) {
+						keywords = append(keywords, string(r.keyword))
+					}
+					comments = append(comments, leadComments(attr.BuildTokens(nil))...)
+					block.Body().RemoveAttribute(r.attr)
+					if len(block.Body().Attributes()) == 0 && len(block.Body().Blocks()) == 0 {
+						comments = append(comments, leadComments(block.BuildTokens(nil))...)
+						cfg.Body().RemoveBlock(block)
+					}
+					if firstConfig == nil {
+						firstConfig, firstConfigFile = cfg.Body(), f
+					}
+					f.change("replaced terramate.config.%s.%s with terramate.config.disable_safeguards", r.block, r.attr)
+				}
+			}
+		}
+	}
+
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	values := make([]cty.Value, len(keywords))
+	for i, keyword := range keywords {
+		values[i] = cty.StringVal(keyword)
+	}
+	// the comments of the removed attributes and blocks are kept as the
+	// comments of disable_safeguards.
+	firstConfig.AppendUnstructuredTokens(comments)
+	firstConfig.SetAttributeValue("disable_safeguards", cty.ListVal(values))
+	firstConfigFile.change("set terramate.config.disable_safeguards")
+	return nil
+}
+
+// leadComments returns the comments preceding an attribute or a block, given
+// its tokens.
+func leadComments(tokens hclwrite.Tokens) hclwrite.Tokens {
+	var comments hclwrite.Tokens
+	for _, tok := range tokens {
+		if tok.Type != hclsyntax.TokenComment && tok.Type != hclsyntax.TokenNewline {
+			break
+		}
+		comments = append(comments, &hclwrite.Token{
+			Type:  tok.Type,
+			Bytes: tok.Bytes,
+		})
+	}
+	return comments
+}
+
+func configBlocks(body *hclwrite.Body) []*hclwrite.Block {
+	var blocks []*hclwrite.Block
+	for _, tm := range body.Blocks() {
+		if tm.Type() != "terramate" {
+			continue
+		}
+		for _, cfg := range tm.Body().Blocks() {
+			if cfg.Type() == "config" {
+				blocks = append(blocks, cfg)
+			}
+		}
+	}
+	return blocks
+}
+
+func parseExpr(expr *hclwrite.Expression) (hhcl.Expression, error) {
+	return ast.ParseExpression(string(expr.BuildTokens(nil).Bytes()), "<migrate>")
+}
+
+// boolValue returns the value of a literal bool expression.
+func boolValue(expr *hclwrite.Expression) (bool, bool) {
+	parsed, err := parseExpr(expr)
+	if err != nil {
+		return false, false
+	}
+	val, diags := parsed.Value(nil)
+	if diags.HasErrors() || val.Type() != cty.Bool || val.IsNull() {
+		return false, false
+	}
+	return val.True(), true
+}
@@ -0,0 +1,300 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package migrate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/migrate"
+	"github.com/terramate-io/terramate/project"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/test/sandbox"
+)
+
+type (
+	file struct {
+		path    string
+		body    string
+		changes []string
+	}
+
+	testcase struct {
+		name   string
+		layout []string
+		dir    string
+		want   []file
+	}
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []testcase{
+		{
+			name: "no deprecated syntax",
+			layout: []string{
+				`f:stack/stack.tm:stack {
+					name = "stack"
+				}
+				globals {
+					name = terramate.stack.name # current syntax
+				}`,
+			},
+		},
+		{
+			name: "deprecated metadata",
+			layout: []string{
+				"f:stack/stack.tm:" + `stack {}
+
+# the stack metadata
+globals {
+  name = terramate.name # the name
+  path = "${terramate.path}/sub"
+  desc = tm_upper(terramate.description)
+}
+
+generate_hcl "file.tf" {
+  content {
+    stack = terramate.path
+  }
+}
+`,
+			},
+			want: []file{
+				{
+					path: "/stack/stack.tm",
+					body: `stack {}
+
+# the stack metadata
+globals {
+  name = terramate.stack.name # the name
+  path = "${terramate.stack.path.absolute}/sub"
+  desc = tm_upper(terramate.stack.description)
+}
+
+generate_hcl "file.tf" {
+  content {
+    stack = terramate.stack.path.absolute
+  }
+}
+`,
+					changes: []string{
+						"replaced terramate.description with terramate.stack.description",
+						"replaced terramate.name with terramate.stack.name",
+						"replaced terramate.path with terramate.stack.path.absolute",
+					},
+				},
+			},
+		},
+		{
+			name: "deprecated safeguards configuration",
+			layout: []string{
+				"f:terramate.tm:" + `terramate {
+  config {
+    git {
+      default_branch = "main"
+      # keep untracked files
+      check_untracked = false
+      check_remote    = true
+    }
+
+    # the generated code is checked in CI
+    run {
+      check_gen_code = false
+    }
+  }
+}
+`,
+			},
+			want: []file{
+				{
+					path: "/terramate.tm",
+					body: `terramate {
+  config {
+    git {
+      default_branch = "main"
+    }
+
+    # keep untracked files
+    # the generated code is checked in CI
+    disable_safeguards = ["git-untracked", "outdated-code"]
+  }
+}
+`,
+					changes: []string{
+						"replaced terramate.config.git.check_remote with terramate.config.disable_safeguards",
+						"replaced terramate.config.git.check_untracked with terramate.config.disable_safeguards",
+						"replaced terramate.config.run.check_gen_code with terramate.config.disable_safeguards",
+						"set terramate.config.disable_safeguards",
+					},
+				},
+			},
+		},
+		{
+			name: "deprecated flags in scripts",
+			layout: []string{
+				`f:terramate.tm:terramate {
+					config {
+						experiments = ["scripts"]
+					}
+				}`,
+				"f:script.tm:" + `script "deploy" {
+  description = "deploy"
+  job {
+    command = ["terramate", "run", "--disable-check-gen-code", "--", "terraform", "apply"]
+  }
+  job {
+    commands = [
+      ["terramate", "run", "--disable-check-git-remote", "--", "terraform", "plan"],
+    ]
+  }
+}
+`,
+			},
+			want: []file{
+				{
+					path: "/script.tm",
+					body: `script "deploy" {
+  description = "deploy"
+  job {
+    command = ["terramate", "run", "--disable-safeguards=outdated-code", "--", "terraform", "apply"]
+  }
+  job {
+    commands = [
+      ["terramate", "run", "--disable-safeguards=git-out-of-sync", "--", "terraform", "plan"],
+    ]
+  }
+}
+`,
+					changes: []string{
+						"replaced --disable-check-gen-code with --disable-safeguards=outdated-code",
+						"replaced --disable-check-git-remote with --disable-safeguards=git-out-of-sync",
+					},
+				},
+			},
+		},
+		{
+			name: "only files inside dir",
+			dir:  "/stack2",
+			layout: []string{
+				`f:stack1/stack.tm:globals {
+					a = terramate.name
+				}`,
+				`f:stack2/stack.tm:globals {
+					a = terramate.name
+				}`,
+				`f:stack2/child/stack.tm:globals {
+					a = terramate.name
+				}`,
+			},
+			want: []file{
+				{
+					path: "/stack2/stack.tm",
+					body: `globals {
+					a = terramate.stack.name
+				}`,
+					changes: []string{
+						"replaced terramate.name with terramate.stack.name",
+					},
+				},
+				{
+					path: "/stack2/child/stack.tm",
+					body: `globals {
+					a = terramate.stack.name
+				}`,
+					changes: []string{
+						"replaced terramate.name with terramate.stack.name",
+					},
+				},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := sandbox.NoGit(t, true)
+			s.BuildTree(tc.layout)
+
+			root, err := config.LoadRoot(s.RootDir())
+			assert.NoError(t, err)
+
+			dir := tc.dir
+			if dir == "" {
+				dir = "/"
+			}
+			files, err := migrate.Dir(root, project.NewPath(dir))
+			assert.NoError(t, err)
+
+			var got []file
+			for _, f := range files {
+				got = append(got, file{
+					path:    f.Path.String(),
+					body:    string(f.Migrated),
+					changes: f.Changes,
+				})
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(file{})); diff != "" {
+				t.Fatalf("-(want) +(got):\n%s", diff)
+			}
+
+			// the files are only changed when written.
+			for _, f := range files {
+				assert.EqualStrings(t,
+					string(f.Original),
+					string(test.ReadFile(t, s.RootDir(), f.Path.String())))
+			}
+		})
+	}
+}
+
+func TestMigrateWrite(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"f:stack/stack.tm:" + `stack {}
+
+globals {
+  name = terramate.name
+}
+`,
+	})
+	assert.NoError(t, os.Chmod(filepath.Join(s.RootDir(), "stack", "stack.tm"), 0600))
+
+	root, err := config.LoadRoot(s.RootDir())
+	assert.NoError(t, err)
+
+	files, err := migrate.Dir(root, project.NewPath("/"))
+	assert.NoError(t, err)
+	assert.EqualInts(t, 1, len(files))
+
+	assert.EqualStrings(t, `--- a/stack/stack.tm
++++ b/stack/stack.tm
+@@ -1,5 +1,5 @@
+ stack {}
+`+" \n"+` globals {
+-  name = terramate.name
++  name = terramate.stack.name
+ }
+`, files[0].Diff())
+
+	assert.NoError(t, files[0].Write())
+
+	assert.EqualStrings(t, string(files[0].Migrated),
+		string(test.ReadFile(t, s.RootDir(), "stack/stack.tm")))
+
+	st, err := os.Stat(filepath.Join(s.RootDir(), "stack", "stack.tm"))
+	assert.NoError(t, err)
+	assert.IsTrue(t, st.Mode().Perm() == 0600)
+
+	files, err = migrate.Dir(root, project.NewPath("/"))
+	assert.NoError(t, err)
+	assert.EqualInts(t, 0, len(files))
+}
@@ -7,6 +7,7 @@ package download
 import (
 	"fmt"
 	iofs "io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -403,21 +404,33 @@ func downloadVendor(
 			Msg("dropped progress event, event handler is not fast enough or absent")
 	}
 
-	if err := g.Clone(modsrc.URL, clonedRepoDir); err != nil {
+	// Registry sources are cloned from the Git source of their module package,
+	// and only the module directory of the package is vendored.
+	pkgsrc := modsrc
+	moduleDir := clonedRepoDir
+	if modsrc.PathScheme == tf.RegistryScheme {
+		pkgsrc, err = tf.ResolveRegistrySource(http.DefaultClient, modsrc)
+		if err != nil {
+			return "", err
+		}
+		moduleDir = filepath.Join(clonedRepoDir, filepath.FromSlash(pkgsrc.Subdir))
+	}
+
+	if err := g.Clone(pkgsrc.URL, clonedRepoDir); err != nil {
 		return "", err
 	}
 
 	const create = false
 
-	if err := g.Checkout(modsrc.Ref, create); err != nil {
-		return "", errors.E(err, "checking ref %s", modsrc.Ref)
+	if err := g.Checkout(pkgsrc.Ref, create); err != nil {
+		return "", errors.E(err, "checking ref %s", pkgsrc.Ref)
 	}
 
 	if err := os.RemoveAll(filepath.Join(clonedRepoDir, ".git")); err != nil {
 		return "", errors.E(err, "removing .git dir from cloned repo")
 	}
 
-	matcher, err := manifest.LoadFileMatcher(clonedRepoDir)
+	matcher, err := manifest.LoadFileMatcher(moduleDir)
 	if err != nil {
 		return "", err
 	}
@@ -429,11 +442,11 @@ func downloadVendor(
 			return true
 		}
 		abspath := filepath.Join(path, entry.Name())
-		relpath := strings.TrimPrefix(abspath, clonedRepoDir+pathSeparator)
+		relpath := strings.TrimPrefix(abspath, moduleDir+pathSeparator)
 		return matcher.Match(strings.Split(relpath, pathSeparator), entry.IsDir())
 	}
 
-	if err := fs.CopyDir(tmTempDir, clonedRepoDir, fileFilter); err != nil {
+	if err := fs.CopyDir(tmTempDir, moduleDir, fileFilter); err != nil {
 		return "", errors.E(err, "copying cloned module")
 	}
 
@@ -39,6 +39,16 @@ type EnvVars []string
 // inside the given stack. The order of the env vars is guaranteed to be the same
 // and is ordered lexicographically.
 func LoadEnv(root *config.Root, st *config.Stack) (EnvVars, error) {
+	return loadEnv(root, st, false)
+}
+
+// LoadRedactedEnv is like [LoadEnv] but the values of the sensitive
+// environment variables are redacted, so they can be shown to the user.
+func LoadRedactedEnv(root *config.Root, st *config.Stack) (EnvVars, error) {
+	return loadEnv(root, st, true)
+}
+
+func loadEnv(root *config.Root, st *config.Stack, redact bool) (EnvVars, error) {
 	logger := log.With().
 		Str("action", "run.Env()").
 		Str("root", root.HostDir()).
@@ -55,6 +65,7 @@ func LoadEnv(root *config.Root, st *config.Stack) (EnvVars, error) {
 	}
 
 	evalctx := eval.NewContext(stdlib.Functions(st.HostDir(root)))
+	globals.SetFunctions(root, evalctx)
 	runtime := root.Runtime()
 	runtime.Merge(st.RuntimeValues(root))
 	evalctx.SetNamespace("terramate", runtime)
@@ -83,8 +94,18 @@ func LoadEnv(root *config.Root, st *config.Stack) (EnvVars, error) {
 				val.Type().FriendlyName(),
 			)
 		}
-		envVars = append(envVars, attr.Name+"="+val.AsString())
 
+		redacted := eval.Redact(val).AsString()
+		logger.Debug().
+			Str("value", redacted).
+			Msg("loaded environment variable")
+
+		if redact {
+			envVars = append(envVars, attr.Name+"="+redacted)
+		} else {
+			val, _ = val.Unmark()
+			envVars = append(envVars, attr.Name+"="+val.AsString())
+		}
 	}
 
 	return envVars, nil
@@ -249,6 +249,48 @@ func TestLoadRunEnv(t *testing.T) {
 	}
 }
 
+func TestLoadRedactedRunEnv(t *testing.T) {
+	t.Parallel()
+
+	s := sandbox.NoGit(t, true)
+	s.BuildTree([]string{
+		"s:stack",
+		`f:run_env.tm:terramate {
+  config {
+    run {
+      env {
+        TOKEN = tm_sensitive("secret")
+        DSN   = "admin:${tm_sensitive("secret")}@localhost"
+        USER  = "admin"
+      }
+    }
+  }
+}`,
+	})
+
+	root, err := config.LoadRoot(s.RootDir())
+	assert.NoError(t, err)
+
+	stack, err := config.LoadStack(root, project.NewPath("/stack"))
+	assert.NoError(t, err)
+
+	gotvars, err := run.LoadEnv(root, stack)
+	assert.NoError(t, err)
+	test.AssertDiff(t, gotvars, run.EnvVars{
+		"DSN=admin:secret@localhost",
+		"TOKEN=secret",
+		"USER=admin",
+	})
+
+	gotvars, err = run.LoadRedactedEnv(root, stack)
+	assert.NoError(t, err)
+	test.AssertDiff(t, gotvars, run.EnvVars{
+		"DSN=(sensitive value)",
+		"TOKEN=(sensitive value)",
+		"USER=admin",
+	})
+}
+
 func init() {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 }
@@ -20,6 +20,7 @@ const (
 	GitUncommitted Keyword = "git-uncommitted"
 	GitOutOfSync   Keyword = "git-out-of-sync"
 	Outdated       Keyword = "outdated-code"
+	Assertions     Keyword = "assertions"
 )
 
 // FromStrings constructs a Keywords list out of a list of strings.
@@ -67,6 +68,7 @@ func (k Keyword) IsValid() bool {
 		GitUncommitted: true,
 		GitOutOfSync:   true,
 		Outdated:       true,
+		Assertions:     true,
 	}
 	return valid[k]
 }
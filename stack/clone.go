@@ -9,13 +9,11 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
-	hhcl "github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/rs/zerolog/log"
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/fs"
-	"github.com/terramate-io/terramate/hcl"
+	"github.com/terramate-io/terramate/hcl/edit"
 	"github.com/terramate-io/terramate/project"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -146,82 +144,26 @@ func Clone(root *config.Root, destdir, srcdir string, skipChildStacks bool) (int
 }
 
 // UpdateStackID updates the stack.id of the given stack directory.
-// The functions updates just the file which defines the stack block.
-// The updated file will lose all comments.
+// The functions updates just the file which defines the stack block, keeping
+// its comments.
+// It returns the generated ID.
 func UpdateStackID(stackdir string) (string, error) {
-	parser, err := hcl.NewTerramateParser(stackdir, stackdir)
+	dir, err := edit.LoadDir(stackdir, stackdir)
 	if err != nil {
 		return "", err
 	}
 
-	if err := parser.AddDir(stackdir); err != nil {
-		return "", err
-	}
-
-	if err := parser.Parse(); err != nil {
-		return "", err
-	}
-
-	stackFilePath := getStackFilepath(parser)
-	if stackFilePath == "" {
-		return "", errors.E("stack does not have a stack block")
-	}
-
-	st, err := os.Lstat(stackFilePath)
+	uuid, err := uuid.NewRandom()
 	if err != nil {
-		return "", errors.E(err, "stating the stack file")
+		return "", errors.E(err, "creating new ID for stack")
 	}
 
-	originalFileMode := st.Mode()
-
-	// Parsing HCL always delivers an AST that
-	// has no comments on it, so building a new HCL file from the parsed
-	// AST will lose all comments from the original code.
-
-	stackContents, err := os.ReadFile(stackFilePath)
-	if err != nil {
-		return "", errors.E(err, "reading stack definition file")
-	}
-
-	parsed, diags := hclwrite.ParseConfig([]byte(stackContents), stackFilePath, hhcl.InitialPos)
-	if diags.HasErrors() {
-		return "", errors.E(diags, "parsing stack configuration")
-	}
-
-	blocks := parsed.Body().Blocks()
-
-	for _, block := range blocks {
-		if block.Type() != hcl.StackBlockType {
-			continue
-		}
-
-		uuid, err := uuid.NewRandom()
-		if err != nil {
-			return "", errors.E(err, "creating new ID for stack")
-		}
-
-		id := uuid.String()
-
-		body := block.Body()
-		body.SetAttributeValue("id", cty.StringVal(id))
-
-		err = os.WriteFile(stackFilePath, parsed.Bytes(), originalFileMode)
-		if err != nil {
-			return "", err
-		}
-		return id, nil
+	id := uuid.String()
+	if err := dir.SetStackAttribute("id", cty.StringVal(id)); err != nil {
+		return "", err
 	}
-
-	return "", errors.E("stack block not found")
-}
-
-func getStackFilepath(parser *hcl.TerramateParser) string {
-	for filepath, body := range parser.ParsedBodies() {
-		for _, block := range body.Blocks {
-			if block.Type == hcl.StackBlockType {
-				return filepath
-			}
-		}
+	if err := dir.Save(); err != nil {
+		return "", err
 	}
-	return ""
+	return id, nil
 }
@@ -94,6 +94,9 @@ func Create(root *config.Root, stack config.Stack, imports ...string) (err error
 		}
 	}()
 
+	// The stack file is always a new file, so there is no existing
+	// configuration to edit with the hcl/edit package, and the stack block
+	// is printed with the canonical order of its attributes.
 	if err := hcl.PrintConfig(stackFile, tmCfg); err != nil {
 		return errors.E(err, "writing stack config to stack file")
 	}
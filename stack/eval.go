@@ -4,7 +4,10 @@
 package stack
 
 import (
+	"github.com/rs/zerolog/log"
 	"github.com/terramate-io/terramate/config"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/globals"
 	"github.com/terramate-io/terramate/hcl/eval"
 	"github.com/terramate-io/terramate/stdlib"
 )
@@ -17,14 +20,15 @@ type EvalCtx struct {
 }
 
 // NewEvalCtx creates a new stack evaluation context.
-func NewEvalCtx(root *config.Root, stack *config.Stack, globals *eval.Object) *EvalCtx {
+func NewEvalCtx(root *config.Root, stack *config.Stack, g *eval.Object) *EvalCtx {
 	evalctx := eval.NewContext(stdlib.Functions(stack.HostDir(root)))
+	globals.SetFunctions(root, evalctx)
 	evalwrapper := &EvalCtx{
 		Context: evalctx,
 		root:    root,
 	}
 	evalwrapper.SetMetadata(stack)
-	evalwrapper.SetGlobals(globals)
+	evalwrapper.SetGlobals(g)
 	return evalwrapper
 }
 
@@ -39,3 +43,46 @@ func (e *EvalCtx) SetMetadata(st *config.Stack) {
 	runtime.Merge(st.RuntimeValues(e.root))
 	e.SetNamespace("terramate", runtime)
 }
+
+// LoadAsserts evaluates the assert blocks of the stack directory and all its
+// parent directories, using the stack metadata and the given globals.
+func LoadAsserts(root *config.Root, st *config.Stack, g *eval.Object) ([]config.Assert, error) {
+	logger := log.With().
+		Str("action", "stack.LoadAsserts()").
+		Str("rootdir", root.HostDir()).
+		Stringer("stack", st.Dir).
+		Logger()
+
+	curdir := st.Dir
+	asserts := []config.Assert{}
+	errs := errors.L()
+
+	evalctx := NewEvalCtx(root, st, g)
+	for {
+		logger.Trace().Stringer("curdir", curdir).Msg("loading asserts")
+
+		cfg, ok := root.Lookup(curdir)
+		if ok {
+			for _, assertCfg := range cfg.Node.Asserts {
+				assert, err := config.EvalAssert(evalctx.Context, assertCfg)
+				if err != nil {
+					errs.Append(err)
+				} else {
+					asserts = append(asserts, assert)
+				}
+			}
+		}
+
+		if p := curdir.Dir(); p != curdir {
+			curdir = p
+		} else {
+			break
+		}
+	}
+
+	if err := errs.AsError(); err != nil {
+		return nil, err
+	}
+
+	return asserts, nil
+}
@@ -12,16 +12,19 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/rs/zerolog/log"
 	"github.com/terramate-io/terramate/config"
 	"github.com/terramate-io/terramate/errors"
 	"github.com/terramate-io/terramate/git"
+	"github.com/terramate-io/terramate/hcl"
 	"github.com/terramate-io/terramate/printer"
 	"github.com/terramate-io/terramate/project"
 	"github.com/terramate-io/terramate/run"
 	"github.com/terramate-io/terramate/run/dag"
 	"github.com/terramate-io/terramate/stack/trigger"
 	"github.com/terramate-io/terramate/tf"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type (
@@ -231,6 +234,40 @@ rangeStacks:
 			continue rangeStacks
 		}
 
+		if changed, ok := m.hasChangedTemplateFiles(stack, changedFiles); ok {
+			logger.Debug().
+				Stringer("stack", stack).
+				Stringer("template", changed).
+				Msg("changed.")
+
+			stack.IsChanged = true
+			stackSet[stack.Dir] = Entry{
+				Stack: stack,
+				Reason: fmt.Sprintf(
+					"stack changed because template file %q changed",
+					changed,
+				),
+			}
+			continue rangeStacks
+		}
+
+		if changed, ok := m.hasChangedGlobalsDataFiles(stack, changedFiles); ok {
+			logger.Debug().
+				Stringer("stack", stack).
+				Stringer("datafile", changed).
+				Msg("changed.")
+
+			stack.IsChanged = true
+			stackSet[stack.Dir] = Entry{
+				Stack: stack,
+				Reason: fmt.Sprintf(
+					"stack changed because globals data file %q changed",
+					changed,
+				),
+			}
+			continue rangeStacks
+		}
+
 		err := m.filesApply(stack.HostDir(m.root), func(file fs.DirEntry) error {
 			if path.Ext(file.Name()) != ".tf" {
 				return nil
@@ -510,6 +547,76 @@ func hasChangedWatchedFiles(stack *config.Stack, changedFiles []string) (project
 	return project.Path{}, false
 }
 
+// hasChangedTemplateFiles checks if any template file used by the generate_file
+// blocks visible to the stack has changed. The blocks which never generate
+// files for the stack, because of its stack_filter blocks or a literal false
+// condition, are ignored.
+func (m *Manager) hasChangedTemplateFiles(stack *config.Stack, changedFiles []string) (project.Path, bool) {
+	return m.hasChangedConfigFiles(stack, changedFiles, func(cfg hcl.Config) []project.Path {
+		var files []project.Path
+		for _, block := range cfg.Generate.Files {
+			if block.TemplateFile == nil || block.Context != "stack" ||
+				!hcl.MatchStackFilters(block.StackFilters, stack.Dir.String()) ||
+				isLiteralFalse(block.Condition) {
+				continue
+			}
+			files = append(files, block.TemplateFile.Path)
+		}
+		return files
+	})
+}
+
+// hasChangedGlobalsDataFiles checks if any globals data file visible to the
+// stack has changed.
+func (m *Manager) hasChangedGlobalsDataFiles(stack *config.Stack, changedFiles []string) (project.Path, bool) {
+	return m.hasChangedConfigFiles(stack, changedFiles, func(cfg hcl.Config) []project.Path {
+		files := make([]project.Path, len(cfg.GlobalsDataFiles))
+		for i, datafile := range cfg.GlobalsDataFiles {
+			files[i] = datafile.Path
+		}
+		return files
+	})
+}
+
+// hasChangedConfigFiles checks if any of the files referenced by the
+// configuration of the stack directory, or of its parent directories, has
+// changed. The referenced files are extracted from each configuration by the
+// files function.
+func (m *Manager) hasChangedConfigFiles(
+	stack *config.Stack,
+	changedFiles []string,
+	files func(cfg hcl.Config) []project.Path,
+) (project.Path, bool) {
+	for dir := stack.Dir; ; dir = dir.Dir() {
+		cfg, ok := m.root.Lookup(dir)
+		if ok && !cfg.IsEmptyConfig() {
+			for _, path := range files(cfg.Node) {
+				for _, file := range changedFiles {
+					if file == path.String()[1:] { // project paths
+						return path, true
+					}
+				}
+			}
+		}
+		if dir == dir.Dir() {
+			return project.Path{}, false
+		}
+	}
+}
+
+// isLiteralFalse tells if the condition attribute is the false literal, or any
+// expression which evaluates to false without an evaluation context.
+func isLiteralFalse(cond *hclsyntax.Attribute) bool {
+	if cond == nil {
+		return false
+	}
+	val, diags := cond.Expr.Value(nil)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() || val.Type() != cty.Bool {
+		return false
+	}
+	return val.False()
+}
+
 func checkRepoIsClean(g *git.Git) (RepoChecks, error) {
 	untracked, err := g.ListUntracked()
 	if err != nil {
@@ -223,6 +223,8 @@ func Create(root *config.Root, path project.Path, reason string) error {
 
 	ctime := time.Now().Unix()
 
+	// Trigger files are always new files inside the triggers dir, so there is
+	// no existing configuration to edit with the hcl/edit package.
 	gen := hclwrite.NewEmptyFile()
 	triggerBody := gen.Body().AppendNewBlock("trigger", nil).Body()
 	triggerBody.SetAttributeValue("ctime", cty.NumberIntVal(ctime))
@@ -0,0 +1,161 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"math/big"
+	"net/netip"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// CIDRSubnetsNonOverlappingFunc returns the `tm_cidrsubnets_nonoverlapping()`
+// function. It allocates one subnet of the prefix for each newbits argument,
+// like Terraform's cidrsubnets(), but the subnets never overlap the used
+// CIDRs. Each subnet is the first free block of its size, so subnets can be
+// allocated without renumbering the existing ones.
+func CIDRSubnetsNonOverlappingFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "prefix",
+				Type: cty.String,
+			},
+			{
+				Name: "used",
+				Type: cty.List(cty.String),
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "newbits",
+			Type: cty.Number,
+		},
+		Type: function.StaticReturnType(cty.List(cty.String)),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			base, err := netip.ParsePrefix(args[0].AsString())
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			base = base.Masked()
+
+			var used []netip.Prefix
+			for _, v := range args[1].AsValueSlice() {
+				if v.IsNull() {
+					return cty.NilVal, function.NewArgErrorf(1, "used CIDRs can't be null")
+				}
+				p, err := netip.ParsePrefix(v.AsString())
+				if err != nil {
+					return cty.NilVal, function.NewArgError(1, err)
+				}
+				if p.Addr().Is4() != base.Addr().Is4() {
+					return cty.NilVal, function.NewArgErrorf(1,
+						"used CIDR %s and prefix %s have different address families", p, base)
+				}
+				used = append(used, p.Masked())
+			}
+
+			var newbits []int
+			for i, v := range args[2:] {
+				n, acc := v.AsBigFloat().Int64()
+				if acc != big.Exact || n < 0 || int(n)+base.Bits() > base.Addr().BitLen() {
+					return cty.NilVal, function.NewArgErrorf(i+2,
+						"newbits must be an integer between 0 and %d but got %s",
+						base.Addr().BitLen()-base.Bits(), v.AsBigFloat().String())
+				}
+				newbits = append(newbits, int(n))
+			}
+
+			subnets, err := cidrSubnetsNonOverlapping(base, used, newbits)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			if len(subnets) == 0 {
+				return cty.ListValEmpty(cty.String), nil
+			}
+			vals := make([]cty.Value, len(subnets))
+			for i, subnet := range subnets {
+				vals[i] = cty.StringVal(subnet.String())
+			}
+			return cty.ListVal(vals), nil
+		},
+	})
+}
+
+// ipRange is a range of addresses, from first to last inclusive.
+type ipRange struct {
+	first, last *big.Int
+}
+
+func cidrSubnetsNonOverlapping(base netip.Prefix, used []netip.Prefix, newbits []int) ([]netip.Prefix, error) {
+	bitlen := base.Addr().BitLen()
+	baseRange := prefixRange(base)
+
+	taken := make([]ipRange, 0, len(used)+len(newbits))
+	for _, p := range used {
+		taken = append(taken, prefixRange(p))
+	}
+
+	var subnets []netip.Prefix
+	for _, n := range newbits {
+		bits := base.Bits() + n
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bitlen-bits))
+		first := new(big.Int).Set(baseRange.first)
+		for {
+			last := new(big.Int).Add(first, size)
+			last.Sub(last, big.NewInt(1))
+			if last.Cmp(baseRange.last) > 0 {
+				return nil, errors.E("no free /%d subnet left in %s", bits, base)
+			}
+
+			overlap, ok := findOverlap(taken, first, last)
+			if !ok {
+				subnet := netip.PrefixFrom(intToAddr(first, base.Addr().Is4()), bits)
+				subnets = append(subnets, subnet)
+				taken = append(taken, ipRange{first: first, last: last})
+				break
+			}
+
+			// the next candidate is the first block aligned to the subnet
+			// size after the overlapping range.
+			first = new(big.Int).Add(overlap.last, big.NewInt(1))
+			if rem := new(big.Int).Mod(first, size); rem.Sign() != 0 {
+				first.Add(first, new(big.Int).Sub(size, rem))
+			}
+		}
+	}
+	return subnets, nil
+}
+
+func findOverlap(ranges []ipRange, first, last *big.Int) (ipRange, bool) {
+	for _, r := range ranges {
+		if r.first.Cmp(last) <= 0 && first.Cmp(r.last) <= 0 {
+			return r, true
+		}
+	}
+	return ipRange{}, false
+}
+
+func prefixRange(p netip.Prefix) ipRange {
+	first := addrToInt(p.Addr())
+	size := new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+	last := new(big.Int).Add(first, size)
+	return ipRange{first: first, last: last.Sub(last, big.NewInt(1))}
+}
+
+func addrToInt(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+func intToAddr(i *big.Int, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		i.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	i.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import "testing"
+
+func TestStdlibTmCIDRSubnetsNonOverlapping(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_cidrsubnets_nonoverlapping("10.0.0.0/16", [])`,
+			want: `tm_slice(tm_tolist(["a"]), 0, 0)`,
+		},
+		{
+			expr: `tm_cidrsubnets_nonoverlapping("10.0.0.0/16", [], 8, 8, 4)`,
+			want: `tm_tolist(["10.0.0.0/24", "10.0.1.0/24", "10.0.16.0/20"])`,
+		},
+		{
+			expr: `tm_cidrsubnets_nonoverlapping("10.0.0.0/16", ["10.0.0.0/24", "10.0.2.0/23"], 8, 8, 7)`,
+			want: `tm_tolist(["10.0.1.0/24", "10.0.4.0/24", "10.0.6.0/23"])`,
+		},
+		{
+			expr: `tm_cidrsubnets_nonoverlapping("10.0.0.0/16", ["10.0.0.128/25", "192.168.0.0/16"], 9, 8)`,
+			want: `tm_tolist(["10.0.0.0/25", "10.0.1.0/24"])`,
+		},
+		{
+			expr: `tm_cidrsubnets_nonoverlapping("10.0.5.3/16", [], 0)`,
+			want: `tm_tolist(["10.0.0.0/16"])`,
+		},
+		{
+			expr: `tm_cidrsubnets_nonoverlapping("fd00::/48", ["fd00::/64"], 16, 16)`,
+			want: `tm_tolist(["fd00:0:0:1::/64", "fd00:0:0:2::/64"])`,
+		},
+		{
+			expr:    `tm_cidrsubnets_nonoverlapping("10.0.0.0/24", ["10.0.0.0/25"], 1, 1)`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_cidrsubnets_nonoverlapping("10.0.0.0/24", [], 9)`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_cidrsubnets_nonoverlapping("10.0.0.0/24", [], 1.5)`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_cidrsubnets_nonoverlapping("10.0.0.0/24", ["fd00::/64"], 1)`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_cidrsubnets_nonoverlapping("invalid", [], 1)`,
+			wantErr: true,
+		},
+	})
+}
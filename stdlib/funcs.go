@@ -23,6 +23,7 @@ import (
 	"github.com/terramate-io/terramate/versions"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/exp/slices"
 )
 
 var regexCache map[string]*regexp.Regexp
@@ -49,10 +50,6 @@ func Functions(basedir string) map[string]function.Function {
 	scope := &tflang.Scope{BaseDir: basedir}
 	tffuncs := scope.Functions()
 
-	// not supported functions
-	delete(tffuncs, "sensitive")
-	delete(tffuncs, "nonsensitive")
-
 	tmfuncs := map[string]function.Function{}
 	for name, function := range tffuncs {
 		tmfuncs["tm_"+name] = function
@@ -71,27 +68,63 @@ func Functions(basedir string) map[string]function.Function {
 	tmfuncs["tm_try"] = TryFunc()
 
 	tmfuncs["tm_version_match"] = VersionMatch()
+	tmfuncs["tm_semver_compare"] = SemverCompareFunc()
+
+	// multi-document support
+	tmfuncs["tm_yamldecode"] = YAMLDecodeFunc()
+
+	tmfuncs["tm_hcl_decode"] = HCLDecodeFunc()
+	tmfuncs["tm_hcl_encode"] = HCLEncodeFunc()
+	tmfuncs["tm_cidrsubnets_nonoverlapping"] = CIDRSubnetsNonOverlappingFunc()
+	tmfuncs["tm_slugify"] = SlugifyFunc()
+	tmfuncs["tm_deep_merge"] = DeepMergeFunc()
+
+	// provider pinning from a provider catalog
+	tmfuncs["tm_terraform_required_providers"] = TerraformRequiredProvidersFunc(basedir, nil)
+	tmfuncs["tm_terraform_lock_providers"] = TerraformLockProvidersFunc(basedir, nil)
 	return tmfuncs
 }
 
+// fsFuncNames are the functions which access the file system, relative to
+// the base directory of the functions.
+var fsFuncNames = []string{
+	"tm_abspath",
+	"tm_file",
+	"tm_fileexists",
+	"tm_fileset",
+	"tm_filebase64",
+	"tm_filebase64sha256",
+	"tm_filebase64sha512",
+	"tm_filemd5",
+	"tm_filesha1",
+	"tm_filesha256",
+	"tm_filesha512",
+	"tm_templatefile",
+	"tm_terraform_required_providers",
+	"tm_terraform_lock_providers",
+}
+
+// nondeterministicFuncNames are the functions which return different results
+// for the same arguments.
+var nondeterministicFuncNames = []string{
+	"tm_bcrypt",
+	"tm_plantimestamp",
+	"tm_timestamp",
+	"tm_uuid",
+}
+
+// IsPure tells if the function with the given name always returns the same
+// result for the same arguments, independently of the base directory of
+// the functions. Functions not defined by this package are considered pure.
+func IsPure(name string) bool {
+	return !slices.Contains(fsFuncNames, name) &&
+		!slices.Contains(nondeterministicFuncNames, name)
+}
+
 // NoFS returns all Terramate functions but excluding fs-related
 // functions.
 func NoFS(basedir string) map[string]function.Function {
 	funcs := Functions(basedir)
-	fsFuncNames := []string{
-		"tm_abspath",
-		"tm_file",
-		"tm_fileexists",
-		"tm_fileset",
-		"tm_filebase64",
-		"tm_filebase64sha256",
-		"tm_filebase64sha512",
-		"tm_filemd5",
-		"tm_filesha1",
-		"tm_filesha256",
-		"tm_filesha512",
-		"tm_templatefile",
-	}
 	for _, name := range fsFuncNames {
 		delete(funcs, name)
 	}
@@ -0,0 +1,196 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build go1.18 && linux
+
+package stdlib_test
+
+import (
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var slugRegex = regexp.MustCompile(`^([a-z0-9]+(-[a-z0-9]+)*)?$`)
+
+func FuzzSlugify(f *testing.F) {
+	seedCorpus := []string{
+		"",
+		"my-stack",
+		"  My Stack_Name!! ",
+		"São Paulo / Zürich",
+		"日本",
+		"a--b",
+	}
+
+	for _, seed := range seedCorpus {
+		f.Add(seed)
+	}
+
+	fn := stdlib.SlugifyFunc()
+	f.Fuzz(func(t *testing.T, str string) {
+		got, err := fn.Call([]cty.Value{cty.StringVal(str)})
+		if err != nil {
+			// cty rejects invalid UTF-8 strings.
+			return
+		}
+		slug := got.AsString()
+		if !slugRegex.MatchString(slug) {
+			t.Fatalf("tm_slugify(%q) = %q is not a valid slug", str, slug)
+		}
+		again, err := fn.Call([]cty.Value{got})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.AsString() != slug {
+			t.Fatalf("tm_slugify is not idempotent: %q != %q", again.AsString(), slug)
+		}
+	})
+}
+
+func FuzzSemverCompare(f *testing.F) {
+	seedCorpus := []struct {
+		a, b string
+	}{
+		{"1.0.0", "1.0.0"},
+		{"v1.2", "1.10.0"},
+		{"1.0.0-rc.1", "1.0.0"},
+		{"1.0.0+build", "1.0.0"},
+		{"invalid", "1.0.0"},
+	}
+
+	for _, seed := range seedCorpus {
+		f.Add(seed.a, seed.b)
+	}
+
+	fn := stdlib.SemverCompareFunc()
+	f.Fuzz(func(t *testing.T, a, b string) {
+		ab, err := fn.Call([]cty.Value{cty.StringVal(a), cty.StringVal(b)})
+		if err != nil {
+			return
+		}
+		ba, err := fn.Call([]cty.Value{cty.StringVal(b), cty.StringVal(a)})
+		if err != nil {
+			t.Fatalf("tm_semver_compare(%q, %q) failed but the reverse succeeded: %v", b, a, err)
+		}
+		if !ab.Equals(ba.Negate()).True() {
+			t.Fatalf("tm_semver_compare is not antisymmetric for %q and %q", a, b)
+		}
+	})
+}
+
+func FuzzHCLDecode(f *testing.F) {
+	seedCorpus := []string{
+		"",
+		"a = 1",
+		"a = \"str\"\nb = [true, null]",
+		"a = { b = { c = 1.5 } }",
+		"a = \"${1}\"",
+		"block {}",
+	}
+
+	for _, seed := range seedCorpus {
+		f.Add(seed)
+	}
+
+	decode := stdlib.HCLDecodeFunc()
+	encode := stdlib.HCLEncodeFunc()
+	f.Fuzz(func(t *testing.T, str string) {
+		// see FuzzTokensForExpression on why big numbers are skipped.
+		const bigNumRegex = "[\\d]+[\\s]*[.]?[\\s]*[\\d]*[EepP]{1}[\\s]*[+-]?[\\s]*[\\d]+"
+		hasBigNumbers, _ := regexp.MatchString(bigNumRegex, str)
+		if hasBigNumbers {
+			return
+		}
+
+		val, err := decode.Call([]cty.Value{cty.StringVal(str)})
+		if err != nil {
+			return
+		}
+		encoded, err := encode.Call([]cty.Value{val})
+		if err != nil {
+			t.Fatalf("tm_hcl_encode failed for decoded %q: %v", str, err)
+		}
+		got, err := decode.Call([]cty.Value{encoded})
+		if err != nil {
+			t.Fatalf("tm_hcl_decode failed for encoded %q: %v", encoded.AsString(), err)
+		}
+		if !got.RawEquals(val) {
+			t.Fatalf("round trip of %q: got %s but want %s", str, got.GoString(), val.GoString())
+		}
+	})
+}
+
+func FuzzYAMLDecode(f *testing.F) {
+	seedCorpus := []string{
+		"",
+		"a: 1",
+		"- a\n- b",
+		"a: 1\n---\nb: 2",
+		"---\n---\n",
+		"a: &x 1\nb: *x",
+	}
+
+	for _, seed := range seedCorpus {
+		f.Add(seed)
+	}
+
+	fn := stdlib.YAMLDecodeFunc()
+	f.Fuzz(func(t *testing.T, str string) {
+		if strings.Count(str, "*") > 10 {
+			// avoid alias expansion bombs.
+			return
+		}
+		_, _ = fn.Call([]cty.Value{cty.StringVal(str)})
+	})
+}
+
+func FuzzCIDRSubnetsNonOverlapping(f *testing.F) {
+	seedCorpus := []struct {
+		prefix string
+		used   string
+		bits1  int
+		bits2  int
+	}{
+		{"10.0.0.0/16", "10.0.0.0/24", 8, 4},
+		{"10.0.0.0/24", "10.0.0.128/25", 1, 2},
+		{"fd00::/48", "fd00::/64", 16, 16},
+		{"10.0.0.0/8", "192.168.0.0/16", 0, 1},
+	}
+
+	for _, seed := range seedCorpus {
+		f.Add(seed.prefix, seed.used, seed.bits1, seed.bits2)
+	}
+
+	fn := stdlib.CIDRSubnetsNonOverlappingFunc()
+	f.Fuzz(func(t *testing.T, prefix, used string, bits1, bits2 int) {
+		got, err := fn.Call([]cty.Value{
+			cty.StringVal(prefix),
+			cty.ListVal([]cty.Value{cty.StringVal(used)}),
+			cty.NumberIntVal(int64(bits1)),
+			cty.NumberIntVal(int64(bits2)),
+		})
+		if err != nil {
+			return
+		}
+
+		base := netip.MustParsePrefix(prefix).Masked()
+		allocated := []netip.Prefix{netip.MustParsePrefix(used).Masked()}
+		for _, subnet := range got.AsValueSlice() {
+			p := netip.MustParsePrefix(subnet.AsString())
+			if !base.Contains(p.Addr()) || p.Bits() < base.Bits() {
+				t.Fatalf("subnet %s is not inside %s", p, base)
+			}
+			for _, other := range allocated {
+				if p.Overlaps(other) {
+					t.Fatalf("subnet %s overlaps %s", p, other)
+				}
+			}
+			allocated = append(allocated, p)
+		}
+	})
+}
@@ -297,6 +297,60 @@ func TestStdlibTmVersionMatch(t *testing.T) {
 	}
 }
 
+func TestStdlibSensitive(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		expr          string
+		want          string
+		wantSensitive bool
+	}{
+		{
+			expr:          `tm_sensitive("secret")`,
+			want:          `"secret"`,
+			wantSensitive: true,
+		},
+		{
+			expr:          `tm_upper(tm_sensitive("secret"))`,
+			want:          `"SECRET"`,
+			wantSensitive: true,
+		},
+		{
+			expr:          `"prefix-${tm_sensitive("secret")}"`,
+			want:          `"prefix-secret"`,
+			wantSensitive: true,
+		},
+		{
+			expr:          `tm_deep_merge({ a = 1 }, tm_sensitive({ b = 2 }))`,
+			want:          `{ a = 1, b = 2 }`,
+			wantSensitive: true,
+		},
+		{
+			expr: `tm_nonsensitive(tm_sensitive("secret"))`,
+			want: `"secret"`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := eval.NewContext(stdlib.Functions(test.TempDir(t)))
+			got, err := ctx.Eval(test.NewExpr(t, tc.expr))
+			assert.NoError(t, err)
+			if sensitive := eval.IsSensitive(got); sensitive != tc.wantSensitive {
+				t.Fatalf("got sensitive=%t but want %t", sensitive, tc.wantSensitive)
+			}
+
+			want, err := ctx.Eval(test.NewExpr(t, tc.want))
+			assert.NoError(t, err)
+			got, _ = got.UnmarkDeep()
+			if !got.Equals(want).True() {
+				t.Fatalf("got %s but want %s", got.GoString(), want.GoString())
+			}
+		})
+	}
+}
+
 func TestStdlibNewFunctionsMustPanicIfRelativeBaseDir(t *testing.T) {
 	defer func() {
 		err := recover()
@@ -330,6 +384,41 @@ func TestStdlibNewFunctionsFailIfBasedirIsNotADirectory(t *testing.T) {
 	_ = stdlib.Functions(path)
 }
 
+// evalTestcase is a test case of an expression which evaluates to the value
+// of the want expression or fails.
+type evalTestcase struct {
+	expr    string
+	want    string
+	wantErr bool
+}
+
+func testEvalExprs(t *testing.T, tcases []evalTestcase) {
+	t.Helper()
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := eval.NewContext(stdlib.Functions(test.TempDir(t)))
+			got, err := ctx.Eval(test.NewExpr(t, tc.expr))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error but got %s", got.GoString())
+				}
+				return
+			}
+			assert.NoError(t, err)
+
+			want, err := ctx.Eval(test.NewExpr(t, tc.want))
+			assert.NoError(t, err)
+			if !got.Equals(want).True() {
+				t.Fatalf("got %s but want %s", got.GoString(), want.GoString())
+			}
+		})
+	}
+}
+
 func init() {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 }
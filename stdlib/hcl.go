@@ -0,0 +1,100 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// HCLDecodeFunc returns the `tm_hcl_decode()` function, which decodes the
+// attributes of an HCL document into an object. Only literal values are
+// supported, so the document can't have blocks, variables or function calls.
+func HCLDecodeFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "src",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return hclDecode(args[0].AsString())
+		},
+	})
+}
+
+// HCLEncodeFunc returns the `tm_hcl_encode()` function, which encodes an
+// object as an HCL document with one attribute per object key, sorted by name.
+func HCLEncodeFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "obj",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return hclEncode(args[0])
+		},
+	})
+}
+
+func hclDecode(src string) (cty.Value, error) {
+	file, diags := hclsyntax.ParseConfig([]byte(src), "<tm_hcl_decode>", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, errors.E(diags, "tm_hcl_decode: invalid HCL")
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return cty.NilVal, errors.E(diags, "tm_hcl_decode: only attributes are supported")
+	}
+	res := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return cty.NilVal, errors.E(diags,
+				"tm_hcl_decode: attribute %q must be a literal value", name)
+		}
+		res[name] = val
+	}
+	return cty.ObjectVal(res), nil
+}
+
+func hclEncode(obj cty.Value) (cty.Value, error) {
+	if !IsObject(obj) {
+		return cty.NilVal, function.NewArgErrorf(0,
+			"tm_hcl_encode: argument must be an object or map but got %s",
+			obj.Type().FriendlyName())
+	}
+	if !obj.IsWhollyKnown() {
+		return cty.UnknownVal(cty.String), nil
+	}
+
+	attrs := obj.AsValueMap()
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		if !hclsyntax.ValidIdentifier(name) {
+			return cty.NilVal, function.NewArgErrorf(0,
+				"tm_hcl_encode: key %q is not a valid attribute name", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+	for _, name := range names {
+		body.SetAttributeValue(name, attrs[name])
+	}
+	return cty.StringVal(string(hclwrite.Format(file.Bytes()))), nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import "testing"
+
+func TestStdlibTmHCLDecode(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_hcl_decode("")`,
+			want: `{}`,
+		},
+		{
+			expr: `tm_hcl_decode("a = 1\nb = \"str\"\nc = [true, null]\nd = { e = 1.5 }")`,
+			want: `{ a = 1, b = "str", c = [true, null], d = { e = 1.5 } }`,
+		},
+		{
+			expr: `tm_hcl_decode("a = 1 + 1\nb = \"x-${1}\"")`,
+			want: `{ a = 2, b = "x-1" }`,
+		},
+		{
+			expr:    `tm_hcl_decode("a = ")`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_hcl_decode("block {}")`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_hcl_decode("a = var.b")`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_hcl_decode("a = upper(\"b\")")`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestStdlibTmHCLEncode(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_hcl_encode({})`,
+			want: `""`,
+		},
+		{
+			expr: `tm_hcl_encode({ b = "str", a = 1 })`,
+			want: `"a = 1\nb = \"str\"\n"`,
+		},
+		{
+			expr: `tm_hcl_encode({ list = [1, 2], obj = { "a-b" = true } })`,
+			want: `"list = [1, 2]\nobj = {\n  a-b = true\n}\n"`,
+		},
+		{
+			expr: `tm_hcl_decode(tm_hcl_encode({ a = [1, "b"], c = { d = null } }))`,
+			want: `{ a = [1, "b"], c = { d = null } }`,
+		},
+		{
+			expr:    `tm_hcl_encode({ "a b" = 1 })`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_hcl_encode([1])`,
+			wantErr: true,
+		},
+	})
+}
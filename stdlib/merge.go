@@ -0,0 +1,67 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"github.com/terramate-io/terramate/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// DeepMergeFunc returns the `tm_deep_merge()` function, which merges objects
+// recursively. The keys of the later objects have precedence, except when both
+// values are objects, in which case they are merged. Null arguments are ignored.
+func DeepMergeFunc() function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name:             "objects",
+			Type:             cty.DynamicPseudoType,
+			AllowNull:        true,
+			AllowDynamicType: true,
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			res := cty.EmptyObjectVal
+			for i, arg := range args {
+				if arg.IsNull() {
+					continue
+				}
+				if !IsObject(arg) {
+					return cty.NilVal, function.NewArgError(i,
+						errors.E("tm_deep_merge: argument must be an object or map but got %s",
+							arg.Type().FriendlyName()))
+				}
+				res = DeepMerge(res, arg)
+			}
+			return res, nil
+		},
+	})
+}
+
+// DeepMerge merges the val object into the base object recursively. The
+// values of val have precedence, except when both are objects, which are
+// merged. Both values must be known objects or maps.
+// The marks of the merged objects, if any, are kept in the result.
+func DeepMerge(base, val cty.Value) cty.Value {
+	base, baseMarks := base.Unmark()
+	val, valMarks := val.Unmark()
+	res := map[string]cty.Value{}
+	for k, v := range base.AsValueMap() {
+		res[k] = v
+	}
+	for k, v := range val.AsValueMap() {
+		if old, ok := res[k]; ok && IsObject(old) && IsObject(v) {
+			res[k] = DeepMerge(old, v)
+			continue
+		}
+		res[k] = v
+	}
+	return cty.ObjectVal(res).WithMarks(baseMarks, valMarks)
+}
+
+// IsObject tells if the value is a known and non-null object or map.
+func IsObject(val cty.Value) bool {
+	return !val.IsNull() && val.IsKnown() &&
+		(val.Type().IsObjectType() || val.Type().IsMapType())
+}
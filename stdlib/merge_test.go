@@ -0,0 +1,41 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import "testing"
+
+func TestStdlibTmDeepMerge(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_deep_merge()`,
+			want: `{}`,
+		},
+		{
+			expr: `tm_deep_merge({ a = 1 }, null)`,
+			want: `{ a = 1 }`,
+		},
+		{
+			expr: `tm_deep_merge({ a = 1, b = { c = 1, d = 1 } }, { b = { d = 2, e = 2 } })`,
+			want: `{ a = 1, b = { c = 1, d = 2, e = 2 } }`,
+		},
+		{
+			expr: `tm_deep_merge({ a = { b = 1 } }, { a = "x" }, { c = [1] })`,
+			want: `{ a = "x", c = [1] }`,
+		},
+		{
+			expr: `tm_deep_merge({ a = [1] }, { a = [2] })`,
+			want: `{ a = [2] }`,
+		},
+		{
+			expr: `tm_deep_merge(tm_tomap({ a = "1" }), { b = { c = 1 } })`,
+			want: `{ a = "1", b = { c = 1 } }`,
+		},
+		{
+			expr:    `tm_deep_merge({ a = 1 }, [1])`,
+			wantErr: true,
+		},
+	})
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"github.com/terramate-io/terramate/versions"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// SemverCompareFunc returns the `tm_semver_compare()` function, which compares
+// two semantic versions and returns -1, 0 or 1 if the first version is lower,
+// equal or greater than the second one, respectively.
+func SemverCompareFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "a",
+				Type: cty.String,
+			},
+			{
+				Name: "b",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			res, err := versions.Compare(args[0].AsString(), args[1].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.NumberIntVal(int64(res)), nil
+		},
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import "testing"
+
+func TestStdlibTmSemverCompare(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_semver_compare("1.0.0", "1.0.0")`,
+			want: `0`,
+		},
+		{
+			expr: `tm_semver_compare("v1.0", "1.0.0")`,
+			want: `0`,
+		},
+		{
+			expr: `tm_semver_compare("1.2.0", "1.10.0")`,
+			want: `-1`,
+		},
+		{
+			expr: `tm_semver_compare("2.0.0", "1.10.0")`,
+			want: `1`,
+		},
+		{
+			expr: `tm_semver_compare("1.0.0-rc1", "1.0.0")`,
+			want: `-1`,
+		},
+		{
+			expr: `tm_semver_compare("1.0.0-rc.2", "1.0.0-rc.10")`,
+			want: `-1`,
+		},
+		{
+			expr:    `tm_semver_compare("1.0.0", "not a version")`,
+			wantErr: true,
+		},
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// slugSeparators matches the sequences of characters which are replaced by
+// a single separator. It's compiled once as tm_slugify is commonly called
+// for every stack.
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SlugifyFunc returns the `tm_slugify()` function, which converts the string
+// into a lowercase string with only ASCII letters, digits and dashes, suitable
+// for resource names. Accents are removed and every other sequence of characters
+// is replaced by a single dash.
+func SlugifyFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "str",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(slugify(args[0].AsString())), nil
+		},
+	})
+}
+
+func slugify(str string) string {
+	// removes the accents by decomposing the characters and removing the
+	// combining marks, eg.: "é" becomes "e".
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if res, _, err := transform.String(t, str); err == nil {
+		str = res
+	}
+	str = slugSeparators.ReplaceAllString(strings.ToLower(str), "-")
+	return strings.Trim(str, "-")
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import "testing"
+
+func TestStdlibTmSlugify(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_slugify("")`,
+			want: `""`,
+		},
+		{
+			expr: `tm_slugify("my-stack")`,
+			want: `"my-stack"`,
+		},
+		{
+			expr: `tm_slugify("  My Stack_Name!! ")`,
+			want: `"my-stack-name"`,
+		},
+		{
+			expr: `tm_slugify("São Paulo / Zürich")`,
+			want: `"sao-paulo-zurich"`,
+		},
+		{
+			expr: `tm_slugify("---")`,
+			want: `""`,
+		},
+		{
+			expr: `tm_slugify("日本")`,
+			want: `""`,
+		},
+		{
+			expr:    `tm_slugify(["a"])`,
+			wantErr: true,
+		},
+	})
+}
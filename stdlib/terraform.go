@@ -0,0 +1,278 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/generate/header"
+	"github.com/terramate-io/terramate/tf"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const (
+	// ErrProviderCatalog indicates an invalid provider catalog.
+	ErrProviderCatalog errors.Kind = "invalid provider catalog"
+
+	// ErrProviderNotInCatalog indicates a provider referenced by Terraform
+	// code that is missing in the provider catalog.
+	ErrProviderNotInCatalog errors.Kind = "provider not found in the provider catalog"
+)
+
+const defaultProviderRegistry = "registry.terraform.io"
+
+// catalogProvider is a provider entry of the provider catalog.
+type catalogProvider struct {
+	name        string
+	source      string
+	version     string
+	constraints string
+	hashes      []string
+}
+
+// TerraformRequiredProvidersFunc returns the `tm_terraform_required_providers`
+// function implementation.
+// The `tm_terraform_required_providers(catalog)` returns an object with the
+// required_providers entries of the providers referenced by the Terraform
+// files in the basedir. Each provider source and version is obtained from the
+// given catalog and it fails if any referenced provider is not in the catalog.
+//
+// The generated argument has the up-to-date content of the files generated by
+// Terramate in the basedir, keyed by the file name. If it's not nil, it's used
+// instead of the content on disk of the generated files, and the generated
+// files not in it are ignored, as they are going to be deleted. An empty
+// content ignores the file too.
+func TerraformRequiredProvidersFunc(basedir string, generated map[string]string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "catalog",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			providers, err := referencedCatalogProviders(basedir, generated, args[0])
+			if err != nil {
+				return cty.NilVal, errors.E(err, "tm_terraform_required_providers")
+			}
+			res := map[string]cty.Value{}
+			for _, p := range providers {
+				res[p.name] = cty.ObjectVal(map[string]cty.Value{
+					"source":  cty.StringVal(p.source),
+					"version": cty.StringVal(p.constraints),
+				})
+			}
+			return cty.ObjectVal(res), nil
+		},
+	})
+}
+
+// TerraformLockProvidersFunc returns the `tm_terraform_lock_providers`
+// function implementation.
+// The `tm_terraform_lock_providers(catalog)` returns an object with the
+// .terraform.lock.hcl provider entries, keyed by the provider address, of the
+// providers referenced by the Terraform files in the basedir. It fails if any
+// referenced provider is not in the catalog. The providers are detected like
+// in [TerraformRequiredProvidersFunc].
+//
+// Terraform rewrites the lock file on `terraform init`, so a generated lock
+// file must be used with `terraform init -lockfile=readonly`, otherwise it's
+// reported as manually edited by the next code generation.
+func TerraformLockProvidersFunc(basedir string, generated map[string]string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "catalog",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			providers, err := referencedCatalogProviders(basedir, generated, args[0])
+			if err != nil {
+				return cty.NilVal, errors.E(err, "tm_terraform_lock_providers")
+			}
+			res := map[string]cty.Value{}
+			for _, p := range providers {
+				hashes := make([]cty.Value, len(p.hashes))
+				for i, hash := range p.hashes {
+					hashes[i] = cty.StringVal(hash)
+				}
+				hashesVal := cty.ListValEmpty(cty.String)
+				if len(hashes) > 0 {
+					hashesVal = cty.ListVal(hashes)
+				}
+				res[providerAddress(p.source)] = cty.ObjectVal(map[string]cty.Value{
+					"version":     cty.StringVal(p.version),
+					"constraints": cty.StringVal(p.constraints),
+					"hashes":      hashesVal,
+				})
+			}
+			return cty.ObjectVal(res), nil
+		},
+	})
+}
+
+// referencedCatalogProviders returns the catalog entries of the providers
+// referenced by the Terraform files inside basedir, sorted by name.
+func referencedCatalogProviders(basedir string, generated map[string]string, catalogVal cty.Value) ([]catalogProvider, error) {
+	catalog, err := parseProviderCatalog(catalogVal)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced, err := referencedProviders(basedir, generated)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	providers := make([]catalogProvider, 0, len(referenced))
+	for _, name := range referenced {
+		p, ok := catalog[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		providers = append(providers, p)
+	}
+	if len(missing) > 0 {
+		return nil, errors.E(ErrProviderNotInCatalog,
+			"providers %s are referenced in %s but not found in the provider catalog",
+			strings.Join(missing, ", "), basedir)
+	}
+	return providers, nil
+}
+
+// referencedProviders returns the providers referenced by the Terraform
+// files of the given dir. Sub directories are not considered. The content of
+// the files generated by Terramate is obtained from generated, if not nil, as
+// described in [TerraformRequiredProvidersFunc].
+func referencedProviders(dir string, generated map[string]string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.E(err, "listing Terraform files")
+	}
+
+	contents := map[string]string{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), ".tf") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.E(err, "reading Terraform file")
+		}
+		if generated != nil && header.IsGenerated(string(data)) {
+			continue
+		}
+		contents[entry.Name()] = string(data)
+	}
+	for name, content := range generated {
+		if strings.HasSuffix(name, ".tf") && !strings.Contains(name, "/") {
+			contents[name] = content
+		}
+	}
+
+	found := map[string]struct{}{}
+	for name, content := range contents {
+		if content == "" {
+			continue
+		}
+		providers, err := tf.ParseProvidersContent(filepath.Join(dir, name), []byte(content))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range providers {
+			found[p] = struct{}{}
+		}
+	}
+
+	res := make([]string, 0, len(found))
+	for name := range found {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func parseProviderCatalog(catalog cty.Value) (map[string]catalogProvider, error) {
+	if !catalog.Type().IsObjectType() && !catalog.Type().IsMapType() {
+		return nil, errors.E(ErrProviderCatalog,
+			"catalog must be an object but has type %s", catalog.Type().FriendlyName())
+	}
+	if catalog.IsNull() || !catalog.IsWhollyKnown() {
+		return nil, errors.E(ErrProviderCatalog, "catalog must be a known non-null object")
+	}
+
+	res := map[string]catalogProvider{}
+	for name, entry := range catalog.AsValueMap() {
+		if !entry.Type().IsObjectType() && !entry.Type().IsMapType() {
+			return nil, errors.E(ErrProviderCatalog,
+				"catalog entry %q must be an object but has type %s",
+				name, entry.Type().FriendlyName())
+		}
+		p := catalogProvider{name: name}
+		attrs := entry.AsValueMap()
+		for attrName, target := range map[string]*string{
+			"source":      &p.source,
+			"version":     &p.version,
+			"constraints": &p.constraints,
+		} {
+			val, ok := attrs[attrName]
+			if !ok {
+				continue
+			}
+			if val.Type() != cty.String {
+				return nil, errors.E(ErrProviderCatalog,
+					"catalog entry %q: %s must be a string but has type %s",
+					name, attrName, val.Type().FriendlyName())
+			}
+			*target = val.AsString()
+		}
+		if p.source == "" || p.version == "" {
+			return nil, errors.E(ErrProviderCatalog,
+				"catalog entry %q must define both source and version", name)
+		}
+		if p.constraints == "" {
+			p.constraints = p.version
+		}
+		if hashes, ok := attrs["hashes"]; ok {
+			if !hashes.Type().IsListType() && !hashes.Type().IsTupleType() && !hashes.Type().IsSetType() {
+				return nil, errors.E(ErrProviderCatalog,
+					"catalog entry %q: hashes must be a list of strings but has type %s",
+					name, hashes.Type().FriendlyName())
+			}
+			for _, hash := range hashes.AsValueSlice() {
+				if hash.Type() != cty.String {
+					return nil, errors.E(ErrProviderCatalog,
+						"catalog entry %q: hashes must be a list of strings", name)
+				}
+				p.hashes = append(p.hashes, hash.AsString())
+			}
+			sort.Strings(p.hashes)
+		}
+		res[name] = p
+	}
+	return res, nil
+}
+
+// providerAddress returns the fully qualified address of the provider source,
+// as used in the .terraform.lock.hcl file.
+func providerAddress(source string) string {
+	switch parts := strings.Split(source, "/"); len(parts) {
+	case 1:
+		return defaultProviderRegistry + "/hashicorp/" + source
+	case 2:
+		return defaultProviderRegistry + "/" + source
+	default:
+		return source
+	}
+}
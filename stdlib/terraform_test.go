@@ -0,0 +1,96 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/generate/genhcl"
+	"github.com/terramate-io/terramate/stdlib"
+	"github.com/terramate-io/terramate/test"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestStdlibTerraformRequiredProviders(t *testing.T) {
+	t.Parallel()
+
+	catalog := cty.ObjectVal(map[string]cty.Value{
+		"aws": cty.ObjectVal(map[string]cty.Value{
+			"source":  cty.StringVal("hashicorp/aws"),
+			"version": cty.StringVal("5.31.0"),
+		}),
+		"google": cty.ObjectVal(map[string]cty.Value{
+			"source":  cty.StringVal("hashicorp/google"),
+			"version": cty.StringVal("5.10.0"),
+		}),
+	})
+
+	providerNames := func(val cty.Value) []string {
+		var names []string
+		for name := range val.AsValueMap() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	type testcase struct {
+		name      string
+		files     map[string]string
+		generated map[string]string
+		want      []string
+		wantErr   error
+	}
+
+	for _, tc := range []testcase{
+		{
+			name: "generated files on disk are considered",
+			files: map[string]string{
+				"main.tf":   `resource "aws_instance" "a" {}`,
+				"google.tf": genhcl.Header(genhcl.SlashComment) + `provider "google" {}`,
+			},
+			want: []string{"aws", "google"},
+		},
+		{
+			name: "generated content replaces the generated files on disk",
+			files: map[string]string{
+				"main.tf":   `resource "aws_instance" "a" {}`,
+				"old.tf":    genhcl.Header(genhcl.HashComment) + `resource "azurerm_resource_group" "a" {}`,
+				"google.tf": genhcl.Header(genhcl.SlashComment) + `resource "azurerm_resource_group" "b" {}`,
+			},
+			generated: map[string]string{
+				"google.tf":    `provider "google" {}`,
+				"providers.tf": "",
+			},
+			want: []string{"aws", "google"},
+		},
+		{
+			name: "provider not in the catalog",
+			files: map[string]string{
+				"main.tf": `resource "azurerm_resource_group" "a" {}`,
+			},
+			wantErr: errors.E(stdlib.ErrProviderNotInCatalog),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := test.TempDir(t)
+			for name, content := range tc.files {
+				test.WriteFile(t, dir, name, content)
+			}
+			fn := stdlib.TerraformRequiredProvidersFunc(dir, tc.generated)
+			got, err := fn.Call([]cty.Value{catalog})
+			assert.IsError(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			test.AssertDiff(t, providerNames(got), tc.want)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib
+
+import (
+	"io"
+	"strings"
+
+	"github.com/terramate-io/terramate/errors"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLDecodeFunc returns the `tm_yamldecode()` function, which works like
+// Terraform's yamldecode() for single document streams. Streams with multiple
+// documents are decoded as a tuple with one element per document.
+func YAMLDecodeFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "src",
+				Type: cty.String,
+			},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if !args[0].IsKnown() {
+				return cty.DynamicPseudoType, nil
+			}
+			docs, err := yamlDocuments(args[0].AsString())
+			if err != nil || len(docs) <= 1 {
+				return ctyyaml.Standard.ImpliedType([]byte(args[0].AsString()))
+			}
+			types := make([]cty.Type, len(docs))
+			for i, doc := range docs {
+				types[i], err = ctyyaml.Standard.ImpliedType(doc)
+				if err != nil {
+					return cty.NilType, function.NewArgError(0,
+						errors.E(err, "decoding YAML document %d", i))
+				}
+			}
+			return cty.Tuple(types), nil
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			docs, err := yamlDocuments(args[0].AsString())
+			if err != nil || len(docs) <= 1 {
+				return ctyyaml.Standard.Unmarshal([]byte(args[0].AsString()), retType)
+			}
+			vals := make([]cty.Value, len(docs))
+			for i, doc := range docs {
+				vals[i], err = ctyyaml.Standard.Unmarshal(doc, retType.TupleElementType(i))
+				if err != nil {
+					return cty.NilVal, errors.E(err, "decoding YAML document %d", i)
+				}
+			}
+			return cty.TupleVal(vals), nil
+		},
+	})
+}
+
+// yamlDocuments splits the YAML stream into its documents. Single document
+// streams must be decoded from the original source, which is what Terraform
+// does.
+func yamlDocuments(src string) ([][]byte, error) {
+	var docs [][]byte
+	dec := yaml.NewDecoder(strings.NewReader(src))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, errors.E(err, "invalid YAML")
+		}
+		doc, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, errors.E(err, "invalid YAML")
+		}
+		docs = append(docs, doc)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package stdlib_test
+
+import "testing"
+
+func TestStdlibTmYAMLDecode(t *testing.T) {
+	t.Parallel()
+
+	testEvalExprs(t, []evalTestcase{
+		{
+			expr: `tm_yamldecode("a: 1")`,
+			want: `{ a = 1 }`,
+		},
+		{
+			expr: `tm_yamldecode("---\na: 1\n")`,
+			want: `{ a = 1 }`,
+		},
+		{
+			expr: `tm_yamldecode("- a\n- b")`,
+			want: `["a", "b"]`,
+		},
+		{
+			expr: `tm_yamldecode("a: 1\n---\nb: [1, 2]\n---\nc")`,
+			want: `[{ a = 1 }, { b = [1, 2] }, "c"]`,
+		},
+		{
+			expr: `tm_yamldecode("a: &x 1\nb: *x\n---\nc: 2")`,
+			want: `[{ a = 1, b = 1 }, { c = 2 }]`,
+		},
+		{
+			expr:    `tm_yamldecode("a: [")`,
+			wantErr: true,
+		},
+		{
+			expr:    `tm_yamldecode("a: 1\n---\nb: [")`,
+			wantErr: true,
+		},
+	})
+}
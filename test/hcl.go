@@ -69,6 +69,8 @@ func AssertTerramateConfig(t *testing.T, got, want hcl.Config) {
 	assertGenHCLBlocks(t, got.Generate.HCLs, want.Generate.HCLs)
 	assertGenFileBlocks(t, got.Generate.Files, want.Generate.Files)
 	assertScriptBlocks(t, got.Scripts, want.Scripts)
+	assertFunctionBlocks(t, got.Functions, want.Functions)
+	AssertDiff(t, got.Profiles.Names(), want.Profiles.Names(), "terramate profiles")
 }
 
 // AssertDiff will compare the two values and fail if they are not the same
@@ -181,6 +183,23 @@ func assertAssertsBlock(t *testing.T, got, want []hcl.AssertConfig, ctx string)
 	}
 }
 
+func assertFunctionBlocks(t *testing.T, got, want []hcl.Function) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d function blocks, want %d", len(got), len(want))
+	}
+
+	for i, g := range got {
+		w := want[i]
+		AssertDiff(t, g.Name, w.Name, "function %d: name mismatch", i)
+		AssertDiff(t, g.Params, w.Params, "function %s: params mismatch", w.Name)
+		assert.EqualStrings(t,
+			exprAsStr(t, w.Result), exprAsStr(t, g.Result),
+			"function %s: result expr mismatch", w.Name)
+	}
+}
+
 func exprAsStr(t *testing.T, expr hhcl.Expression) string {
 	t.Helper()
 
@@ -244,6 +263,10 @@ func assertTerramateConfigBlock(t *testing.T, got, want *hcl.RootConfig) {
 		t.Fatalf("want.Experiments[%+v] != got.Experiments[%+v]", want.Experiments, got.Experiments)
 	}
 
+	if want.Strict != got.Strict {
+		t.Fatalf("want.Strict[%t] != got.Strict[%t]", want.Strict, got.Strict)
+	}
+
 	assertTerramateRunBlock(t, got.Run, want.Run)
 	assertTerramateCloudBlock(t, got.Cloud, want.Cloud)
 }
@@ -0,0 +1,148 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package tf
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/terramate-io/terramate/errors"
+)
+
+const (
+	// RegistryScheme is the path scheme of Terraform registry module sources.
+	RegistryScheme = "registry"
+
+	// DefaultRegistryHost is the registry host of module sources that have no
+	// hostname.
+	DefaultRegistryHost = "registry.terraform.io"
+
+	// ErrRegistry indicates that a registry module source could not be resolved.
+	ErrRegistry errors.Kind = "resolving registry module source"
+)
+
+var registrySourceRegex = regexp.MustCompile(
+	`^(?:([a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)+(?::[0-9]+)?)/)?` +
+		`([a-zA-Z0-9][a-zA-Z0-9_-]*)/([a-zA-Z0-9][a-zA-Z0-9_-]*)/([a-zA-Z0-9]+)$`)
+
+// IsRegistrySource tells if modsource is a Terraform registry module source.
+func IsRegistrySource(modsource string) bool {
+	addr, _, _ := strings.Cut(modsource, "?")
+	addr, _ = parseSubdir(addr)
+	return registrySourceRegex.MatchString(addr)
+}
+
+// ParseRegistrySource parses the given Terraform registry module source, in the
+// [<HOSTNAME>/]<NAMESPACE>/<NAME>/<PROVIDER>[//<SUBDIR>] format as documented in:
+//
+// - https://developer.hashicorp.com/terraform/language/modules/sources#terraform-registry
+//
+// The version of the module is given by the ?ref= query parameter, if any.
+// The URL of the returned source is the URL of the registry, and the source must
+// be resolved with [ResolveRegistrySource] to get the Git source of its package.
+func ParseRegistrySource(modsource string) (Source, error) {
+	addr, query, _ := strings.Cut(modsource, "?")
+	addr, subdir := parseSubdir(addr)
+	matches := registrySourceRegex.FindStringSubmatch(addr)
+	if matches == nil {
+		return Source{}, errors.E(ErrUnsupportedModSrc,
+			"%s is not a registry module source", modsource)
+	}
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return Source{}, errors.E(ErrInvalidModSrc, err,
+			"invalid query in %s", modsource)
+	}
+	host := matches[1]
+	if host == "" {
+		host = DefaultRegistryHost
+	}
+	return Source{
+		Raw:        modsource,
+		URL:        "https://" + host,
+		Path:       path.Join(strings.Replace(host, ":", "-", -1), matches[2], matches[3], matches[4]),
+		PathScheme: RegistryScheme,
+		Subdir:     subdir,
+		Ref:        q.Get("ref"),
+	}, nil
+}
+
+// ResolveRegistrySource resolves the registry module source modsrc, returned by
+// [ParseRegistrySource] and pinned to a version, to the Git source of the module
+// package, using the registry module protocol:
+//
+// - https://developer.hashicorp.com/terraform/internals/module-registry-protocol
+//
+// The subdir of the returned source is the subdir of the module inside the
+// package, if any. Package sources that are not Git sources are not supported.
+func ResolveRegistrySource(client *http.Client, modsrc Source) (Source, error) {
+	if modsrc.PathScheme != RegistryScheme {
+		return Source{}, errors.E(ErrRegistry, "%s is not a registry module source", modsrc.Raw)
+	}
+	if modsrc.Ref == "" {
+		return Source{}, errors.E(ErrRegistry, "%s has no version", modsrc.Raw)
+	}
+
+	baseURL, err := url.Parse(modsrc.URL)
+	if err != nil {
+		return Source{}, errors.E(ErrRegistry, err)
+	}
+
+	discoveryURL := baseURL.JoinPath(".well-known", "terraform.json")
+	resp, err := client.Get(discoveryURL.String())
+	if err != nil {
+		return Source{}, errors.E(ErrRegistry, err, "discovering registry services")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Source{}, errors.E(ErrRegistry, "discovering registry services at %s: %s",
+			discoveryURL, resp.Status)
+	}
+
+	var services struct {
+		ModulesV1 string `json:"modules.v1"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&services); err != nil {
+		return Source{}, errors.E(ErrRegistry, err, "decoding registry services")
+	}
+	if services.ModulesV1 == "" {
+		return Source{}, errors.E(ErrRegistry, "registry %s does not provide modules", modsrc.URL)
+	}
+	modulesURL, err := discoveryURL.Parse(services.ModulesV1)
+	if err != nil {
+		return Source{}, errors.E(ErrRegistry, err, "parsing registry modules URL")
+	}
+
+	// The module path is the source path without the registry host.
+	modpath := strings.SplitN(modsrc.Path, "/", 2)[1]
+	downloadURL := modulesURL.JoinPath(modpath, modsrc.Ref, "download")
+	resp, err = client.Get(downloadURL.String())
+	if err != nil {
+		return Source{}, errors.E(ErrRegistry, err, "getting module download URL")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return Source{}, errors.E(ErrRegistry, "getting module download URL from %s: %s",
+			downloadURL, resp.Status)
+	}
+
+	pkgsrc := resp.Header.Get("X-Terraform-Get")
+	if pkgsrc == "" {
+		return Source{}, errors.E(ErrRegistry, "registry returned no download URL for %s", modsrc.Raw)
+	}
+	resolved, err := ParseSource(pkgsrc)
+	if err != nil {
+		return Source{}, errors.E(ErrRegistry, err,
+			"module package %s of %s is not a Git source", pkgsrc, modsrc.Raw)
+	}
+	if resolved.Ref == "" {
+		return Source{}, errors.E(ErrRegistry,
+			"module package %s of %s is not pinned to a reference", pkgsrc, modsrc.Raw)
+	}
+	return resolved, nil
+}
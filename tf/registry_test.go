@@ -0,0 +1,148 @@
+// Copyright 2023 Terramate GmbH
+// SPDX-License-Identifier: MPL-2.0
+
+package tf_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madlambda/spells/assert"
+	"github.com/terramate-io/terramate/errors"
+	"github.com/terramate-io/terramate/test"
+	"github.com/terramate-io/terramate/tf"
+)
+
+func TestParseRegistrySources(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		parsed tf.Source
+		err    error
+	}
+
+	type testcase struct {
+		name   string
+		source string
+		want   want
+	}
+
+	tcases := []testcase{
+		{
+			name:   "source without hostname",
+			source: "terramate-io/example/aws?ref=1.2.0",
+			want: want{
+				parsed: tf.Source{
+					URL:        "https://registry.terraform.io",
+					Path:       "registry.terraform.io/terramate-io/example/aws",
+					PathScheme: tf.RegistryScheme,
+					Ref:        "1.2.0",
+				},
+			},
+		},
+		{
+			name:   "source with hostname and subdir",
+			source: "app.terraform.io/terramate-io/example/aws//modules/vpc?ref=1.2.0",
+			want: want{
+				parsed: tf.Source{
+					URL:        "https://app.terraform.io",
+					Path:       "app.terraform.io/terramate-io/example/aws",
+					PathScheme: tf.RegistryScheme,
+					Subdir:     "/modules/vpc",
+					Ref:        "1.2.0",
+				},
+			},
+		},
+		{
+			name:   "source with hostname and port",
+			source: "example.com:8443/terramate-io/example/aws",
+			want: want{
+				parsed: tf.Source{
+					URL:        "https://example.com:8443",
+					Path:       "example.com-8443/terramate-io/example/aws",
+					PathScheme: tf.RegistryScheme,
+				},
+			},
+		},
+		{
+			name:   "local path is not a registry source",
+			source: "../terramate-io/example/aws",
+			want: want{
+				err: errors.E(tf.ErrUnsupportedModSrc),
+			},
+		},
+		{
+			name:   "source with missing provider is not a registry source",
+			source: "terramate-io/example",
+			want: want{
+				err: errors.E(tf.ErrUnsupportedModSrc),
+			},
+		},
+	}
+
+	for _, tcase := range tcases {
+		tcase := tcase
+		t.Run(tcase.name, func(t *testing.T) {
+			t.Parallel()
+			assert.IsTrue(t, tf.IsRegistrySource(tcase.source) == (tcase.want.err == nil))
+			got, err := tf.ParseRegistrySource(tcase.source)
+			assert.IsError(t, err, tcase.want.err)
+			if tcase.want.err != nil {
+				return
+			}
+			tcase.want.parsed.Raw = tcase.source
+			test.AssertDiff(t, got, tcase.want.parsed)
+		})
+	}
+}
+
+func TestResolveRegistrySource(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/terraform.json", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"modules.v1": "/api/modules/v1/"}`)
+	})
+	mux.HandleFunc("/api/modules/v1/terramate-io/example/aws/1.2.0/download",
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Terraform-Get",
+				"git::https://github.com/terramate-io/terraform-aws-example.git//modules/main?ref=v1.2.0")
+			w.WriteHeader(http.StatusNoContent)
+		})
+	mux.HandleFunc("/api/modules/v1/terramate-io/example/aws/2.0.0/download",
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Terraform-Get", "https://example.com/module.tar.gz")
+			w.WriteHeader(http.StatusNoContent)
+		})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	modsrc, err := tf.ParseRegistrySource(host + "/terramate-io/example/aws?ref=1.2.0")
+	assert.NoError(t, err)
+
+	got, err := tf.ResolveRegistrySource(srv.Client(), modsrc)
+	assert.NoError(t, err)
+	test.AssertDiff(t, got, tf.Source{
+		Raw:        "git::https://github.com/terramate-io/terraform-aws-example.git//modules/main?ref=v1.2.0",
+		URL:        "https://github.com/terramate-io/terraform-aws-example.git",
+		Path:       "github.com/terramate-io/terraform-aws-example",
+		PathScheme: "https",
+		Subdir:     "/modules/main",
+		Ref:        "v1.2.0",
+	})
+
+	for _, source := range []string{
+		host + "/terramate-io/example/aws",
+		host + "/terramate-io/example/aws?ref=2.0.0",
+		host + "/terramate-io/example/aws?ref=3.0.0",
+	} {
+		modsrc, err := tf.ParseRegistrySource(source)
+		assert.NoError(t, err)
+		_, err = tf.ResolveRegistrySource(srv.Client(), modsrc)
+		assert.IsError(t, err, errors.E(tf.ErrRegistry))
+	}
+}
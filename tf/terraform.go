@@ -5,7 +5,10 @@ package tf
 
 import (
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/rs/zerolog/log"
@@ -91,6 +94,73 @@ func ParseModules(path string) ([]Module, error) {
 	return modules, nil
 }
 
+// ParseProviders parses the file at the given path and returns the local names
+// of the providers referenced by it, sorted and without duplicates.
+// Providers are referenced by provider blocks, by the type of resource and
+// data blocks and by their provider meta-argument. The builtin terraform
+// provider and the required_providers entries are not considered references.
+func ParseProviders(path string) ([]string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.E(err, "reading Terraform file")
+	}
+	return ParseProvidersContent(path, src)
+}
+
+// ParseProvidersContent is like [ParseProviders] but parses the given content
+// of the named file instead of reading it.
+func ParseProvidersContent(filename string, src []byte) ([]string, error) {
+	logger := log.With().
+		Str("action", "ParseProvidersContent()").
+		Str("filename", filename).
+		Logger()
+
+	p := hclparse.NewParser()
+
+	logger.Debug().Msg("Parse HCL file")
+
+	f, diags := p.ParseHCL(src, filename)
+	if diags.HasErrors() {
+		return nil, errors.E(ErrHCLSyntax, diags)
+	}
+
+	body := f.Body.(*hclsyntax.Body)
+
+	found := map[string]struct{}{}
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "provider":
+			if len(block.Labels) == 1 {
+				found[block.Labels[0]] = struct{}{}
+			}
+		case "resource", "data":
+			if len(block.Labels) != 2 {
+				logger.Debug().Msgf("ignoring %s block with %d labels", block.Type, len(block.Labels))
+				continue
+			}
+			if attr, ok := block.Body.Attributes["provider"]; ok {
+				traversal, diags := hcl.AbsTraversalForExpr(attr.Expr)
+				if diags.HasErrors() {
+					return nil, errors.E(ErrHCLSyntax, diags)
+				}
+				found[traversal.RootName()] = struct{}{}
+				continue
+			}
+			name, _, _ := strings.Cut(block.Labels[0], "_")
+			found[name] = struct{}{}
+		}
+	}
+
+	delete(found, "terraform")
+
+	providers := make([]string, 0, len(found))
+	for name := range found {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return providers, nil
+}
+
 // IsStack tells if the file defined by path is a potential stack.
 // Eg.: has a backend block or a provider block.
 func IsStack(path string) (bool, error) {
@@ -405,3 +405,85 @@ var end = start
 func init() {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 }
+
+func TestTerraformParseProviders(t *testing.T) {
+	t.Parallel()
+
+	type testcase struct {
+		name    string
+		body    string
+		want    []string
+		wantErr error
+	}
+
+	for _, tc := range []testcase{
+		{
+			name: "no providers",
+			body: `module "test" { source = "./mod" }`,
+			want: []string{},
+		},
+		{
+			name: "provider blocks",
+			body: `
+				provider "aws" {}
+				provider "aws" { alias = "west" }
+				provider "google" {}
+			`,
+			want: []string{"aws", "google"},
+		},
+		{
+			name: "resource and data types",
+			body: `
+				resource "aws_instance" "a" {}
+				data "null_data_source" "b" {}
+				resource "random" "c" {}
+			`,
+			want: []string{"aws", "null", "random"},
+		},
+		{
+			name: "provider meta-argument has precedence over the type",
+			body: `
+				resource "custom_thing" "a" {
+					provider = google.west
+				}
+			`,
+			want: []string{"google"},
+		},
+		{
+			name: "builtin terraform provider and required_providers are ignored",
+			body: `
+				terraform {
+					required_providers {
+						aws = {
+							source = "hashicorp/aws"
+						}
+					}
+				}
+				data "terraform_remote_state" "a" {}
+			`,
+			want: []string{},
+		},
+		{
+			name:    "invalid provider meta-argument",
+			body:    `resource "aws_instance" "a" { provider = "aws" }`,
+			wantErr: errors.E(tf.ErrHCLSyntax),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			configdir := test.TempDir(t)
+			tfpath := test.WriteFile(t, configdir, "main.tf", tc.body)
+
+			got, err := tf.ParseProviders(tfpath)
+			assert.IsError(t, err, tc.wantErr)
+			if err != nil {
+				return
+			}
+			assert.EqualInts(t, len(tc.want), len(got), "got: %v, want: %v", got, tc.want)
+			for i, want := range tc.want {
+				assert.EqualStrings(t, want, got[i], "provider mismatch")
+			}
+		})
+	}
+}
@@ -60,3 +60,18 @@ func Match(version, constraint string, allowPrereleases bool) (bool, error) {
 	}
 	return spec.Check(semver), nil
 }
+
+// Compare compares the semantic versions a and b and returns -1, 0 or 1 if a
+// is lower, equal or greater than b, respectively. Prereleases are lower than
+// their release versions.
+func Compare(a, b string) (int, error) {
+	va, err := hclversion.NewSemver(a)
+	if err != nil {
+		return 0, errors.E(ErrCheck, err, "invalid version %q", a)
+	}
+	vb, err := hclversion.NewSemver(b)
+	if err != nil {
+		return 0, errors.E(ErrCheck, err, "invalid version %q", b)
+	}
+	return va.Compare(vb), nil
+}